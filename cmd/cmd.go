@@ -3,104 +3,299 @@ package cmd
 import (
 	"context"
 	"flag"
+	"fmt"
 	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
 
 	"gitea.com/gitea/gitea-mcp/operation"
+	"gitea.com/gitea/gitea-mcp/pkg/config"
 	flagPkg "gitea.com/gitea/gitea-mcp/pkg/flag"
 	"gitea.com/gitea/gitea-mcp/pkg/log"
 )
 
-var (
-	host  string
-	port  int
-	token string
-)
+var cfg *config.Config
+
+// parseConfig defines the flags on fs, parses args and layers the
+// configuration: the defaults, the configuration file, the environment and
+// the flags given in args, each overriding the ones before.
+func parseConfig(fs *flag.FlagSet, args []string) (*config.Config, error) {
+	c := config.Default()
+	var (
+		token         string
+		tokenFile     string
+		tokenCommand  string
+		gitCredential bool
+		apiKeys       string
+		clientSecret  string
+	)
 
-func init() {
-	flag.StringVar(
-		&flagPkg.Mode,
+	path := fs.String(
+		"config",
+		config.DefaultPath(),
+		"Configuration file (env: GITEA_MCP_CONFIG)",
+	)
+	fs.StringVar(
+		&c.Transport,
 		"t",
-		"stdio",
+		c.Transport,
 		"Transport type (stdio, sse or http)",
 	)
-	flag.StringVar(
-		&flagPkg.Mode,
+	fs.StringVar(
+		&c.Transport,
 		"transport",
-		"stdio",
+		c.Transport,
 		"Transport type (stdio, sse or http)",
 	)
-	flag.StringVar(
-		&host,
+	fs.StringVar(
+		&c.Host,
 		"host",
-		os.Getenv("GITEA_HOST"),
+		c.Host,
 		"Gitea host",
 	)
-	flag.IntVar(
-		&port,
+	fs.IntVar(
+		&c.Port,
 		"port",
-		8080,
+		c.Port,
 		"see or http port",
 	)
-	flag.StringVar(
+	fs.StringVar(
 		&token,
 		"token",
 		"",
 		"Your personal access token",
 	)
-	flag.BoolVar(
-		&flagPkg.ReadOnly,
-		"read-only",
+	fs.StringVar(
+		&tokenFile,
+		"token-file",
+		"",
+		"File to read the access token from (env: GITEA_ACCESS_TOKEN_FILE)",
+	)
+	fs.StringVar(
+		&tokenCommand,
+		"token-command",
+		"",
+		"Command printing the access token, run again when the token is rejected (env: GITEA_TOKEN_COMMAND)",
+	)
+	fs.BoolVar(
+		&gitCredential,
+		"git-credential",
 		false,
+		"Read the access token from git's credential helpers (env: GITEA_GIT_CREDENTIAL)",
+	)
+	fs.StringVar(
+		&c.Instances,
+		"instances",
+		c.Instances,
+		"Path to a YAML or JSON file declaring additional Gitea instances",
+	)
+	fs.BoolVar(
+		&c.Policy.ReadOnly,
+		"read-only",
+		c.Policy.ReadOnly,
 		"Read-only mode",
 	)
-	flag.BoolVar(
-		&flagPkg.Debug,
+	fs.BoolVar(
+		&c.OAuth.Enabled,
+		"oauth",
+		c.OAuth.Enabled,
+		"Require OAuth2 access tokens issued by Gitea in http mode",
+	)
+	fs.StringVar(
+		&c.OAuth.Issuer,
+		"oauth-issuer",
+		c.OAuth.Issuer,
+		"OAuth2 authorization server URL (defaults to the Gitea host)",
+	)
+	fs.StringVar(
+		&c.OAuth.ClientID,
+		"oauth-client-id",
+		c.OAuth.ClientID,
+		"Client ID of the Gitea OAuth2 application MCP clients authorize with (env: GITEA_OAUTH_CLIENT_ID)",
+	)
+	fs.StringVar(
+		&clientSecret,
+		"oauth-client-secret",
+		"",
+		"Client secret of the OAuth2 application, used to introspect tokens (env: GITEA_OAUTH_CLIENT_SECRET)",
+	)
+	fs.StringVar(
+		&c.OAuth.PublicURL,
+		"public-url",
+		c.OAuth.PublicURL,
+		"Public URL of the MCP endpoint advertised in OAuth2 metadata",
+	)
+	fs.StringVar(
+		&c.TLS.Cert,
+		"tls-cert",
+		c.TLS.Cert,
+		"TLS certificate file for sse or http mode",
+	)
+	fs.StringVar(
+		&c.TLS.Key,
+		"tls-key",
+		c.TLS.Key,
+		"TLS private key file for sse or http mode",
+	)
+	fs.StringVar(
+		&c.TLS.ClientCA,
+		"tls-client-ca",
+		c.TLS.ClientCA,
+		"CA bundle to verify client certificates against",
+	)
+	fs.StringVar(
+		&apiKeys,
+		"api-keys",
+		"",
+		"Comma separated list of API keys accepted in sse or http mode",
+	)
+	fs.StringVar(
+		&c.APIKeys.File,
+		"api-keys-file",
+		c.APIKeys.File,
+		"File with one accepted API key per line",
+	)
+	fs.DurationVar(
+		(*time.Duration)(&c.Timeouts.Request),
+		"request-timeout",
+		time.Duration(c.Timeouts.Request),
+		"Timeout of requests to Gitea (0 for none)",
+	)
+	fs.DurationVar(
+		(*time.Duration)(&c.Timeouts.Shutdown),
+		"shutdown-timeout",
+		time.Duration(c.Timeouts.Shutdown),
+		"How long to wait for in-flight tool calls on SIGINT or SIGTERM",
+	)
+	fs.StringVar(
+		&c.Logging.Dir,
+		"log-dir",
+		c.Logging.Dir,
+		"Log directory (defaults to ~/.gitea-mcp)",
+	)
+	fs.BoolVar(
+		&c.Logging.Debug,
 		"d",
-		false,
+		c.Logging.Debug,
 		"debug mode (If -d flag is provided, debug mode will be enabled by default)",
 	)
-	flag.BoolVar(
-		&flagPkg.Insecure,
+	fs.BoolVar(
+		&c.Insecure,
 		"insecure",
-		false,
+		c.Insecure,
 		"ignore TLS certificate errors",
 	)
+	fs.StringVar(
+		&c.CAFile,
+		"ca-file",
+		c.CAFile,
+		"PEM file with additional CA certificates to verify the Gitea host with (env: GITEA_CA_FILE)",
+	)
 
-	flag.Parse()
-
-	flagPkg.Host = host
-	if flagPkg.Host == "" {
-		flagPkg.Host = "https://gitea.com"
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "%s\nFlags:\n", usage)
+		fs.PrintDefaults()
 	}
-
-	flagPkg.Port = port
-
-	flagPkg.Token = token
-	if flagPkg.Token == "" {
-		flagPkg.Token = os.Getenv("GITEA_ACCESS_TOKEN")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
 	}
 
-	if os.Getenv("MCP_MODE") != "" {
-		flagPkg.Mode = os.Getenv("MCP_MODE")
+	// The flags write to c, which the file and the environment are about to
+	// overwrite, so remember the ones given to set them again afterwards.
+	type setting struct{ name, value string }
+	var given []setting
+	fs.Visit(func(f *flag.Flag) {
+		given = append(given, setting{f.Name, f.Value.String()})
+	})
+	explicit := os.Getenv(config.EnvPath) != "" || slices.ContainsFunc(given, func(s setting) bool {
+		return s.name == "config"
+	})
+	loaded, err := config.Load(*path, explicit)
+	if err != nil {
+		return nil, err
 	}
-
-	if os.Getenv("GITEA_READONLY") == "true" {
-		flagPkg.ReadOnly = true
+	*c = *loaded
+	if err := c.ApplyEnv(); err != nil {
+		return nil, err
+	}
+	for _, s := range given {
+		if err := fs.Set(s.name, s.value); err != nil {
+			return nil, err
+		}
 	}
 
-	if os.Getenv("GITEA_DEBUG") == "true" {
-		flagPkg.Debug = true
+	// Secrets are not used as flag defaults so they do not show up in -h.
+	if token != "" || tokenFile != "" || tokenCommand != "" || gitCredential {
+		c.ClearToken()
+		c.Token = token
+		c.TokenFile = tokenFile
+		c.TokenCommand = tokenCommand
+		c.GitCredential = gitCredential
+	}
+	c.UseSecretFile()
+	if apiKeys != "" {
+		c.APIKeys.Keys = apiKeys
+	}
+	if clientSecret != "" {
+		c.OAuth.ClientSecret = clientSecret
 	}
+	return c, nil
+}
+
+func apply(c *config.Config) {
+	flagPkg.Host = c.Host
+	flagPkg.Port = c.Port
+	flagPkg.Token = c.Token
+	flagPkg.TokenFile = c.TokenFile
+	flagPkg.TokenCommand = c.TokenCommand
+	flagPkg.GitCredential = c.GitCredential
+	flagPkg.Mode = c.Transport
+	flagPkg.InstancesFile = c.Instances
 
-	// Set insecure mode based on environment variable
-	if os.Getenv("GITEA_INSECURE") == "true" {
-		flagPkg.Insecure = true
+	flagPkg.OAuth = c.OAuth.Enabled
+	flagPkg.OAuthIssuer = c.OAuth.Issuer
+	if flagPkg.OAuthIssuer == "" {
+		flagPkg.OAuthIssuer = c.Host
 	}
+	flagPkg.OAuthClientID = c.OAuth.ClientID
+	flagPkg.OAuthClientSecret = c.OAuth.ClientSecret
+	flagPkg.PublicURL = c.OAuth.PublicURL
+
+	flagPkg.TLSCert = c.TLS.Cert
+	flagPkg.TLSKey = c.TLS.Key
+	flagPkg.TLSClientCA = c.TLS.ClientCA
+	flagPkg.APIKeys = c.APIKeys.Keys
+	flagPkg.APIKeysFile = c.APIKeys.File
+
+	flagPkg.RequestTimeout = time.Duration(c.Timeouts.Request)
+	flagPkg.ShutdownTimeout = time.Duration(c.Timeouts.Shutdown)
+	flagPkg.LogDir = c.Logging.Dir
+
+	flagPkg.Insecure = c.Insecure
+	flagPkg.CAFile = c.CAFile
+	flagPkg.ReadOnly = c.Policy.ReadOnly
+	flagPkg.Debug = c.Logging.Debug
 }
 
 func Execute() {
+	var err error
+	cfg, err = parseConfig(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Load config error: %v\n", err)
+		os.Exit(1)
+	}
+	apply(cfg)
+	if flag.NArg() > 0 {
+		runCommand(flag.Args())
+		return
+	}
+
 	defer log.Default().Sync()
-	if err := operation.Run(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := operation.Run(ctx); err != nil {
 		if err == context.Canceled {
 			log.Info("Server shutdown due to context cancellation")
 			return
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"gitea.com/gitea/gitea-mcp/pkg/config"
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Setenv(config.EnvPath, "")
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "host: https://gitea.example.com\nport: 9090\ntransport: sse\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		args      []string
+		env       map[string]string
+		wantHost  string
+		wantPort  int
+		wantMode  string
+		wantArgs  []string
+		wantError bool
+		readOnly  bool
+	}{
+		{
+			name:     "config after a flag value",
+			args:     []string{"-t", "http", "--config", path, "config", "show"},
+			wantHost: "https://gitea.example.com",
+			wantPort: 9090,
+			wantMode: "http",
+			wantArgs: []string{"config", "show"},
+		},
+		{
+			name:     "flags override the file",
+			args:     []string{"--port=1234", "-config=" + path},
+			wantHost: "https://gitea.example.com",
+			wantPort: 1234,
+			wantMode: "sse",
+		},
+		{
+			name:     "environment overrides the file",
+			args:     []string{"--config", path},
+			env:      map[string]string{"GITEA_MCP_PORT": "7070"},
+			wantHost: "https://gitea.example.com",
+			wantPort: 7070,
+			wantMode: "sse",
+		},
+		{
+			name:     "file from the environment",
+			args:     []string{"-t", "stdio"},
+			env:      map[string]string{config.EnvPath: path},
+			wantHost: "https://gitea.example.com",
+			wantPort: 9090,
+			wantMode: "stdio",
+		},
+		{
+			name:     "yes as a boolean",
+			args:     []string{"-t", "http"},
+			env:      map[string]string{"GITEA_READONLY": "yes"},
+			wantHost: "https://gitea.com",
+			wantPort: 8080,
+			wantMode: "http",
+			readOnly: true,
+		},
+		{
+			name:      "invalid boolean",
+			env:       map[string]string{"GITEA_READONLY": "maybe"},
+			wantError: true,
+		},
+		{
+			name:     "no file",
+			args:     []string{"-t", "http"},
+			wantHost: "https://gitea.com",
+			wantPort: 8080,
+			wantMode: "http",
+		},
+		{
+			name:      "missing file",
+			args:      []string{"-t", "http", "--config", filepath.Join(t.TempDir(), "missing.yaml")},
+			wantError: true,
+		},
+		{
+			name:      "missing file from the environment",
+			env:       map[string]string{config.EnvPath: filepath.Join(t.TempDir(), "missing.yaml")},
+			wantError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			fs := flag.NewFlagSet("gitea-mcp", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			c, err := parseConfig(fs, tt.args)
+			if tt.wantError {
+				if err == nil {
+					t.Fatal("parseConfig succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseConfig: %v", err)
+			}
+			if c.Host != tt.wantHost || c.Port != tt.wantPort || c.Transport != tt.wantMode {
+				t.Errorf("host, port, transport = %s, %d, %s, want %s, %d, %s",
+					c.Host, c.Port, c.Transport, tt.wantHost, tt.wantPort, tt.wantMode)
+			}
+			if c.Policy.ReadOnly != tt.readOnly {
+				t.Errorf("read-only = %v, want %v", c.Policy.ReadOnly, tt.readOnly)
+			}
+			if !slices.Equal(fs.Args(), tt.wantArgs) {
+				t.Errorf("args = %q, want %q", fs.Args(), tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestRedactedConfig(t *testing.T) {
+	c := config.Default()
+	c.Token = "t0ken"
+	c.TokenCommand = "echo t0ken"
+	c.OAuth.ClientSecret = "t0ken"
+	shown := c.Redacted().String()
+	if strings.Contains(shown, "t0ken") {
+		t.Errorf("config show leaks a secret:\n%s", shown)
+	}
+	if c.TokenCommand != "echo t0ken" {
+		t.Errorf("Redacted changed the configuration: token command %q", c.TokenCommand)
+	}
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `Usage:
+  gitea-mcp [flags]                       run the MCP server
+  gitea-mcp [flags] config show           print the effective configuration
+  gitea-mcp [flags] tools list [-json]    list the available tools
+  gitea-mcp [flags] tools call <name> [--arg key=value]... [--json '{...}']
+                                          run a tool and print its result
+`
+
+// runCommand dispatches the subcommands given after the flags.
+func runCommand(args []string) {
+	switch args[0] {
+	case "config":
+		runConfig(args[1:])
+	case "tools":
+		runTools(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n%s", args[0], usage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+)
+
+// runConfig implements "gitea-mcp config show".
+func runConfig(args []string) {
+	if len(args) != 1 || args[0] != "show" {
+		fmt.Fprintln(os.Stderr, "Usage: gitea-mcp [flags] config show")
+		os.Exit(2)
+	}
+	fmt.Print(cfg.Redacted())
+}
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"gitea.com/gitea/gitea-mcp/operation"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// argFlag collects repeated --arg key=value flags.
+type argFlag []string
+
+func (a *argFlag) String() string {
+	return strings.Join(*a, ",")
+}
+
+func (a *argFlag) Set(v string) error {
+	*a = append(*a, v)
+	return nil
+}
+
+type toolInfo struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"inputSchema"`
+}
+
+// runTools implements "gitea-mcp tools list" and "gitea-mcp tools call". Both
+// go through the MCP server like a client would, so tools run exactly as they
+// do for agents.
+func runTools(args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	s, err := operation.NewServer()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Create server error: %v\n", err)
+		os.Exit(1)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	switch args[0] {
+	case "list":
+		err = listTools(ctx, s, args[1:])
+	case "call":
+		err = callTool(ctx, s, args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: tools %s\n\n%s", args[0], usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func listTools(ctx context.Context, s *server.MCPServer, args []string) error {
+	fs := flag.NewFlagSet("tools list", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print the tools as JSON")
+	_ = fs.Parse(args)
+
+	tools, err := fetchTools(ctx, s)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(tools)
+	}
+	for _, t := range tools {
+		var schema bytes.Buffer
+		if err := json.Indent(&schema, t.InputSchema, "  ", "  "); err != nil {
+			return err
+		}
+		fmt.Printf("%s\n  %s\n  %s\n\n", t.Name, t.Description, schema.String())
+	}
+	return nil
+}
+
+func callTool(ctx context.Context, s *server.MCPServer, args []string) error {
+	fs := flag.NewFlagSet("tools call", flag.ExitOnError)
+	var kvs argFlag
+	fs.Var(&kvs, "arg", "argument as key=value, may be repeated")
+	rawArgs := fs.String("json", "", "arguments as a JSON object")
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		return fmt.Errorf("tools call: tool name is required")
+	}
+	name := fs.Arg(0)
+	// Flags may also follow the tool name.
+	_ = fs.Parse(fs.Args()[1:])
+	if fs.NArg() > 0 {
+		return fmt.Errorf("tools call: unexpected arguments %v", fs.Args())
+	}
+
+	arguments := map[string]any{}
+	if *rawArgs != "" {
+		if err := json.Unmarshal([]byte(*rawArgs), &arguments); err != nil {
+			return fmt.Errorf("parse --json err: %v", err)
+		}
+	}
+	if len(kvs) > 0 {
+		tools, err := fetchTools(ctx, s)
+		if err != nil {
+			return err
+		}
+		var schema json.RawMessage
+		for _, t := range tools {
+			if t.Name == name {
+				schema = t.InputSchema
+			}
+		}
+		for _, kv := range kvs {
+			key, value, ok := strings.Cut(kv, "=")
+			if !ok {
+				return fmt.Errorf("invalid --arg %q, expected key=value", kv)
+			}
+			v, err := convertArg(schema, key, value)
+			if err != nil {
+				return err
+			}
+			arguments[key] = v
+		}
+	}
+
+	var result struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	}
+	params := map[string]any{"name": name, "arguments": arguments}
+	if err := request(ctx, s, "tools/call", params, &result); err != nil {
+		return err
+	}
+	out := os.Stdout
+	if result.IsError {
+		out = os.Stderr
+	}
+	for _, c := range result.Content {
+		if c.Type != "text" {
+			fmt.Fprintf(out, "[%s content]\n", c.Type)
+			continue
+		}
+		var pretty bytes.Buffer
+		if json.Indent(&pretty, []byte(c.Text), "", "  ") == nil {
+			fmt.Fprintln(out, pretty.String())
+		} else {
+			fmt.Fprintln(out, c.Text)
+		}
+	}
+	if result.IsError {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// convertArg converts value to the type the input schema declares for key.
+// Unknown arguments are passed as strings.
+func convertArg(schema json.RawMessage, key, value string) (any, error) {
+	var s struct {
+		Properties map[string]struct {
+			Type string `json:"type"`
+		} `json:"properties"`
+	}
+	if len(schema) > 0 {
+		_ = json.Unmarshal(schema, &s)
+	}
+	switch s.Properties[key].Type {
+	case "number", "integer":
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s must be a number: %v", key, err)
+		}
+		return v, nil
+	case "boolean":
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("argument %s must be a boolean: %v", key, err)
+		}
+		return v, nil
+	case "array", "object":
+		var v any
+		if err := json.Unmarshal([]byte(value), &v); err != nil {
+			return nil, fmt.Errorf("argument %s must be JSON: %v", key, err)
+		}
+		return v, nil
+	default:
+		return value, nil
+	}
+}
+
+func fetchTools(ctx context.Context, s *server.MCPServer) ([]toolInfo, error) {
+	var result struct {
+		Tools []toolInfo `json:"tools"`
+	}
+	if err := request(ctx, s, "tools/list", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Tools, nil
+}
+
+// request sends a JSON-RPC request to s and decodes its result into v.
+func request(ctx context.Context, s *server.MCPServer, method string, params, v any) error {
+	msg := map[string]any{"jsonrpc": "2.0", "id": 1, "method": method}
+	if params != nil {
+		msg["params"] = params
+	}
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(s.HandleMessage(ctx, raw))
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("%s", resp.Error.Message)
+	}
+	return json.Unmarshal(resp.Result, v)
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
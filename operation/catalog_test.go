@@ -0,0 +1,67 @@
+package operation
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"gitea.com/gitea/gitea-mcp/pkg/tool"
+)
+
+var update = flag.Bool("update", false, "rewrite tools.json and the README tool table from the registered tools")
+
+const (
+	catalogFile = "../tools.json"
+	readmeFile  = "../README.md"
+
+	tableStart = "<!-- tools:start -->\n"
+	tableEnd   = "<!-- tools:end -->\n"
+)
+
+// TestCatalog fails when a tool, argument or description changes without
+// tools.json being regenerated, so that renames clients depend on are never
+// accidental. Run "make catalog" after an intended change.
+func TestCatalog(t *testing.T) {
+	catalog := tool.Catalog()
+
+	data, err := json.MarshalIndent(catalog, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = append(data, '\n')
+	checkGolden(t, catalogFile, data)
+
+	readme, err := os.ReadFile(readmeFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, rest, ok := strings.Cut(string(readme), tableStart)
+	if !ok {
+		t.Fatalf("%s has no %q marker", readmeFile, tableStart)
+	}
+	_, after, ok := strings.Cut(rest, tableEnd)
+	if !ok {
+		t.Fatalf("%s has no %q marker", readmeFile, tableEnd)
+	}
+	checkGolden(t, readmeFile, []byte(before+tableStart+"\n"+tool.MarkdownTable(catalog)+"\n"+tableEnd+after))
+}
+
+func checkGolden(t *testing.T, path string, want []byte) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(path, want, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s is out of date with the registered tools, run \"make catalog\" and review the diff", path)
+	}
+}
@@ -0,0 +1,108 @@
+package operation
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gitea.com/gitea/gitea-mcp/pkg/flag"
+	"gitea.com/gitea/gitea-mcp/pkg/gitea"
+	"gitea.com/gitea/gitea-mcp/pkg/log"
+)
+
+// readinessCacheTTL is how long the result of a readiness probe is reused, so
+// frequent load balancer checks do not translate into Gitea API calls.
+const readinessCacheTTL = 5 * time.Second
+
+type readiness struct {
+	Ready        bool   `json:"ready"`
+	GiteaVersion string `json:"gitea_version,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+var (
+	readinessMu      sync.Mutex
+	lastReadiness    readiness
+	lastReadinessAt  time.Time
+	registeredTools  atomic.Int64
+	enabledTransport []string
+)
+
+// registerHealth mounts the health and readiness endpoints on mux, outside
+// of the authentication layers so probes do not need credentials, and the
+// info endpoint behind protect, since it reveals the versions in use.
+func registerHealth(mux *http.ServeMux, protect func(http.Handler) http.Handler) {
+	mux.HandleFunc("/healthz", handleHealthz)
+	mux.HandleFunc("/readyz", handleReadyz)
+	mux.Handle("/info", protect(http.HandlerFunc(handleInfo)))
+}
+
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if calls.isDraining() {
+		writeJSON(w, http.StatusServiceUnavailable, readiness{Error: errShuttingDown.Error()})
+		return
+	}
+	state := checkReadiness()
+	status := http.StatusOK
+	if !state.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, state)
+}
+
+func handleInfo(w http.ResponseWriter, r *http.Request) {
+	state := checkReadiness()
+	writeJSON(w, http.StatusOK, map[string]any{
+		"version":       flag.Version,
+		"gitea_version": state.GiteaVersion,
+		"transports":    enabledTransport,
+		"read_only":     flag.ReadOnly,
+		"tool_count":    registeredTools.Load(),
+	})
+}
+
+// checkReadiness verifies that the primary Gitea instance is reachable and,
+// if a token is configured, that it is accepted.
+func checkReadiness() readiness {
+	readinessMu.Lock()
+	defer readinessMu.Unlock()
+	if time.Since(lastReadinessAt) < readinessCacheTTL {
+		return lastReadiness
+	}
+
+	state := readiness{Ready: true}
+	client := gitea.Client()
+	version, _, err := client.ServerVersion()
+	if err != nil {
+		state = readiness{Error: "get gitea version err: " + err.Error()}
+	} else {
+		state.GiteaVersion = version
+		if inst, err := gitea.LookupInstance(""); err == nil && inst.HasToken() {
+			if _, _, err := client.GetMyUserInfo(); err != nil {
+				state.Ready = false
+				state.Error = "verify token err: " + err.Error()
+			}
+		}
+	}
+	if !state.Ready {
+		log.Warnf("Readiness check failed: %s", state.Error)
+	}
+
+	lastReadiness = state
+	lastReadinessAt = time.Now()
+	return state
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Errorf("write json response err: %v", err)
+	}
+}
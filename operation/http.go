@@ -0,0 +1,72 @@
+package operation
+
+import (
+	"fmt"
+	"net/http"
+
+	"gitea.com/gitea/gitea-mcp/pkg/auth"
+	"gitea.com/gitea/gitea-mcp/pkg/flag"
+	"gitea.com/gitea/gitea-mcp/pkg/log"
+)
+
+// newHTTPServer returns the server of the sse and http transports, serving
+// handler behind the client certificate and API key checks enabled by flags.
+// The health and readiness endpoints are left unprotected, as are the routes
+// public mounts, if not nil.
+func newHTTPServer(handler http.Handler, public func(*http.ServeMux)) (*http.Server, error) {
+	if (flag.TLSCert == "") != (flag.TLSKey == "") {
+		return nil, fmt.Errorf("--tls-cert and --tls-key must be set together")
+	}
+	if flag.TLSClientCA != "" && flag.TLSCert == "" {
+		return nil, fmt.Errorf("--tls-client-ca requires --tls-cert and --tls-key")
+	}
+
+	keys, err := auth.LoadAPIKeys(flag.APIKeys, flag.APIKeysFile)
+	if err != nil {
+		return nil, err
+	}
+	var layers []func(http.Handler) http.Handler
+	if len(keys) > 0 {
+		layers = append(layers, auth.APIKeys(keys, !flag.OAuth))
+		log.Infof("API key authentication enabled with %d key(s)", len(keys))
+	}
+
+	srv := &http.Server{
+		Addr: fmt.Sprintf(":%d", flag.Port),
+	}
+	if flag.TLSCert != "" {
+		srv.TLSConfig, err = auth.TLSConfig(flag.TLSClientCA)
+		if err != nil {
+			return nil, err
+		}
+		if flag.TLSClientCA != "" {
+			// Checked first, a request without certificate is rejected before
+			// its API key is looked at.
+			layers = append(layers, auth.RequireClientCert)
+			log.Infof("Client certificate verification enabled")
+		}
+	}
+	protect := func(h http.Handler) http.Handler {
+		for _, layer := range layers {
+			h = layer(h)
+		}
+		return h
+	}
+
+	root := http.NewServeMux()
+	registerHealth(root, protect)
+	if public != nil {
+		public(root)
+	}
+	root.Handle("/", protect(handler))
+	srv.Handler = root
+	return srv, nil
+}
+
+// listen serves srv over TLS when a certificate is configured.
+func listen(srv *http.Server) error {
+	if flag.TLSCert != "" {
+		return srv.ListenAndServeTLS(flag.TLSCert, flag.TLSKey)
+	}
+	return srv.ListenAndServe()
+}
@@ -0,0 +1,59 @@
+package instance
+
+import (
+	"context"
+
+	"gitea.com/gitea/gitea-mcp/pkg/gitea"
+	"gitea.com/gitea/gitea-mcp/pkg/log"
+	"gitea.com/gitea/gitea-mcp/pkg/to"
+	"gitea.com/gitea/gitea-mcp/pkg/tool"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+var Tool = tool.New()
+
+const (
+	ListGiteaInstancesToolName = "list_gitea_instances"
+)
+
+var ListGiteaInstancesTool = mcp.NewTool(
+	ListGiteaInstancesToolName,
+	mcp.WithDescription("List the configured Gitea instances that tools can target with the instance argument"),
+	to.OutputSchema[[]instanceInfo](),
+)
+
+func init() {
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    ListGiteaInstancesTool,
+		Handler: ListGiteaInstancesFn,
+	})
+}
+
+type instanceInfo struct {
+	Name     string `json:"name"`
+	Host     string `json:"host"`
+	Insecure bool   `json:"insecure"`
+	CAFile   string `json:"ca_file,omitempty"`
+	ReadOnly bool   `json:"read_only"`
+	Primary  bool   `json:"primary"`
+}
+
+func ListGiteaInstancesFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListGiteaInstancesFn")
+	primary := gitea.PrimaryInstance()
+	instances := gitea.Instances()
+	infos := make([]instanceInfo, 0, len(instances))
+	for _, inst := range instances {
+		infos = append(infos, instanceInfo{
+			Name:     inst.Name,
+			Host:     inst.Host,
+			Insecure: inst.Insecure,
+			CAFile:   inst.CAFile,
+			ReadOnly: inst.ReadOnly,
+			Primary:  inst.Name == primary,
+		})
+	}
+	return to.Result(infos)
+}
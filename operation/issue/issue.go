@@ -15,7 +15,7 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 )
 
-var Tool = tool.New()
+var Tool = tool.New(tool.Scope(gitea.ScopeIssue))
 
 const (
 	GetIssueByIndexToolName         = "get_issue_by_index"
@@ -34,6 +34,7 @@ var (
 		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
 		mcp.WithNumber("index", mcp.Required(), mcp.Description("repository issue index")),
+		to.OutputSchema[*gitea_sdk.Issue](),
 	)
 
 	ListRepoIssuesTool = mcp.NewTool(
@@ -43,7 +44,8 @@ var (
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
 		mcp.WithString("state", mcp.Description("issue state"), mcp.DefaultString("all")),
 		mcp.WithNumber("page", mcp.Description("page number"), mcp.DefaultNumber(1)),
-		mcp.WithNumber("pageSize", mcp.Description("page size"), mcp.DefaultNumber(100)),
+		mcp.WithNumber("page_size", mcp.Description("page size"), mcp.DefaultNumber(100)),
+		to.OutputSchema[[]*gitea_sdk.Issue](),
 	)
 
 	CreateIssueTool = mcp.NewTool(
@@ -53,6 +55,7 @@ var (
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
 		mcp.WithString("title", mcp.Required(), mcp.Description("issue title")),
 		mcp.WithString("body", mcp.Required(), mcp.Description("issue body")),
+		to.OutputSchema[*gitea_sdk.Issue](),
 	)
 
 	CreateIssueCommentTool = mcp.NewTool(
@@ -62,6 +65,7 @@ var (
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
 		mcp.WithNumber("index", mcp.Required(), mcp.Description("repository issue index")),
 		mcp.WithString("body", mcp.Required(), mcp.Description("issue comment body")),
+		to.OutputSchema[*gitea_sdk.Comment](),
 	)
 
 	EditIssueTool = mcp.NewTool(
@@ -75,6 +79,7 @@ var (
 		mcp.WithArray("assignees", mcp.Description("usernames to assign to this issue"), mcp.Items(map[string]interface{}{"type": "string"})),
 		mcp.WithNumber("milestone", mcp.Description("milestone number")),
 		mcp.WithString("state", mcp.Description("issue state, one of open, closed, all")),
+		to.OutputSchema[*gitea_sdk.Issue](),
 	)
 
 	EditIssueCommentTool = mcp.NewTool(
@@ -82,8 +87,9 @@ var (
 		mcp.WithDescription("edit issue comment"),
 		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
-		mcp.WithNumber("commentID", mcp.Required(), mcp.Description("id of issue comment")),
+		mcp.WithNumber("comment_id", mcp.Required(), mcp.Description("id of issue comment")),
 		mcp.WithString("body", mcp.Required(), mcp.Description("issue comment body")),
+		to.OutputSchema[*gitea_sdk.Comment](),
 	)
 
 	GetIssueCommentsByIndexTool = mcp.NewTool(
@@ -92,6 +98,7 @@ var (
 		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
 		mcp.WithNumber("index", mcp.Required(), mcp.Description("repository issue index")),
+		to.OutputSchema[[]*gitea_sdk.Comment](),
 	)
 )
 
@@ -103,7 +110,7 @@ func init() {
 	Tool.RegisterRead(server.ServerTool{
 		Tool:    ListRepoIssuesTool,
 		Handler: ListRepoIssuesFn,
-	})
+	}, tool.Alias("pageSize", "page_size"))
 	Tool.RegisterWrite(server.ServerTool{
 		Tool:    CreateIssueTool,
 		Handler: CreateIssueFn,
@@ -119,7 +126,7 @@ func init() {
 	Tool.RegisterWrite(server.ServerTool{
 		Tool:    EditIssueCommentTool,
 		Handler: EditIssueCommentFn,
-	})
+	}, tool.Alias("commentID", "comment_id"))
 	Tool.RegisterRead(server.ServerTool{
 		Tool:    GetIssueCommentsByIndexTool,
 		Handler: GetIssueCommentsByIndexFn,
@@ -140,12 +147,12 @@ func GetIssueByIndexFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 	if !ok {
 		return to.ErrorResult(fmt.Errorf("index is required"))
 	}
-	issue, _, err := gitea.Client().GetIssue(owner, repo, int64(index))
+	issue, _, err := gitea.ClientFromContext(ctx).GetIssue(owner, repo, int64(index))
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("get %v/%v/issue/%v err: %v", owner, repo, int64(index), err))
 	}
 
-	return to.TextResult(issue)
+	return to.Result(issue)
 }
 
 func ListRepoIssuesFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -166,7 +173,7 @@ func ListRepoIssuesFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 	if !ok {
 		page = 1
 	}
-	pageSize, ok := req.GetArguments()["pageSize"].(float64)
+	pageSize, ok := req.GetArguments()["page_size"].(float64)
 	if !ok {
 		pageSize = 100
 	}
@@ -177,11 +184,11 @@ func ListRepoIssuesFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 			PageSize: int(pageSize),
 		},
 	}
-	issues, _, err := gitea.Client().ListRepoIssues(owner, repo, opt)
+	issues, _, err := gitea.ClientFromContext(ctx).ListRepoIssues(owner, repo, opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("get %v/%v/issues err: %v", owner, repo, err))
 	}
-	return to.TextResult(issues)
+	return to.Result(issues)
 }
 
 func CreateIssueFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -202,7 +209,7 @@ func CreateIssueFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 	if !ok {
 		return to.ErrorResult(fmt.Errorf("body is required"))
 	}
-	issue, _, err := gitea.Client().CreateIssue(owner, repo, gitea_sdk.CreateIssueOption{
+	issue, _, err := gitea.ClientFromContext(ctx).CreateIssue(owner, repo, gitea_sdk.CreateIssueOption{
 		Title: title,
 		Body:  body,
 	})
@@ -210,7 +217,7 @@ func CreateIssueFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 		return to.ErrorResult(fmt.Errorf("create %v/%v/issue err: %v", owner, repo, err))
 	}
 
-	return to.TextResult(issue)
+	return to.Result(issue)
 }
 
 func CreateIssueCommentFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -234,12 +241,12 @@ func CreateIssueCommentFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.Ca
 	opt := gitea_sdk.CreateIssueCommentOption{
 		Body: body,
 	}
-	issueComment, _, err := gitea.Client().CreateIssueComment(owner, repo, int64(index), opt)
+	issueComment, _, err := gitea.ClientFromContext(ctx).CreateIssueComment(owner, repo, int64(index), opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("create %v/%v/issue/%v/comment err: %v", owner, repo, int64(index), err))
 	}
 
-	return to.TextResult(issueComment)
+	return to.Result(issueComment)
 }
 
 func EditIssueFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -267,9 +274,16 @@ func EditIssueFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRes
 	if ok {
 		opt.Body = ptr.To(body)
 	}
-	assignees, ok := req.GetArguments()["assignees"].([]string)
+	assignees, ok := req.GetArguments()["assignees"].([]interface{})
 	if ok {
-		opt.Assignees = assignees
+		opt.Assignees = make([]string, 0, len(assignees))
+		for _, assignee := range assignees {
+			name, ok := assignee.(string)
+			if !ok {
+				return to.ErrorResult(fmt.Errorf("invalid username in assignees array"))
+			}
+			opt.Assignees = append(opt.Assignees, name)
+		}
 	}
 	milestone, ok := req.GetArguments()["milestone"].(float64)
 	if ok {
@@ -280,12 +294,12 @@ func EditIssueFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRes
 		opt.State = ptr.To(gitea_sdk.StateType(state))
 	}
 
-	issue, _, err := gitea.Client().EditIssue(owner, repo, int64(index), opt)
+	issue, _, err := gitea.ClientFromContext(ctx).EditIssue(owner, repo, int64(index), opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("edit %v/%v/issue/%v err: %v", owner, repo, int64(index), err))
 	}
 
-	return to.TextResult(issue)
+	return to.Result(issue)
 }
 
 func EditIssueCommentFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -298,7 +312,7 @@ func EditIssueCommentFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 	if !ok {
 		return to.ErrorResult(fmt.Errorf("repo is required"))
 	}
-	commentID, ok := req.GetArguments()["commentID"].(float64)
+	commentID, ok := req.GetArguments()["comment_id"].(float64)
 	if !ok {
 		return to.ErrorResult(fmt.Errorf("comment ID is required"))
 	}
@@ -309,12 +323,12 @@ func EditIssueCommentFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 	opt := gitea_sdk.EditIssueCommentOption{
 		Body: body,
 	}
-	issueComment, _, err := gitea.Client().EditIssueComment(owner, repo, int64(commentID), opt)
+	issueComment, _, err := gitea.ClientFromContext(ctx).EditIssueComment(owner, repo, int64(commentID), opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("edit %v/%v/issues/comments/%v err: %v", owner, repo, int64(commentID), err))
 	}
 
-	return to.TextResult(issueComment)
+	return to.Result(issueComment)
 }
 
 func GetIssueCommentsByIndexFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -332,10 +346,10 @@ func GetIssueCommentsByIndexFn(ctx context.Context, req mcp.CallToolRequest) (*m
 		return to.ErrorResult(fmt.Errorf("index is required"))
 	}
 	opt := gitea_sdk.ListIssueCommentOptions{}
-	issue, _, err := gitea.Client().ListIssueComments(owner, repo, int64(index), opt)
+	issue, _, err := gitea.ClientFromContext(ctx).ListIssueComments(owner, repo, int64(index), opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("get %v/%v/issues/%v/comments err: %v", owner, repo, int64(index), err))
 	}
 
-	return to.TextResult(issue)
+	return to.Result(issue)
 }
@@ -15,7 +15,7 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 )
 
-var Tool = tool.New()
+var Tool = tool.New(tool.Scope(gitea.ScopeIssue))
 
 const (
 	ListRepoLabelsToolName     = "list_repo_labels"
@@ -36,7 +36,8 @@ var (
 		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
 		mcp.WithNumber("page", mcp.Description("page number"), mcp.DefaultNumber(1)),
-		mcp.WithNumber("pageSize", mcp.Description("page size"), mcp.DefaultNumber(100)),
+		mcp.WithNumber("page_size", mcp.Description("page size"), mcp.DefaultNumber(100)),
+		to.OutputSchema[[]*gitea_sdk.Label](),
 	)
 
 	GetRepoLabelTool = mcp.NewTool(
@@ -45,6 +46,7 @@ var (
 		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
 		mcp.WithNumber("id", mcp.Required(), mcp.Description("label ID")),
+		to.OutputSchema[*gitea_sdk.Label](),
 	)
 
 	CreateRepoLabelTool = mcp.NewTool(
@@ -55,6 +57,7 @@ var (
 		mcp.WithString("name", mcp.Required(), mcp.Description("label name")),
 		mcp.WithString("color", mcp.Required(), mcp.Description("label color (hex code, e.g., #RRGGBB)")),
 		mcp.WithString("description", mcp.Description("label description")),
+		to.OutputSchema[*gitea_sdk.Label](),
 	)
 
 	EditRepoLabelTool = mcp.NewTool(
@@ -66,6 +69,7 @@ var (
 		mcp.WithString("name", mcp.Description("new label name")),
 		mcp.WithString("color", mcp.Description("new label color (hex code, e.g., #RRGGBB)")),
 		mcp.WithString("description", mcp.Description("new label description")),
+		to.OutputSchema[*gitea_sdk.Label](),
 	)
 
 	DeleteRepoLabelTool = mcp.NewTool(
@@ -74,6 +78,7 @@ var (
 		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
 		mcp.WithNumber("id", mcp.Required(), mcp.Description("label ID")),
+		to.OutputSchema[string](),
 	)
 
 	AddIssueLabelsTool = mcp.NewTool(
@@ -83,6 +88,7 @@ var (
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
 		mcp.WithNumber("index", mcp.Required(), mcp.Description("issue index")),
 		mcp.WithArray("labels", mcp.Required(), mcp.Description("array of label IDs to add"), mcp.Items(map[string]interface{}{"type": "number"})),
+		to.OutputSchema[[]*gitea_sdk.Label](),
 	)
 
 	ReplaceIssueLabelsTool = mcp.NewTool(
@@ -92,6 +98,7 @@ var (
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
 		mcp.WithNumber("index", mcp.Required(), mcp.Description("issue index")),
 		mcp.WithArray("labels", mcp.Required(), mcp.Description("array of label IDs to replace with"), mcp.Items(map[string]interface{}{"type": "number"})),
+		to.OutputSchema[[]*gitea_sdk.Label](),
 	)
 
 	ClearIssueLabelsTool = mcp.NewTool(
@@ -100,6 +107,7 @@ var (
 		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
 		mcp.WithNumber("index", mcp.Required(), mcp.Description("issue index")),
+		to.OutputSchema[string](),
 	)
 
 	RemoveIssueLabelTool = mcp.NewTool(
@@ -109,6 +117,7 @@ var (
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
 		mcp.WithNumber("index", mcp.Required(), mcp.Description("issue index")),
 		mcp.WithNumber("label_id", mcp.Required(), mcp.Description("label ID to remove")),
+		to.OutputSchema[string](),
 	)
 )
 
@@ -116,7 +125,7 @@ func init() {
 	Tool.RegisterRead(server.ServerTool{
 		Tool:    ListRepoLabelsTool,
 		Handler: ListRepoLabelsFn,
-	})
+	}, tool.Alias("pageSize", "page_size"))
 	Tool.RegisterRead(server.ServerTool{
 		Tool:    GetRepoLabelTool,
 		Handler: GetRepoLabelFn,
@@ -165,7 +174,7 @@ func ListRepoLabelsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 	if !ok {
 		page = 1
 	}
-	pageSize, ok := req.GetArguments()["pageSize"].(float64)
+	pageSize, ok := req.GetArguments()["page_size"].(float64)
 	if !ok {
 		pageSize = 100
 	}
@@ -176,11 +185,11 @@ func ListRepoLabelsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 			PageSize: int(pageSize),
 		},
 	}
-	labels, _, err := gitea.Client().ListRepoLabels(owner, repo, opt)
+	labels, _, err := gitea.ClientFromContext(ctx).ListRepoLabels(owner, repo, opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("list %v/%v/labels err: %v", owner, repo, err))
 	}
-	return to.TextResult(labels)
+	return to.Result(labels)
 }
 
 func GetRepoLabelFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -198,11 +207,11 @@ func GetRepoLabelFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTool
 		return to.ErrorResult(fmt.Errorf("label ID is required"))
 	}
 
-	label, _, err := gitea.Client().GetRepoLabel(owner, repo, int64(id))
+	label, _, err := gitea.ClientFromContext(ctx).GetRepoLabel(owner, repo, int64(id))
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("get %v/%v/label/%v err: %v", owner, repo, int64(id), err))
 	}
-	return to.TextResult(label)
+	return to.Result(label)
 }
 
 func CreateRepoLabelFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -231,11 +240,11 @@ func CreateRepoLabelFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 		Description: description,
 	}
 
-	label, _, err := gitea.Client().CreateLabel(owner, repo, opt)
+	label, _, err := gitea.ClientFromContext(ctx).CreateLabel(owner, repo, opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("create %v/%v/label err: %v", owner, repo, err))
 	}
-	return to.TextResult(label)
+	return to.Result(label)
 }
 
 func EditRepoLabelFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -264,11 +273,11 @@ func EditRepoLabelFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToo
 		opt.Description = ptr.To(description)
 	}
 
-	label, _, err := gitea.Client().EditLabel(owner, repo, int64(id), opt)
+	label, _, err := gitea.ClientFromContext(ctx).EditLabel(owner, repo, int64(id), opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("edit %v/%v/label/%v err: %v", owner, repo, int64(id), err))
 	}
-	return to.TextResult(label)
+	return to.Result(label)
 }
 
 func DeleteRepoLabelFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -286,11 +295,11 @@ func DeleteRepoLabelFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 		return to.ErrorResult(fmt.Errorf("label ID is required"))
 	}
 
-	_, err := gitea.Client().DeleteLabel(owner, repo, int64(id))
+	_, err := gitea.ClientFromContext(ctx).DeleteLabel(owner, repo, int64(id))
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("delete %v/%v/label/%v err: %v", owner, repo, int64(id), err))
 	}
-	return to.TextResult("Label deleted successfully")
+	return to.Result("Label deleted successfully")
 }
 
 func AddIssueLabelsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -324,11 +333,11 @@ func AddIssueLabelsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 		Labels: labels,
 	}
 
-	issueLabels, _, err := gitea.Client().AddIssueLabels(owner, repo, int64(index), opt)
+	issueLabels, _, err := gitea.ClientFromContext(ctx).AddIssueLabels(owner, repo, int64(index), opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("add labels to %v/%v/issue/%v err: %v", owner, repo, int64(index), err))
 	}
-	return to.TextResult(issueLabels)
+	return to.Result(issueLabels)
 }
 
 func ReplaceIssueLabelsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -362,11 +371,11 @@ func ReplaceIssueLabelsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.Ca
 		Labels: labels,
 	}
 
-	issueLabels, _, err := gitea.Client().ReplaceIssueLabels(owner, repo, int64(index), opt)
+	issueLabels, _, err := gitea.ClientFromContext(ctx).ReplaceIssueLabels(owner, repo, int64(index), opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("replace labels on %v/%v/issue/%v err: %v", owner, repo, int64(index), err))
 	}
-	return to.TextResult(issueLabels)
+	return to.Result(issueLabels)
 }
 
 func ClearIssueLabelsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -384,11 +393,11 @@ func ClearIssueLabelsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 		return to.ErrorResult(fmt.Errorf("issue index is required"))
 	}
 
-	_, err := gitea.Client().ClearIssueLabels(owner, repo, int64(index))
+	_, err := gitea.ClientFromContext(ctx).ClearIssueLabels(owner, repo, int64(index))
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("clear labels on %v/%v/issue/%v err: %v", owner, repo, int64(index), err))
 	}
-	return to.TextResult("Labels cleared successfully")
+	return to.Result("Labels cleared successfully")
 }
 
 func RemoveIssueLabelFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -410,9 +419,9 @@ func RemoveIssueLabelFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 		return to.ErrorResult(fmt.Errorf("label ID is required"))
 	}
 
-	_, err := gitea.Client().DeleteIssueLabel(owner, repo, int64(index), int64(labelID))
+	_, err := gitea.ClientFromContext(ctx).DeleteIssueLabel(owner, repo, int64(index), int64(labelID))
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("remove label %v from %v/%v/issue/%v err: %v", int64(labelID), owner, repo, int64(index), err))
 	}
-	return to.TextResult("Label removed successfully")
+	return to.Result("Label removed successfully")
 }
@@ -1,82 +1,189 @@
 package operation
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"os"
+	"strings"
 	"time"
 
+	"gitea.com/gitea/gitea-mcp/operation/instance"
 	"gitea.com/gitea/gitea-mcp/operation/issue"
 	"gitea.com/gitea/gitea-mcp/operation/label"
+	"gitea.com/gitea/gitea-mcp/operation/org"
 	"gitea.com/gitea/gitea-mcp/operation/pull"
 	"gitea.com/gitea/gitea-mcp/operation/repo"
 	"gitea.com/gitea/gitea-mcp/operation/search"
+	"gitea.com/gitea/gitea-mcp/operation/team"
 	"gitea.com/gitea/gitea-mcp/operation/user"
 	"gitea.com/gitea/gitea-mcp/operation/version"
+	"gitea.com/gitea/gitea-mcp/pkg/auth"
 	"gitea.com/gitea/gitea-mcp/pkg/flag"
+	"gitea.com/gitea/gitea-mcp/pkg/gitea"
 	"gitea.com/gitea/gitea-mcp/pkg/log"
+	"gitea.com/gitea/gitea-mcp/pkg/tool"
 
 	"github.com/mark3labs/mcp-go/server"
 )
 
+const httpEndpointPath = "/mcp"
+
 var mcpServer *server.MCPServer
 
 func RegisterTool(s *server.MCPServer) {
+	count := 0
+
 	// User Tool
-	s.AddTools(user.Tool.Tools()...)
+	count += addTools(s, user.Tool.Tools()...)
 
 	// Repo Tool
-	s.AddTools(repo.Tool.Tools()...)
+	count += addTools(s, repo.Tool.Tools()...)
 
 	// Issue Tool
-	s.AddTools(issue.Tool.Tools()...)
+	count += addTools(s, issue.Tool.Tools()...)
 
 	// Label Tool
-	s.AddTools(label.Tool.Tools()...)
+	count += addTools(s, label.Tool.Tools()...)
 
 	// Pull Tool
-	s.AddTools(pull.Tool.Tools()...)
+	count += addTools(s, pull.Tool.Tools()...)
 
 	// Search Tool
-	s.AddTools(search.Tool.Tools()...)
+	count += addTools(s, search.Tool.Tools()...)
+
+	// Team Tool
+	count += addTools(s, team.Tool.Tools()...)
+
+	// Org Tool
+	count += addTools(s, org.Tool.Tools()...)
 
 	// Version Tool
-	s.AddTools(version.Tool.Tools()...)
+	count += addTools(s, version.Tool.Tools()...)
+
+	// Instance Tool
+	count += addTools(s, instance.Tool.Tools()...)
 
 	s.DeleteTools("")
+	registeredTools.Store(int64(count))
+}
+
+func addTools(s *server.MCPServer, tools ...server.ServerTool) int {
+	s.AddTools(tools...)
+	return len(tools)
+}
+
+// NewServer loads the configured Gitea instances and returns an MCP server
+// with every tool registered.
+func NewServer() (*server.MCPServer, error) {
+	if err := gitea.LoadInstances(flag.InstancesFile); err != nil {
+		return nil, err
+	}
+	s := newMCPServer(flag.Version)
+	RegisterTool(s)
+	return s, nil
 }
 
-func Run() error {
-	mcpServer = newMCPServer(flag.Version)
-	RegisterTool(mcpServer)
+// Run serves the MCP server on the configured transport until ctx is
+// cancelled, in which case it shuts down gracefully and returns ctx.Err().
+func Run(ctx context.Context) error {
+	var err error
+	mcpServer, err = NewServer()
+	if err != nil {
+		return err
+	}
+	go detectScopes()
+	if flag.OAuth && flag.Mode != "http" {
+		return fmt.Errorf("oauth is only supported with the http transport")
+	}
+	enabledTransport = []string{flag.Mode}
 	switch flag.Mode {
 	case "stdio":
-		if err := server.ServeStdio(
-			mcpServer,
-		); err != nil {
+		// Tool calls inherit the context passed to Listen, so it is only
+		// cancelled once the calls in flight are done.
+		serveCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		defer cancel()
+		stdioServer := server.NewStdioServer(mcpServer)
+		return serveUntil(ctx, func() error {
+			return stdioServer.Listen(serveCtx, os.Stdin, os.Stdout)
+		}, func(context.Context) error {
+			cancel()
+			return nil
+		})
+	case "sse":
+		mux := http.NewServeMux()
+		srv, err := newHTTPServer(rejectWhileDraining(mux), nil)
+		if err != nil {
 			return err
 		}
-	case "sse":
 		sseServer := server.NewSSEServer(
 			mcpServer,
+			server.WithHTTPServer(srv),
 		)
+		mux.Handle("/", sseServer)
 		log.Infof("Gitea MCP SSE server listening on :%d", flag.Port)
-		if err := sseServer.Start(fmt.Sprintf(":%d", flag.Port)); err != nil {
+		return serveUntil(ctx, func() error {
+			return listen(srv)
+		}, sseServer.Shutdown)
+	case "http":
+		var (
+			oauth  *auth.OAuth
+			public func(*http.ServeMux)
+		)
+		if flag.OAuth {
+			var err error
+			oauth, err = newOAuth()
+			if err != nil {
+				return err
+			}
+			// MCP clients discover the authorization server before they
+			// have any credentials.
+			public = func(mux *http.ServeMux) {
+				oauth.RegisterMetadata(mux, httpEndpointPath)
+			}
+		}
+		mux := http.NewServeMux()
+		srv, err := newHTTPServer(rejectWhileDraining(mux), public)
+		if err != nil {
 			return err
 		}
-	case "http":
 		httpServer := server.NewStreamableHTTPServer(
 			mcpServer,
 			server.WithLogger(log.New()),
 			server.WithHeartbeatInterval(30*time.Second),
 			server.WithStateLess(true),
+			server.WithStreamableHTTPServer(srv),
 		)
-		log.Infof("Gitea MCP HTTP server listening on :%d", flag.Port)
-		if err := httpServer.Start(fmt.Sprintf(":%d", flag.Port)); err != nil {
-			return err
+		if oauth != nil {
+			mux.Handle(httpEndpointPath, oauth.Middleware(httpServer))
+			log.Infof("OAuth2 authorization enabled with issuer %s", flag.OAuthIssuer)
+		} else {
+			mux.Handle(httpEndpointPath, httpServer)
 		}
+		log.Infof("Gitea MCP HTTP server listening on :%d", flag.Port)
+		return serveUntil(ctx, func() error {
+			return listen(srv)
+		}, httpServer.Shutdown)
 	default:
 		return fmt.Errorf("invalid transport type: %s. Must be 'stdio', 'sse' or 'http'", flag.Mode)
 	}
-	return nil
+}
+
+// newOAuth returns the OAuth guard of the http transport. The tokens it
+// accepts are only used for the instance of the issuer.
+func newOAuth() (*auth.OAuth, error) {
+	inst, err := gitea.InstanceByHost(flag.OAuthIssuer)
+	if err != nil {
+		return nil, fmt.Errorf("oauth issuer: %v", err)
+	}
+	return auth.NewOAuth(auth.OAuthOptions{
+		Issuer:       flag.OAuthIssuer,
+		Instance:     inst.Name,
+		ClientID:     flag.OAuthClientID,
+		ClientSecret: flag.OAuthClientSecret,
+		ResourceURL:  flag.PublicURL,
+		TLSConfig:    inst.TLSConfig(),
+	})
 }
 
 func newMCPServer(version string) *server.MCPServer {
@@ -86,5 +193,26 @@ func newMCPServer(version string) *server.MCPServer {
 		server.WithToolCapabilities(true),
 		server.WithLogging(),
 		server.WithRecovery(),
+		server.WithToolHandlerMiddleware(trackCalls),
+		server.WithToolFilter(tool.Filter),
 	)
 }
+
+// detectScopes probes the scopes of the primary instance's token at startup,
+// so the first tools/list does not wait for it.
+func detectScopes() {
+	caps, err := gitea.CapabilitiesFromContext(context.Background())
+	if err != nil {
+		log.Warnf("Detect token scopes err: %v", err)
+		return
+	}
+	var missing []string
+	for _, info := range tool.Registered() {
+		if !caps.Allows(info.Scope, info.Write) {
+			missing = append(missing, info.Name)
+		}
+	}
+	if len(missing) > 0 {
+		log.Infof("The token lacks the scopes required by: %s", strings.Join(missing, ", "))
+	}
+}
@@ -0,0 +1,471 @@
+package operation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"gitea.com/gitea/gitea-mcp/pkg/auth"
+	"gitea.com/gitea/gitea-mcp/pkg/flag"
+	"gitea.com/gitea/gitea-mcp/pkg/gitea"
+	"gitea.com/gitea/gitea-mcp/pkg/giteatest"
+	"gitea.com/gitea/gitea-mcp/pkg/log"
+	"gitea.com/gitea/gitea-mcp/pkg/tool"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.uber.org/zap"
+)
+
+const testToken = "secret"
+
+func TestMain(m *testing.M) {
+	log.SetDefault(zap.NewNop())
+	os.Exit(m.Run())
+}
+
+// newTestServer returns an MCP server with every tool registered, talking to
+// fake with its token.
+func newTestServer(t *testing.T, fake *giteatest.Server, readOnly bool) *server.MCPServer {
+	t.Helper()
+	host, token, ro := flag.Host, flag.Token, flag.ReadOnly
+	t.Cleanup(func() {
+		flag.Host, flag.Token, flag.ReadOnly = host, token, ro
+		gitea.SetInstances(gitea.DefaultInstanceName, nil)
+	})
+	flag.Host, flag.Token, flag.ReadOnly = fake.URL, fake.Token, readOnly
+
+	s, err := NewServer()
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	return s
+}
+
+// rpcError is a JSON-RPC error returned instead of a result.
+type rpcError struct {
+	Code    int
+	Message string
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("%d: %s", e.Code, e.Message)
+}
+
+// rpc sends a JSON-RPC request to s and decodes its result into v.
+func rpc(t *testing.T, s *server.MCPServer, method string, params, v any) error {
+	t.Helper()
+	raw, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(s.HandleMessage(context.Background(), raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		t.Fatalf("decode response %s: %v", data, err)
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	if err := json.Unmarshal(resp.Result, v); err != nil {
+		t.Fatalf("decode result %s: %v", resp.Result, err)
+	}
+	return nil
+}
+
+// callTool calls a tool and returns the structured content of its result.
+func callTool(t *testing.T, s *server.MCPServer, name string, args map[string]any) (string, error) {
+	t.Helper()
+	// mcp.CallToolResult does not decode structured content.
+	var result struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		StructuredContent json.RawMessage `json:"structuredContent"`
+		IsError           bool            `json:"isError"`
+	}
+	if err := rpc(t, s, "tools/call", map[string]any{"name": name, "arguments": args}, &result); err != nil {
+		return "", err
+	}
+	if len(result.Content) != 1 {
+		t.Fatalf("%s returned %d content blocks, want 1", name, len(result.Content))
+	}
+	text := result.Content[0]
+	if text.Type != "text" {
+		t.Fatalf("%s returned %s content, want text", name, text.Type)
+	}
+	if result.IsError {
+		return "", fmt.Errorf("tool error: %s", text.Text)
+	}
+	if text.Text == "" {
+		t.Errorf("%s returned no text fallback", name)
+	}
+	if result.StructuredContent == nil {
+		t.Fatalf("%s returned no structured content", name)
+	}
+	return string(result.StructuredContent), nil
+}
+
+func listTools(t *testing.T, s *server.MCPServer) []string {
+	t.Helper()
+	var result mcp.ListToolsResult
+	if err := rpc(t, s, "tools/list", map[string]any{}, &result); err != nil {
+		t.Fatalf("tools/list: %v", err)
+	}
+	names := make([]string, 0, len(result.Tools))
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// matchJSON reports whether got contains want: objects must have every key
+// of want with a matching value, arrays must match element by element, and
+// everything else must be equal.
+func matchJSON(got, want any) bool {
+	switch want := want.(type) {
+	case map[string]any:
+		got, ok := got.(map[string]any)
+		if !ok {
+			return false
+		}
+		for k, v := range want {
+			if _, ok := got[k]; !ok || !matchJSON(got[k], v) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		got, ok := got.([]any)
+		if !ok || len(got) != len(want) {
+			return false
+		}
+		for i := range want {
+			if !matchJSON(got[i], want[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(got, want)
+	}
+}
+
+// outputSchema returns the output schema s lists for the named tool.
+func outputSchema(t *testing.T, s *server.MCPServer, name string) map[string]any {
+	t.Helper()
+	var result struct {
+		Tools []struct {
+			Name         string         `json:"name"`
+			OutputSchema map[string]any `json:"outputSchema"`
+		} `json:"tools"`
+	}
+	if err := rpc(t, s, "tools/list", map[string]any{}, &result); err != nil {
+		t.Fatalf("tools/list: %v", err)
+	}
+	for _, tool := range result.Tools {
+		if tool.Name == name {
+			return tool.OutputSchema
+		}
+	}
+	t.Fatalf("%s is not listed", name)
+	return nil
+}
+
+// checkSchema returns an error if v does not conform to schema. It supports
+// the subset of JSON Schema the output schemas are generated with; defs
+// holds the $defs of the root schema.
+func checkSchema(schema, defs map[string]any, v any, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		def, ok := defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: unresolved %s", path, ref)
+		}
+		return checkSchema(def, defs, v, path)
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		for _, sub := range anyOf {
+			if checkSchema(sub.(map[string]any), defs, v, path) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s: %v matches no schema of anyOf", path, v)
+	}
+	if types, ok := schema["type"]; ok {
+		allowed, ok := types.([]any)
+		if !ok {
+			allowed = []any{types}
+		}
+		if !slices.ContainsFunc(allowed, func(t any) bool { return jsonType(v, t.(string)) }) {
+			return fmt.Errorf("%s: %v is not %v", path, v, types)
+		}
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		required, _ := schema["required"].([]any)
+		for _, name := range required {
+			if _, ok := v[name.(string)]; !ok {
+				return fmt.Errorf("%s: missing %s", path, name)
+			}
+		}
+		props, _ := schema["properties"].(map[string]any)
+		extra, _ := schema["additionalProperties"].(map[string]any)
+		for name, value := range v {
+			sub, ok := props[name].(map[string]any)
+			if !ok {
+				sub = extra
+			}
+			if err := checkSchema(sub, defs, value, path+"."+name); err != nil {
+				return err
+			}
+		}
+	case []any:
+		items, _ := schema["items"].(map[string]any)
+		for i, value := range v {
+			if err := checkSchema(items, defs, value, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func jsonType(v any, t string) bool {
+	switch v := v.(type) {
+	case nil:
+		return t == "null"
+	case bool:
+		return t == "boolean"
+	case float64:
+		return t == "number" || t == "integer" && v == float64(int64(v))
+	case string:
+		return t == "string"
+	case []any:
+		return t == "array"
+	case map[string]any:
+		return t == "object"
+	}
+	return false
+}
+
+func TestReadOnlyHidesWriteTools(t *testing.T) {
+	fake := giteatest.NewServer(t)
+	s := newTestServer(t, fake, true)
+
+	var want []string
+	for _, info := range tool.Registered() {
+		if !info.Write {
+			want = append(want, info.Name)
+		}
+	}
+	if got := listTools(t, s); !slices.Equal(got, want) {
+		t.Errorf("tools/list in read-only mode\n got: %v\nwant: %v", got, want)
+	}
+
+	_, err := callTool(t, s, "create_issue", map[string]any{"owner": "test", "repo": "demo", "title": "t", "body": "b"})
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("calling a write tool in read-only mode: got %v, want tool not found", err)
+	}
+}
+
+func TestReadOnlyInstanceRefusesWriteTools(t *testing.T) {
+	fake := giteatest.NewServer(t)
+	fake.AddRepo("test", "demo")
+	s := newTestServer(t, fake, false)
+	gitea.SetInstances(gitea.DefaultInstanceName, map[string]*gitea.Instance{
+		gitea.DefaultInstanceName: {Name: gitea.DefaultInstanceName, Host: fake.URL, ReadOnly: true},
+	})
+
+	_, err := callTool(t, s, "create_issue", map[string]any{"owner": "test", "repo": "demo", "title": "t", "body": "b"})
+	if err == nil || !strings.Contains(err.Error(), "gitea instance default is read-only") {
+		t.Errorf("write tool on a read-only instance: got %v", err)
+	}
+	if _, err := callTool(t, s, "list_repo_issues", map[string]any{"owner": "test", "repo": "demo"}); err != nil {
+		t.Errorf("read tool on a read-only instance: %v", err)
+	}
+}
+
+func TestEveryToolIsListed(t *testing.T) {
+	fake := giteatest.NewServer(t)
+	s := newTestServer(t, fake, false)
+
+	var want []string
+	for _, info := range tool.Registered() {
+		want = append(want, info.Name)
+	}
+	if got := listTools(t, s); !slices.Equal(got, want) {
+		t.Errorf("tools/list\n got: %v\nwant: %v", got, want)
+	}
+}
+
+func TestOlderGiteaHidesNewerTools(t *testing.T) {
+	fake := giteatest.NewServer(t)
+	fake.Version = "1.20.5"
+	fake.AddRepo("test", "demo")
+	s := newTestServer(t, fake, false)
+
+	listed := listTools(t, s)
+	for _, name := range []string{"compare_refs", "get_commit"} {
+		if slices.Contains(listed, name) {
+			t.Errorf("%s is listed for Gitea 1.20", name)
+		}
+	}
+	if !slices.Contains(listed, "create_tag") {
+		t.Error("create_tag is not listed for Gitea 1.20")
+	}
+
+	_, err := callTool(t, s, "compare_refs", map[string]any{"owner": "test", "repo": "demo", "base": "main", "head": "main"})
+	if err == nil || !strings.Contains(err.Error(), "requires Gitea >= 1.22, instance default runs 1.20.5") {
+		t.Errorf("compare_refs on Gitea 1.20: got %v", err)
+	}
+	_, err = callTool(t, s, "edit_repo", map[string]any{"owner": "test", "repo": "demo", "default_merge_style": "fast-forward-only"})
+	if err == nil || !strings.Contains(err.Error(), "fast-forward-only merges requires Gitea >= 1.21") {
+		t.Errorf("edit_repo with fast-forward-only on Gitea 1.20: got %v", err)
+	}
+	if _, err := callTool(t, s, "edit_repo", map[string]any{"owner": "test", "repo": "demo", "default_merge_style": "squash"}); err != nil {
+		t.Errorf("edit_repo on Gitea 1.20: %v", err)
+	}
+}
+
+func TestErrorMapping(t *testing.T) {
+	fake := giteatest.NewServer(t)
+	fake.Token = testToken
+	fake.AddRepo("test", "demo")
+
+	t.Run("handler errors are JSON-RPC errors", func(t *testing.T) {
+		s := newTestServer(t, fake, false)
+		_, err := callTool(t, s, "get_issue_by_index", map[string]any{"owner": "test", "repo": "demo", "index": 7})
+		var rpcErr *rpcError
+		if !errorAs(err, &rpcErr) {
+			t.Fatalf("got %v, want a JSON-RPC error", err)
+		}
+		if rpcErr.Code != mcp.INTERNAL_ERROR {
+			t.Errorf("code = %d, want %d", rpcErr.Code, mcp.INTERNAL_ERROR)
+		}
+		if want := "get test/demo/issue/7 err: The target couldn't be found."; rpcErr.Message != want {
+			t.Errorf("message = %q, want %q", rpcErr.Message, want)
+		}
+	})
+
+	t.Run("rejected token", func(t *testing.T) {
+		fake.Token = "other"
+		defer func() { fake.Token = testToken }()
+		s := newTestServer(t, fake, false)
+		flag.Token = testToken
+		gitea.SetInstances(gitea.DefaultInstanceName, nil)
+
+		_, err := callTool(t, s, "get_my_user_info", nil)
+		if err == nil || !strings.Contains(err.Error(), "get user info err: invalid username, password or token") {
+			t.Errorf("got %v", err)
+		}
+	})
+
+	t.Run("unknown instance", func(t *testing.T) {
+		s := newTestServer(t, fake, false)
+		_, err := callTool(t, s, "get_my_user_info", map[string]any{"instance": "nope"})
+		if err == nil || !strings.Contains(err.Error(), "unknown gitea instance: nope") {
+			t.Errorf("got %v", err)
+		}
+	})
+}
+
+func errorAs(err error, target **rpcError) bool {
+	e, ok := err.(*rpcError)
+	if ok {
+		*target = e
+	}
+	return ok
+}
+
+func TestInfo(t *testing.T) {
+	fake := giteatest.NewServer(t)
+	newTestServer(t, fake, false)
+	newTestServer(t, fake, false)
+
+	keys := flag.APIKeys
+	flag.APIKeys = "key"
+	defer func() { flag.APIKeys = keys }()
+	srv, err := newHTTPServer(http.NotFoundHandler(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(path, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if key != "" {
+			req.Header.Set(auth.APIKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := get("/healthz", ""); rec.Code != http.StatusOK {
+		t.Errorf("/healthz without API key: status %d", rec.Code)
+	}
+	if rec := get("/info", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("/info without API key: status %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	rec := get("/info", "key")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("/info with API key: status %d", rec.Code)
+	}
+	var info struct {
+		ToolCount int `json:"tool_count"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+	// Building the server twice must not count the tools twice.
+	if want := len(tool.Registered()); info.ToolCount != want {
+		t.Errorf("tool_count = %d, want %d", info.ToolCount, want)
+	}
+}
+
+func TestOAuthDiscoveryWithoutAPIKey(t *testing.T) {
+	keys := flag.APIKeys
+	flag.APIKeys = "key"
+	defer func() { flag.APIKeys = keys }()
+	oauth, err := auth.NewOAuth(auth.OAuthOptions{
+		Issuer:       "https://gitea.example.com",
+		ClientID:     "mcp-client",
+		ClientSecret: "mcp-secret",
+		ResourceURL:  "https://mcp.example.com/mcp",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, err := newHTTPServer(http.NotFoundHandler(), func(mux *http.ServeMux) {
+		oauth.RegisterMetadata(mux, httpEndpointPath)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]int{
+		"/.well-known/oauth-protected-resource":     http.StatusOK,
+		"/.well-known/oauth-protected-resource/mcp": http.StatusOK,
+		httpEndpointPath: http.StatusUnauthorized,
+	} {
+		rec := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Errorf("GET %s without API key: status %d, want %d", path, rec.Code, want)
+		}
+	}
+}
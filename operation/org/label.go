@@ -0,0 +1,212 @@
+package org
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"gitea.com/gitea/gitea-mcp/pkg/gitea"
+	"gitea.com/gitea/gitea-mcp/pkg/log"
+	"gitea.com/gitea/gitea-mcp/pkg/params"
+	"gitea.com/gitea/gitea-mcp/pkg/ptr"
+	"gitea.com/gitea/gitea-mcp/pkg/to"
+	"gitea.com/gitea/gitea-mcp/pkg/tool"
+
+	gitea_sdk "code.gitea.io/sdk/gitea"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	ListOrgLabelsToolName  = "list_org_labels"
+	GetOrgLabelToolName    = "get_org_label"
+	CreateOrgLabelToolName = "create_org_label"
+	EditOrgLabelToolName   = "edit_org_label"
+	DeleteOrgLabelToolName = "delete_org_label"
+
+	// orgLabelsVersion is the release that added organization labels.
+	orgLabelsVersion = "1.12"
+)
+
+var (
+	ListOrgLabelsTool = mcp.NewTool(
+		ListOrgLabelsToolName,
+		mcp.WithDescription("Lists the labels of an organization, which its repositories can use"),
+		mcp.WithString("org", mcp.Required(), mcp.Description("organization name")),
+		mcp.WithNumber("page", mcp.Description("page number"), mcp.DefaultNumber(1), mcp.Min(1)),
+		mcp.WithNumber("page_size", mcp.Description("page size"), mcp.DefaultNumber(50), mcp.Min(1)),
+		to.OutputSchema[[]*gitea_sdk.Label](),
+	)
+
+	GetOrgLabelTool = mcp.NewTool(
+		GetOrgLabelToolName,
+		mcp.WithDescription("Gets a single label of an organization by its ID"),
+		mcp.WithString("org", mcp.Required(), mcp.Description("organization name")),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description("label ID")),
+		to.OutputSchema[*gitea_sdk.Label](),
+	)
+
+	CreateOrgLabelTool = mcp.NewTool(
+		CreateOrgLabelToolName,
+		mcp.WithDescription("Creates a label for an organization"),
+		mcp.WithString("org", mcp.Required(), mcp.Description("organization name")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("label name")),
+		mcp.WithString("color", mcp.Required(), mcp.Description("label color (hex code, e.g., #RRGGBB)")),
+		mcp.WithString("description", mcp.Description("label description")),
+		to.OutputSchema[*gitea_sdk.Label](),
+	)
+
+	EditOrgLabelTool = mcp.NewTool(
+		EditOrgLabelToolName,
+		mcp.WithDescription("Edits a label of an organization"),
+		mcp.WithString("org", mcp.Required(), mcp.Description("organization name")),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description("label ID")),
+		mcp.WithString("name", mcp.Description("new label name")),
+		mcp.WithString("color", mcp.Description("new label color (hex code, e.g., #RRGGBB)")),
+		mcp.WithString("description", mcp.Description("new label description")),
+		to.OutputSchema[*gitea_sdk.Label](),
+	)
+
+	DeleteOrgLabelTool = mcp.NewTool(
+		DeleteOrgLabelToolName,
+		mcp.WithDescription("Deletes a label of an organization"),
+		mcp.WithString("org", mcp.Required(), mcp.Description("organization name")),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description("label ID")),
+		to.OutputSchema[string](),
+	)
+)
+
+func init() {
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    ListOrgLabelsTool,
+		Handler: ListOrgLabelsFn,
+	}, tool.MinVersion(orgLabelsVersion))
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    GetOrgLabelTool,
+		Handler: GetOrgLabelFn,
+	}, tool.MinVersion(orgLabelsVersion))
+	Tool.RegisterWrite(server.ServerTool{
+		Tool:    CreateOrgLabelTool,
+		Handler: CreateOrgLabelFn,
+	}, tool.MinVersion(orgLabelsVersion))
+	Tool.RegisterWrite(server.ServerTool{
+		Tool:    EditOrgLabelTool,
+		Handler: EditOrgLabelFn,
+	}, tool.MinVersion(orgLabelsVersion))
+	Tool.RegisterWrite(server.ServerTool{
+		Tool:    DeleteOrgLabelTool,
+		Handler: DeleteOrgLabelFn,
+	}, tool.MinVersion(orgLabelsVersion))
+}
+
+// The SDK has no calls for organization labels, so these tools use the API
+// directly.
+
+func ListOrgLabelsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListOrgLabelsFn")
+	org, ok := req.GetArguments()["org"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("org is required"))
+	}
+	opt := params.ListOptions(req)
+	labels := []*gitea_sdk.Label{}
+	path := fmt.Sprintf("/orgs/%s/labels?page=%d&limit=%d", url.PathEscape(org), opt.Page, opt.PageSize)
+	if err := gitea.Do(ctx, http.MethodGet, path, nil, &labels); err != nil {
+		return to.ErrorResult(fmt.Errorf("list %v/labels err: %v", org, err))
+	}
+	return to.Result(labels)
+}
+
+func GetOrgLabelFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called GetOrgLabelFn")
+	org, ok := req.GetArguments()["org"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("org is required"))
+	}
+	id, ok := req.GetArguments()["id"].(float64)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("label ID is required"))
+	}
+	var label gitea_sdk.Label
+	path := fmt.Sprintf("/orgs/%s/labels/%d", url.PathEscape(org), int64(id))
+	if err := gitea.Do(ctx, http.MethodGet, path, nil, &label); err != nil {
+		return to.ErrorResult(fmt.Errorf("get %v/label/%v err: %v", org, int64(id), err))
+	}
+	return to.Result(label)
+}
+
+func CreateOrgLabelFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called CreateOrgLabelFn")
+	org, ok := req.GetArguments()["org"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("org is required"))
+	}
+	name, ok := req.GetArguments()["name"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("name is required"))
+	}
+	color, ok := req.GetArguments()["color"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("color is required"))
+	}
+	description, _ := req.GetArguments()["description"].(string)
+
+	opt := gitea_sdk.CreateLabelOption{
+		Name:        name,
+		Color:       color,
+		Description: description,
+	}
+	var label gitea_sdk.Label
+	path := fmt.Sprintf("/orgs/%s/labels", url.PathEscape(org))
+	if err := gitea.Do(ctx, http.MethodPost, path, opt, &label); err != nil {
+		return to.ErrorResult(fmt.Errorf("create %v/label err: %v", org, err))
+	}
+	return to.Result(label)
+}
+
+func EditOrgLabelFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called EditOrgLabelFn")
+	org, ok := req.GetArguments()["org"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("org is required"))
+	}
+	id, ok := req.GetArguments()["id"].(float64)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("label ID is required"))
+	}
+
+	opt := gitea_sdk.EditLabelOption{}
+	if name, ok := req.GetArguments()["name"].(string); ok {
+		opt.Name = ptr.To(name)
+	}
+	if color, ok := req.GetArguments()["color"].(string); ok {
+		opt.Color = ptr.To(color)
+	}
+	if description, ok := req.GetArguments()["description"].(string); ok {
+		opt.Description = ptr.To(description)
+	}
+	var label gitea_sdk.Label
+	path := fmt.Sprintf("/orgs/%s/labels/%d", url.PathEscape(org), int64(id))
+	if err := gitea.Do(ctx, http.MethodPatch, path, opt, &label); err != nil {
+		return to.ErrorResult(fmt.Errorf("edit %v/label/%v err: %v", org, int64(id), err))
+	}
+	return to.Result(label)
+}
+
+func DeleteOrgLabelFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called DeleteOrgLabelFn")
+	org, ok := req.GetArguments()["org"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("org is required"))
+	}
+	id, ok := req.GetArguments()["id"].(float64)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("label ID is required"))
+	}
+	path := fmt.Sprintf("/orgs/%s/labels/%d", url.PathEscape(org), int64(id))
+	if err := gitea.Do(ctx, http.MethodDelete, path, nil, nil); err != nil {
+		return to.ErrorResult(fmt.Errorf("delete %v/label/%v err: %v", org, int64(id), err))
+	}
+	return to.Result("Label deleted successfully")
+}
@@ -0,0 +1,364 @@
+package org
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gitea.com/gitea/gitea-mcp/pkg/gitea"
+	"gitea.com/gitea/gitea-mcp/pkg/log"
+	"gitea.com/gitea/gitea-mcp/pkg/params"
+	"gitea.com/gitea/gitea-mcp/pkg/to"
+	"gitea.com/gitea/gitea-mcp/pkg/tool"
+
+	gitea_sdk "code.gitea.io/sdk/gitea"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+var Tool = tool.New(tool.Scope(gitea.ScopeOrganization))
+
+const (
+	GetOrgToolName               = "get_org"
+	ListOrgReposToolName         = "list_org_repos"
+	ListOrgMembersToolName       = "list_org_members"
+	ListOrgPublicMembersToolName = "list_org_public_members"
+	CheckOrgMembershipToolName   = "check_org_membership"
+	CreateOrgToolName            = "create_org"
+	EditOrgToolName              = "edit_org"
+)
+
+const (
+	// orgReposPageSize and maxOrgRepoPages bound the repositories
+	// list_org_repos reads to filter or sort them.
+	orgReposPageSize = 50
+	maxOrgRepoPages  = 20
+)
+
+var (
+	GetOrgTool = mcp.NewTool(
+		GetOrgToolName,
+		mcp.WithDescription("Get the details of an organization"),
+		mcp.WithString("org", mcp.Required(), mcp.Description("organization name")),
+		to.OutputSchema[*gitea_sdk.Organization](),
+	)
+
+	ListOrgReposTool = mcp.NewTool(
+		ListOrgReposToolName,
+		mcp.WithDescription("List the repositories of an organization, optionally filtered"),
+		mcp.WithString("org", mcp.Required(), mcp.Description("organization name")),
+		mcp.WithString("keyword", mcp.Description("only list repositories whose name contains keyword")),
+		mcp.WithBoolean("is_private", mcp.Description("only list private, or only public, repositories")),
+		mcp.WithBoolean("is_archived", mcp.Description("only list archived, or only active, repositories")),
+		mcp.WithString("sort", mcp.Description("sort order"), mcp.Enum("alpha", "created", "updated", "size", "id")),
+		mcp.WithString("order", mcp.Description("sort direction"), mcp.Enum("asc", "desc")),
+		mcp.WithNumber("page", mcp.Description("page number"), mcp.DefaultNumber(1), mcp.Min(1)),
+		mcp.WithNumber("page_size", mcp.Description("page size"), mcp.DefaultNumber(50), mcp.Min(1)),
+		to.OutputSchema[[]*gitea_sdk.Repository](),
+	)
+
+	ListOrgMembersTool = mcp.NewTool(
+		ListOrgMembersToolName,
+		mcp.WithDescription("List the members of an organization"),
+		mcp.WithString("org", mcp.Required(), mcp.Description("organization name")),
+		mcp.WithNumber("page", mcp.Description("page number"), mcp.DefaultNumber(1), mcp.Min(1)),
+		mcp.WithNumber("page_size", mcp.Description("page size"), mcp.DefaultNumber(50), mcp.Min(1)),
+		to.OutputSchema[[]*gitea_sdk.User](),
+	)
+
+	ListOrgPublicMembersTool = mcp.NewTool(
+		ListOrgPublicMembersToolName,
+		mcp.WithDescription("List the members of an organization who made their membership public"),
+		mcp.WithString("org", mcp.Required(), mcp.Description("organization name")),
+		mcp.WithNumber("page", mcp.Description("page number"), mcp.DefaultNumber(1), mcp.Min(1)),
+		mcp.WithNumber("page_size", mcp.Description("page size"), mcp.DefaultNumber(50), mcp.Min(1)),
+		to.OutputSchema[[]*gitea_sdk.User](),
+	)
+
+	CheckOrgMembershipTool = mcp.NewTool(
+		CheckOrgMembershipToolName,
+		mcp.WithDescription("Check whether a user is a member of an organization, and whether publicly"),
+		mcp.WithString("org", mcp.Required(), mcp.Description("organization name")),
+		mcp.WithString("username", mcp.Required(), mcp.Description("user to check")),
+		to.OutputSchema[Membership](),
+	)
+
+	CreateOrgTool = mcp.NewTool(
+		CreateOrgToolName,
+		mcp.WithDescription("Create an organization owned by the authenticated user"),
+		mcp.WithString("name", mcp.Required(), mcp.Description("organization name")),
+		mcp.WithString("full_name", mcp.Description("display name")),
+		mcp.WithString("description", mcp.Description("description of the organization")),
+		mcp.WithString("website", mcp.Description("website of the organization")),
+		mcp.WithString("location", mcp.Description("location of the organization")),
+		mcp.WithString("visibility", mcp.Description("who can see the organization"), mcp.Enum("public", "limited", "private"), mcp.DefaultString("public")),
+		mcp.WithBoolean("repo_admin_change_team_access", mcp.Description("allow repository admins to change the access of teams")),
+		to.OutputSchema[*gitea_sdk.Organization](),
+	)
+
+	EditOrgTool = mcp.NewTool(
+		EditOrgToolName,
+		mcp.WithDescription("Edit an organization, only the given settings are changed"),
+		mcp.WithString("org", mcp.Required(), mcp.Description("organization name")),
+		mcp.WithString("full_name", mcp.Description("display name")),
+		mcp.WithString("description", mcp.Description("description of the organization")),
+		mcp.WithString("website", mcp.Description("website of the organization")),
+		mcp.WithString("location", mcp.Description("location of the organization")),
+		mcp.WithString("visibility", mcp.Description("who can see the organization"), mcp.Enum("public", "limited", "private")),
+		to.OutputSchema[*gitea_sdk.Organization](),
+	)
+)
+
+func init() {
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    GetOrgTool,
+		Handler: GetOrgFn,
+	})
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    ListOrgReposTool,
+		Handler: ListOrgReposFn,
+	})
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    ListOrgMembersTool,
+		Handler: ListOrgMembersFn,
+	})
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    ListOrgPublicMembersTool,
+		Handler: ListOrgPublicMembersFn,
+	})
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    CheckOrgMembershipTool,
+		Handler: CheckOrgMembershipFn,
+	})
+	Tool.RegisterWrite(server.ServerTool{
+		Tool:    CreateOrgTool,
+		Handler: CreateOrgFn,
+	})
+	Tool.RegisterWrite(server.ServerTool{
+		Tool:    EditOrgTool,
+		Handler: EditOrgFn,
+	})
+}
+
+// Membership is the result of check_org_membership.
+type Membership struct {
+	Member bool `json:"member"`
+	Public bool `json:"public"`
+}
+
+func GetOrgFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called GetOrgFn")
+	org, ok := req.GetArguments()["org"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("org is required"))
+	}
+	organization, _, err := gitea.ClientFromContext(ctx).GetOrg(org)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get org %v err: %v", org, err))
+	}
+	return to.Result(organization)
+}
+
+// ListOrgReposFn is the handler for "list_org_repos" MCP tool requests.
+// Gitea's endpoint for the repositories of an organization cannot filter,
+// so it searches the repositories the organization owns instead.
+func ListOrgReposFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListOrgReposFn")
+	org, ok := req.GetArguments()["org"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("org is required"))
+	}
+	keyword, _ := req.GetArguments()["keyword"].(string)
+	sort, _ := req.GetArguments()["sort"].(string)
+	order, _ := req.GetArguments()["order"].(string)
+	isPrivate, filterPrivate := req.GetArguments()["is_private"].(bool)
+	isArchived, filterArchived := req.GetArguments()["is_archived"].(bool)
+	list := params.ListOptions(req)
+	client := gitea.ClientFromContext(ctx)
+	if keyword == "" && sort == "" && !filterPrivate && !filterArchived {
+		repos, _, err := client.ListOrgRepos(org, gitea_sdk.ListOrgReposOptions{ListOptions: list})
+		if err != nil {
+			return to.ErrorResult(fmt.Errorf("list repos of %v err: %v", org, err))
+		}
+		return to.Result(repos)
+	}
+
+	// Gitea lists the repositories of an organization unfiltered, so they are
+	// read in full and filtered, sorted and paged here.
+	repos := []*gitea_sdk.Repository{}
+	for page := 1; ; page++ {
+		if page > maxOrgRepoPages {
+			return to.ErrorResult(fmt.Errorf("%v has more than %d repositories to filter, use search_repos with its owner_id instead", org, maxOrgRepoPages*orgReposPageSize))
+		}
+		batch, _, err := client.ListOrgRepos(org, gitea_sdk.ListOrgReposOptions{
+			ListOptions: gitea_sdk.ListOptions{Page: page, PageSize: orgReposPageSize},
+		})
+		if err != nil {
+			return to.ErrorResult(fmt.Errorf("list repos of %v err: %v", org, err))
+		}
+		for _, r := range batch {
+			switch {
+			case !strings.Contains(strings.ToLower(r.Name), strings.ToLower(keyword)):
+			case filterPrivate && r.Private != isPrivate:
+			case filterArchived && r.Archived != isArchived:
+			default:
+				repos = append(repos, r)
+			}
+		}
+		if len(batch) < orgReposPageSize {
+			break
+		}
+	}
+	sortRepos(repos, sort, order)
+	start := min(max(list.Page-1, 0)*max(list.PageSize, 1), len(repos))
+	end := min(start+max(list.PageSize, 1), len(repos))
+	return to.Result(repos[start:end])
+}
+
+// sortRepos sorts repos like Gitea's repository search: by name unless sort
+// is "created", "updated", "size" or "id", ascending unless order is "desc".
+func sortRepos(repos []*gitea_sdk.Repository, sort, order string) {
+	slices.SortStableFunc(repos, func(a, b *gitea_sdk.Repository) int {
+		var c int
+		switch sort {
+		case "created":
+			c = a.Created.Compare(b.Created)
+		case "updated":
+			c = a.Updated.Compare(b.Updated)
+		case "size":
+			c = cmp.Compare(a.Size, b.Size)
+		case "id":
+			c = cmp.Compare(a.ID, b.ID)
+		default:
+			c = strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		}
+		if order == "desc" {
+			return -c
+		}
+		return c
+	})
+}
+
+func ListOrgMembersFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListOrgMembersFn")
+	org, ok := req.GetArguments()["org"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("org is required"))
+	}
+	members, _, err := gitea.ClientFromContext(ctx).ListOrgMembership(org, gitea_sdk.ListOrgMembershipOption{
+		ListOptions: params.ListOptions(req),
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list members of %v err: %v", org, err))
+	}
+	return to.Result(members)
+}
+
+func ListOrgPublicMembersFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListOrgPublicMembersFn")
+	org, ok := req.GetArguments()["org"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("org is required"))
+	}
+	members, _, err := gitea.ClientFromContext(ctx).ListPublicOrgMembership(org, gitea_sdk.ListOrgMembershipOption{
+		ListOptions: params.ListOptions(req),
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list public members of %v err: %v", org, err))
+	}
+	return to.Result(members)
+}
+
+func CheckOrgMembershipFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called CheckOrgMembershipFn")
+	org, ok := req.GetArguments()["org"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("org is required"))
+	}
+	username, ok := req.GetArguments()["username"].(string)
+	if !ok || username == "" {
+		return to.ErrorResult(fmt.Errorf("username is required"))
+	}
+	client := gitea.ClientFromContext(ctx)
+	var membership Membership
+	var err error
+	membership.Member, _, err = client.CheckOrgMembership(org, username)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("check membership of %v in %v err: %v", username, org, err))
+	}
+	membership.Public, _, err = client.CheckPublicOrgMembership(org, username)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("check public membership of %v in %v err: %v", username, org, err))
+	}
+	return to.Result(membership)
+}
+
+func CreateOrgFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called CreateOrgFn")
+	name, ok := req.GetArguments()["name"].(string)
+	if !ok || name == "" {
+		return to.ErrorResult(fmt.Errorf("name is required"))
+	}
+	fullName, _ := req.GetArguments()["full_name"].(string)
+	description, _ := req.GetArguments()["description"].(string)
+	website, _ := req.GetArguments()["website"].(string)
+	location, _ := req.GetArguments()["location"].(string)
+	visibility, ok := req.GetArguments()["visibility"].(string)
+	if !ok {
+		visibility = string(gitea_sdk.VisibleTypePublic)
+	}
+	repoAdminChangeTeamAccess, _ := req.GetArguments()["repo_admin_change_team_access"].(bool)
+
+	organization, _, err := gitea.ClientFromContext(ctx).CreateOrg(gitea_sdk.CreateOrgOption{
+		Name:                      name,
+		FullName:                  fullName,
+		Description:               description,
+		Website:                   website,
+		Location:                  location,
+		Visibility:                gitea_sdk.VisibleType(visibility),
+		RepoAdminChangeTeamAccess: repoAdminChangeTeamAccess,
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("create org %v err: %v", name, err))
+	}
+	return to.Result(organization)
+}
+
+// EditOrgFn is the handler for "edit_org" MCP tool requests. Gitea replaces
+// every text field of an organization on edit, so the settings not given
+// are sent as they are.
+func EditOrgFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called EditOrgFn")
+	org, ok := req.GetArguments()["org"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("org is required"))
+	}
+	client := gitea.ClientFromContext(ctx)
+	organization, _, err := client.GetOrg(org)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get org %v err: %v", org, err))
+	}
+	stringArg := func(name, current string) string {
+		if v, ok := req.GetArguments()[name].(string); ok {
+			return v
+		}
+		return current
+	}
+	opt := gitea_sdk.EditOrgOption{
+		FullName:    stringArg("full_name", organization.FullName),
+		Description: stringArg("description", organization.Description),
+		Website:     stringArg("website", organization.Website),
+		Location:    stringArg("location", organization.Location),
+		Visibility:  gitea_sdk.VisibleType(stringArg("visibility", organization.Visibility)),
+	}
+	if _, err := client.EditOrg(org, opt); err != nil {
+		return to.ErrorResult(fmt.Errorf("edit org %v err: %v", org, err))
+	}
+	organization, _, err = client.GetOrg(org)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get org %v err: %v", org, err))
+	}
+	return to.Result(organization)
+}
@@ -14,7 +14,7 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 )
 
-var Tool = tool.New()
+var Tool = tool.New(tool.Scope(gitea.ScopeRepository))
 
 const (
 	GetPullRequestByIndexToolName = "get_pull_request_by_index"
@@ -29,6 +29,7 @@ var (
 		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
 		mcp.WithNumber("index", mcp.Required(), mcp.Description("repository pull request index")),
+		to.OutputSchema[*gitea_sdk.PullRequest](),
 	)
 
 	ListRepoPullRequestsTool = mcp.NewTool(
@@ -40,7 +41,8 @@ var (
 		mcp.WithString("sort", mcp.Description("sort"), mcp.Enum("oldest", "recentupdate", "leastupdate", "mostcomment", "leastcomment", "priority"), mcp.DefaultString("recentupdate")),
 		mcp.WithNumber("milestone", mcp.Description("milestone")),
 		mcp.WithNumber("page", mcp.Description("page number"), mcp.DefaultNumber(1)),
-		mcp.WithNumber("pageSize", mcp.Description("page size"), mcp.DefaultNumber(100)),
+		mcp.WithNumber("page_size", mcp.Description("page size"), mcp.DefaultNumber(100)),
+		to.OutputSchema[[]*gitea_sdk.PullRequest](),
 	)
 
 	CreatePullRequestTool = mcp.NewTool(
@@ -52,6 +54,7 @@ var (
 		mcp.WithString("body", mcp.Required(), mcp.Description("pull request body")),
 		mcp.WithString("head", mcp.Required(), mcp.Description("pull request head")),
 		mcp.WithString("base", mcp.Required(), mcp.Description("pull request base")),
+		to.OutputSchema[*gitea_sdk.PullRequest](),
 	)
 )
 
@@ -63,7 +66,7 @@ func init() {
 	Tool.RegisterRead(server.ServerTool{
 		Tool:    ListRepoPullRequestsTool,
 		Handler: ListRepoPullRequestsFn,
-	})
+	}, tool.Alias("pageSize", "page_size"))
 	Tool.RegisterWrite(server.ServerTool{
 		Tool:    CreatePullRequestTool,
 		Handler: CreatePullRequestFn,
@@ -84,12 +87,24 @@ func GetPullRequestByIndexFn(ctx context.Context, req mcp.CallToolRequest) (*mcp
 	if !ok {
 		return to.ErrorResult(fmt.Errorf("index is required"))
 	}
-	pr, _, err := gitea.Client().GetPullRequest(owner, repo, int64(index))
+	pr, _, err := gitea.ClientFromContext(ctx).GetPullRequest(owner, repo, int64(index))
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("get %v/%v/pr/%v err: %v", owner, repo, int64(index), err))
 	}
 
-	return to.TextResult(pr)
+	return to.Result(pr)
+}
+
+// HeadSHA returns the SHA of the head commit of pull request index.
+func HeadSHA(ctx context.Context, owner, repo string, index int64) (string, error) {
+	pr, _, err := gitea.ClientFromContext(ctx).GetPullRequest(owner, repo, index)
+	if err != nil {
+		return "", fmt.Errorf("get %v/%v/pr/%v err: %v", owner, repo, index, err)
+	}
+	if pr.Head == nil || pr.Head.Sha == "" {
+		return "", fmt.Errorf("%v/%v/pr/%v has no head commit", owner, repo, index)
+	}
+	return pr.Head.Sha, nil
 }
 
 func ListRepoPullRequestsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -112,7 +127,7 @@ func ListRepoPullRequestsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.
 	if !ok {
 		page = 1
 	}
-	pageSize, ok := req.GetArguments()["pageSize"].(float64)
+	pageSize, ok := req.GetArguments()["page_size"].(float64)
 	if !ok {
 		pageSize = 100
 	}
@@ -125,12 +140,12 @@ func ListRepoPullRequestsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.
 			PageSize: int(pageSize),
 		},
 	}
-	pullRequests, _, err := gitea.Client().ListRepoPullRequests(owner, repo, opt)
+	pullRequests, _, err := gitea.ClientFromContext(ctx).ListRepoPullRequests(owner, repo, opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("list %v/%v/pull_requests err: %v", owner, repo, err))
 	}
 
-	return to.TextResult(pullRequests)
+	return to.Result(pullRequests)
 }
 
 func CreatePullRequestFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -159,7 +174,7 @@ func CreatePullRequestFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.Cal
 	if !ok {
 		return to.ErrorResult(fmt.Errorf("base is required"))
 	}
-	pr, _, err := gitea.Client().CreatePullRequest(owner, repo, gitea_sdk.CreatePullRequestOption{
+	pr, _, err := gitea.ClientFromContext(ctx).CreatePullRequest(owner, repo, gitea_sdk.CreatePullRequestOption{
 		Title: title,
 		Body:  body,
 		Head:  head,
@@ -169,5 +184,5 @@ func CreatePullRequestFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.Cal
 		return to.ErrorResult(fmt.Errorf("create %v/%v/pull_request err: %v", owner, repo, err))
 	}
 
-	return to.TextResult(pr)
+	return to.Result(pr)
 }
@@ -0,0 +1,234 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"gitea.com/gitea/gitea-mcp/pkg/diff"
+	"gitea.com/gitea/gitea-mcp/pkg/gitea"
+	"gitea.com/gitea/gitea-mcp/pkg/log"
+	"gitea.com/gitea/gitea-mcp/pkg/to"
+
+	gitea_sdk "code.gitea.io/sdk/gitea"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const GetFileBlameToolName = "get_file_blame"
+
+const (
+	// maxBlameCommits is the number of commits of the file read at most.
+	maxBlameCommits = 100
+	blamePageSize   = 50
+	// blameWorkers is the number of versions of the file fetched
+	// concurrently.
+	blameWorkers = 8
+)
+
+var GetFileBlameTool = mcp.NewTool(
+	GetFileBlameToolName,
+	mcp.WithDescription("Show which commit last changed each line of a file, optionally for a line range"),
+	mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
+	mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+	mcp.WithString("path", mcp.Required(), mcp.Description("file path")),
+	mcp.WithString("ref", mcp.Description("branch, tag or commit, defaults to the default branch")),
+	mcp.WithNumber("start_line", mcp.Description("first line to blame"), mcp.Min(1)),
+	mcp.WithNumber("end_line", mcp.Description("last line to blame"), mcp.Min(1)),
+	to.OutputSchema[FileBlame](),
+)
+
+func init() {
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    GetFileBlameTool,
+		Handler: GetFileBlameFn,
+	})
+}
+
+// FileBlame is the result of get_file_blame.
+type FileBlame struct {
+	Path  string      `json:"path"`
+	Hunks []BlameHunk `json:"hunks"`
+	// Truncated is set if the history of the file is longer than was read.
+	// The lines left are then blamed on the oldest commit read.
+	Truncated bool `json:"truncated"`
+}
+
+// BlameHunk is a run of lines last changed by the same commit.
+type BlameHunk struct {
+	StartLine int      `json:"start_line"`
+	EndLine   int      `json:"end_line"`
+	Commit    string   `json:"commit"`
+	Author    string   `json:"author"`
+	Email     string   `json:"email"`
+	Date      string   `json:"date"`
+	Summary   string   `json:"summary"`
+	Lines     []string `json:"lines"`
+}
+
+// GetFileBlameFn is the handler for "get_file_blame" MCP tool requests.
+// Gitea's API has no blame endpoint, so the blame is derived from the
+// commits of the path: each version of the file is compared with the one
+// before, and the lines it adds are blamed on its commit. The commits are
+// taken in the order Gitea lists them rather than by their parents, so
+// across merges the attribution is approximate: lines from a merged branch
+// may be blamed on a commit of the other branch.
+func GetFileBlameFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called GetFileBlameFn")
+	owner, ok := req.GetArguments()["owner"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("owner is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("repo is required"))
+	}
+	filePath, ok := req.GetArguments()["path"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("path is required"))
+	}
+	ref, _ := req.GetArguments()["ref"].(string)
+	startLine, hasStart := req.GetArguments()["start_line"].(float64)
+	endLine, hasEnd := req.GetArguments()["end_line"].(float64)
+	if hasStart && hasEnd && endLine < startLine {
+		return to.ErrorResult(fmt.Errorf("end_line %d is before start_line %d", int(endLine), int(startLine)))
+	}
+
+	client := gitea.ClientFromContext(ctx)
+	var commits []*gitea_sdk.Commit
+	// One commit more than is read shows whether the history is longer.
+	for page := 1; len(commits) <= maxBlameCommits; page++ {
+		list, _, err := client.ListRepoCommits(owner, repo, gitea_sdk.ListCommitOptions{
+			ListOptions: gitea_sdk.ListOptions{Page: page, PageSize: blamePageSize},
+			SHA:         ref,
+			Path:        filePath,
+		})
+		if err != nil {
+			return to.ErrorResult(fmt.Errorf("list commits of %v err: %v", filePath, err))
+		}
+		commits = append(commits, list...)
+		if len(list) < blamePageSize {
+			break
+		}
+	}
+	if len(commits) == 0 {
+		return to.ErrorResult(fmt.Errorf("no commits of %v found", filePath))
+	}
+	truncated := len(commits) > maxBlameCommits
+	commits = commits[:min(len(commits), maxBlameCommits)]
+
+	// versions holds the file at the commits read so far. They are fetched
+	// a batch at a time, as the blame often ends long before the history.
+	versions, err := filesAt(client, owner, repo, filePath, commits[:min(len(commits), blameWorkers)])
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	content := versions[0]
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if content == "" {
+		lines = nil
+	}
+	first, last := 1, len(lines)
+	if hasStart {
+		first = max(int(startLine), 1)
+	}
+	if hasEnd {
+		last = min(int(endLine), last)
+	}
+
+	// pos maps the lines still to blame to their index in the version of
+	// the file at commits[i].
+	pos := make(map[int]int)
+	for n := first; n <= last; n++ {
+		pos[n] = n - 1
+	}
+	blame := make(map[int]*gitea_sdk.Commit)
+	result := FileBlame{Path: filePath, Hunks: []BlameHunk{}}
+	for i, commit := range commits {
+		if len(pos) == 0 {
+			break
+		}
+		if i == len(commits)-1 {
+			// The file was added here, or its history was cut short.
+			result.Truncated = truncated
+			for n := range pos {
+				blame[n] = commit
+			}
+			break
+		}
+		if i+1 == len(versions) {
+			batch, err := filesAt(client, owner, repo, filePath, commits[i+1:min(len(commits), i+1+blameWorkers)])
+			if err != nil {
+				return to.ErrorResult(err)
+			}
+			versions = append(versions, batch...)
+		}
+		parent := versions[i+1]
+		matches := diff.Matches(content, parent)
+		for n, p := range pos {
+			if matches[p] < 0 {
+				blame[n] = commit
+				delete(pos, n)
+			} else {
+				pos[n] = matches[p]
+			}
+		}
+		content = parent
+	}
+
+	for n := first; n <= last; n++ {
+		c := blame[n]
+		if h := len(result.Hunks) - 1; h >= 0 && result.Hunks[h].Commit == c.SHA {
+			result.Hunks[h].EndLine = n
+			result.Hunks[h].Lines = append(result.Hunks[h].Lines, lines[n-1])
+			continue
+		}
+		hunk := BlameHunk{StartLine: n, EndLine: n, Commit: c.SHA, Lines: []string{lines[n-1]}}
+		if c.RepoCommit != nil {
+			hunk.Summary, _, _ = strings.Cut(c.RepoCommit.Message, "\n")
+			if c.RepoCommit.Author != nil {
+				hunk.Author = c.RepoCommit.Author.Name
+				hunk.Email = c.RepoCommit.Author.Email
+				hunk.Date = c.RepoCommit.Author.Date
+			}
+		}
+		result.Hunks = append(result.Hunks, hunk)
+	}
+	return to.Result(result)
+}
+
+// filesAt returns the content of p at each of commits, fetched concurrently.
+func filesAt(client *gitea_sdk.Client, owner, repo, p string, commits []*gitea_sdk.Commit) ([]string, error) {
+	contents := make([]string, len(commits))
+	errs := make([]error, len(commits))
+	var wg sync.WaitGroup
+	for i, commit := range commits {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			contents[i], errs[i] = fileAt(client, owner, repo, commit.SHA, p)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return contents, nil
+}
+
+// fileAt returns the content of p at commit sha, or "" if the commit deleted
+// it.
+func fileAt(client *gitea_sdk.Client, owner, repo, sha, p string) (string, error) {
+	data, resp, err := client.GetFile(owner, repo, sha, p)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return "", nil
+		}
+		return "", fmt.Errorf("get %v at %v err: %v", p, sha, err)
+	}
+	return string(data), nil
+}
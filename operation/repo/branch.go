@@ -7,6 +7,7 @@ import (
 	"gitea.com/gitea/gitea-mcp/pkg/gitea"
 	"gitea.com/gitea/gitea-mcp/pkg/log"
 	"gitea.com/gitea/gitea-mcp/pkg/to"
+	"gitea.com/gitea/gitea-mcp/pkg/tool"
 
 	gitea_sdk "code.gitea.io/sdk/gitea"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -27,6 +28,7 @@ var (
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
 		mcp.WithString("branch", mcp.Required(), mcp.Description("Name of the branch to create")),
 		mcp.WithString("old_branch", mcp.Required(), mcp.Description("Name of the old branch to create from")),
+		to.OutputSchema[string](),
 	)
 
 	DeleteBranchTool = mcp.NewTool(
@@ -35,13 +37,15 @@ var (
 		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
 		mcp.WithString("branch", mcp.Required(), mcp.Description("Name of the branch to delete")),
+		to.OutputSchema[string](),
 	)
 
 	ListBranchesTool = mcp.NewTool(
 		ListBranchesToolName,
-		mcp.WithDescription("List branches"),
+		mcp.WithDescription("List branches, with whether each is protected, by which rule, and whether you can push to it"),
 		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+		to.OutputSchema[[]*gitea_sdk.Branch](),
 	)
 )
 
@@ -49,11 +53,11 @@ func init() {
 	Tool.RegisterWrite(server.ServerTool{
 		Tool:    CreateBranchTool,
 		Handler: CreateBranchFn,
-	})
+	}, tool.MinVersion("1.13"))
 	Tool.RegisterWrite(server.ServerTool{
 		Tool:    DeleteBranchTool,
 		Handler: DeleteBranchFn,
-	})
+	}, tool.MinVersion("1.12"))
 	Tool.RegisterRead(server.ServerTool{
 		Tool:    ListBranchesTool,
 		Handler: ListBranchesFn,
@@ -76,7 +80,7 @@ func CreateBranchFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTool
 	}
 	oldBranch, _ := req.GetArguments()["old_branch"].(string)
 
-	_, _, err := gitea.Client().CreateBranch(owner, repo, gitea_sdk.CreateBranchOption{
+	_, _, err := gitea.ClientFromContext(ctx).CreateBranch(owner, repo, gitea_sdk.CreateBranchOption{
 		BranchName:    branch,
 		OldBranchName: oldBranch,
 	})
@@ -84,7 +88,7 @@ func CreateBranchFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTool
 		return to.ErrorResult(fmt.Errorf("create branch error: %v", err))
 	}
 
-	return mcp.NewToolResultText("Branch Created"), nil
+	return to.Result("Branch Created")
 }
 
 func DeleteBranchFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -101,12 +105,16 @@ func DeleteBranchFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTool
 	if !ok {
 		return to.ErrorResult(fmt.Errorf("branch is required"))
 	}
-	_, _, err := gitea.Client().DeleteRepoBranch(owner, repo, branch)
+	deleted, resp, err := gitea.ClientFromContext(ctx).DeleteRepoBranch(owner, repo, branch)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("delete branch error: %v", err))
 	}
+	// The SDK does not turn a refusal into an error.
+	if !deleted {
+		return to.ErrorResult(fmt.Errorf("delete branch error: %s", resp.Status))
+	}
 
-	return to.TextResult("Branch Deleted")
+	return to.Result("Branch Deleted")
 }
 
 func ListBranchesFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -125,10 +133,10 @@ func ListBranchesFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTool
 			PageSize: 100,
 		},
 	}
-	branches, _, err := gitea.Client().ListRepoBranches(owner, repo, opt)
+	branches, _, err := gitea.ClientFromContext(ctx).ListRepoBranches(owner, repo, opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("list branches error: %v", err))
 	}
 
-	return to.TextResult(branches)
+	return to.Result(branches)
 }
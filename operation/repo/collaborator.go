@@ -0,0 +1,204 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"gitea.com/gitea/gitea-mcp/pkg/gitea"
+	"gitea.com/gitea/gitea-mcp/pkg/log"
+	"gitea.com/gitea/gitea-mcp/pkg/params"
+	"gitea.com/gitea/gitea-mcp/pkg/ptr"
+	"gitea.com/gitea/gitea-mcp/pkg/to"
+
+	gitea_sdk "code.gitea.io/sdk/gitea"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	ListRepoCollaboratorsToolName  = "list_repo_collaborators"
+	AddRepoCollaboratorToolName    = "add_repo_collaborator"
+	RemoveRepoCollaboratorToolName = "remove_repo_collaborator"
+	GetRepoPermissionToolName      = "get_repo_permission"
+)
+
+const (
+	// maxCollaboratorsPageSize caps page_size of list_repo_collaborators, as
+	// the permission of every collaborator listed takes a request.
+	maxCollaboratorsPageSize = 50
+	// permissionWorkers is the number of permissions fetched concurrently.
+	permissionWorkers = 8
+)
+
+var (
+	ListRepoCollaboratorsTool = mcp.NewTool(
+		ListRepoCollaboratorsToolName,
+		mcp.WithDescription("List the collaborators of a repository with their permission"),
+		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
+		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+		mcp.WithNumber("page", mcp.Description("page number"), mcp.DefaultNumber(1), mcp.Min(1)),
+		mcp.WithNumber("page_size", mcp.Description("page size"), mcp.DefaultNumber(maxCollaboratorsPageSize), mcp.Min(1), mcp.Max(maxCollaboratorsPageSize)),
+		to.OutputSchema[[]*gitea_sdk.CollaboratorPermissionResult](),
+	)
+
+	AddRepoCollaboratorTool = mcp.NewTool(
+		AddRepoCollaboratorToolName,
+		mcp.WithDescription("Add a user as a collaborator of a repository, or change the permission of a collaborator"),
+		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
+		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+		mcp.WithString("username", mcp.Required(), mcp.Description("user to add")),
+		mcp.WithString("permission", mcp.Description("permission of the collaborator"), mcp.Enum("read", "write", "admin"), mcp.DefaultString("write")),
+		to.OutputSchema[string](),
+	)
+
+	RemoveRepoCollaboratorTool = mcp.NewTool(
+		RemoveRepoCollaboratorToolName,
+		mcp.WithDescription("Remove a collaborator from a repository"),
+		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
+		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+		mcp.WithString("username", mcp.Required(), mcp.Description("collaborator to remove")),
+		to.OutputSchema[string](),
+	)
+
+	GetRepoPermissionTool = mcp.NewTool(
+		GetRepoPermissionToolName,
+		mcp.WithDescription("Get the permission a user has on a repository, whether as owner, collaborator, team member or through visibility"),
+		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
+		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+		mcp.WithString("username", mcp.Required(), mcp.Description("user to check")),
+		to.OutputSchema[*gitea_sdk.CollaboratorPermissionResult](),
+	)
+)
+
+func init() {
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    ListRepoCollaboratorsTool,
+		Handler: ListRepoCollaboratorsFn,
+	})
+	Tool.RegisterWrite(server.ServerTool{
+		Tool:    AddRepoCollaboratorTool,
+		Handler: AddRepoCollaboratorFn,
+	})
+	Tool.RegisterWrite(server.ServerTool{
+		Tool:    RemoveRepoCollaboratorTool,
+		Handler: RemoveRepoCollaboratorFn,
+	})
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    GetRepoPermissionTool,
+		Handler: GetRepoPermissionFn,
+	})
+}
+
+// ListRepoCollaboratorsFn is the handler for "list_repo_collaborators" MCP
+// tool requests. Gitea lists collaborators without their permission, so it
+// is looked up for each of them.
+func ListRepoCollaboratorsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListRepoCollaboratorsFn")
+	owner, ok := req.GetArguments()["owner"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("owner is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("repo is required"))
+	}
+	list := params.ListOptions(req)
+	list.PageSize = min(max(list.PageSize, 1), maxCollaboratorsPageSize)
+	client := gitea.ClientFromContext(ctx)
+	users, _, err := client.ListCollaborators(owner, repo, gitea_sdk.ListCollaboratorsOptions{
+		ListOptions: list,
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list collaborators of %v/%v err: %v", owner, repo, err))
+	}
+	collaborators := make([]*gitea_sdk.CollaboratorPermissionResult, len(users))
+	errs := make([]error, len(users))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, permissionWorkers)
+	for i, user := range users {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			collaborators[i], _, errs[i] = client.CollaboratorPermission(owner, repo, user.UserName)
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return to.ErrorResult(fmt.Errorf("get permission of %v err: %v", users[i].UserName, err))
+		}
+	}
+	return to.Result(collaborators)
+}
+
+func AddRepoCollaboratorFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called AddRepoCollaboratorFn")
+	owner, ok := req.GetArguments()["owner"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("owner is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("repo is required"))
+	}
+	username, ok := req.GetArguments()["username"].(string)
+	if !ok || username == "" {
+		return to.ErrorResult(fmt.Errorf("username is required"))
+	}
+	permission, ok := req.GetArguments()["permission"].(string)
+	if !ok {
+		permission = string(gitea_sdk.AccessModeWrite)
+	}
+	_, err := gitea.ClientFromContext(ctx).AddCollaborator(owner, repo, username, gitea_sdk.AddCollaboratorOption{
+		Permission: ptr.To(gitea_sdk.AccessMode(permission)),
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("add collaborator %v err: %v", username, err))
+	}
+	return to.Result("Collaborator added successfully")
+}
+
+func RemoveRepoCollaboratorFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called RemoveRepoCollaboratorFn")
+	owner, ok := req.GetArguments()["owner"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("owner is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("repo is required"))
+	}
+	username, ok := req.GetArguments()["username"].(string)
+	if !ok || username == "" {
+		return to.ErrorResult(fmt.Errorf("username is required"))
+	}
+	_, err := gitea.ClientFromContext(ctx).DeleteCollaborator(owner, repo, username)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("remove collaborator %v err: %v", username, err))
+	}
+	return to.Result("Collaborator removed successfully")
+}
+
+func GetRepoPermissionFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called GetRepoPermissionFn")
+	owner, ok := req.GetArguments()["owner"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("owner is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("repo is required"))
+	}
+	username, ok := req.GetArguments()["username"].(string)
+	if !ok || username == "" {
+		return to.ErrorResult(fmt.Errorf("username is required"))
+	}
+	permission, _, err := gitea.ClientFromContext(ctx).CollaboratorPermission(owner, repo, username)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get permission of %v err: %v", username, err))
+	}
+	return to.Result(permission)
+}
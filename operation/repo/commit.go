@@ -3,10 +3,14 @@ package repo
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 
 	"gitea.com/gitea/gitea-mcp/pkg/gitea"
 	"gitea.com/gitea/gitea-mcp/pkg/log"
 	"gitea.com/gitea/gitea-mcp/pkg/to"
+	"gitea.com/gitea/gitea-mcp/pkg/tool"
 
 	gitea_sdk "code.gitea.io/sdk/gitea"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -15,6 +19,19 @@ import (
 
 const (
 	ListRepoCommitsToolName = "list_repo_commits"
+	GetCommitToolName       = "get_commit"
+	CompareRefsToolName     = "compare_refs"
+)
+
+const (
+	// defaultPatchBytes is the size patches are truncated to by default.
+	defaultPatchBytes = 64 << 10
+	// defaultCommitFiles caps the files of get_commit by default.
+	defaultCommitFiles = 300
+	// defaultCompareCommits and defaultCompareFiles cap the lists of
+	// compare_refs by default.
+	defaultCompareCommits = 100
+	defaultCompareFiles   = 300
 )
 
 var ListRepoCommitsTool = mcp.NewTool(
@@ -26,6 +43,31 @@ var ListRepoCommitsTool = mcp.NewTool(
 	mcp.WithString("path", mcp.Description("path indicates that only commits that include the path's file/dir should be returned.")),
 	mcp.WithNumber("page", mcp.Required(), mcp.Description("page number"), mcp.DefaultNumber(1), mcp.Min(1)),
 	mcp.WithNumber("page_size", mcp.Required(), mcp.Description("page size"), mcp.DefaultNumber(50), mcp.Min(1)),
+	to.OutputSchema[[]*gitea_sdk.Commit](),
+)
+
+var GetCommitTool = mcp.NewTool(
+	GetCommitToolName,
+	mcp.WithDescription("Get a commit with its stats, changed files and optionally its patch"),
+	mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
+	mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+	mcp.WithString("sha", mcp.Required(), mcp.Description("commit SHA, branch or tag")),
+	mcp.WithBoolean("patch", mcp.Description("include the unified diff of the commit")),
+	mcp.WithNumber("max_bytes", mcp.Description("maximum bytes of patch to return, longer patches are truncated"), mcp.DefaultNumber(defaultPatchBytes), mcp.Min(1)),
+	mcp.WithNumber("max_files", mcp.Description("maximum number of changed files to return"), mcp.DefaultNumber(defaultCommitFiles), mcp.Min(1)),
+	to.OutputSchema[CommitDetail](),
+)
+
+var CompareRefsTool = mcp.NewTool(
+	CompareRefsToolName,
+	mcp.WithDescription("Compare two refs: how far head is ahead of and behind base, and the commits and files of base...head"),
+	mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
+	mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+	mcp.WithString("base", mcp.Required(), mcp.Description("base branch, tag or commit")),
+	mcp.WithString("head", mcp.Required(), mcp.Description("head branch, tag or commit")),
+	mcp.WithNumber("max_commits", mcp.Description("maximum number of commits to return"), mcp.DefaultNumber(defaultCompareCommits), mcp.Min(1)),
+	mcp.WithNumber("max_files", mcp.Description("maximum number of files to return"), mcp.DefaultNumber(defaultCompareFiles), mcp.Min(1)),
+	to.OutputSchema[RefComparison](),
 )
 
 func init() {
@@ -33,6 +75,74 @@ func init() {
 		Tool:    ListRepoCommitsTool,
 		Handler: ListRepoCommitsFn,
 	})
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    GetCommitTool,
+		Handler: GetCommitFn,
+	}, tool.MinVersion("1.21"))
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    CompareRefsTool,
+		Handler: CompareRefsFn,
+	}, tool.MinVersion("1.22"))
+}
+
+// CommitFile is a file changed by a commit, with a status of "added",
+// "modified", "removed" or "renamed".
+type CommitFile struct {
+	Filename string `json:"filename"`
+	Status   string `json:"status"`
+}
+
+// CommitDetail is the result of get_commit.
+type CommitDetail struct {
+	SHA       string                 `json:"sha"`
+	HTMLURL   string                 `json:"html_url"`
+	Message   string                 `json:"message"`
+	Author    *gitea_sdk.CommitUser  `json:"author"`
+	Committer *gitea_sdk.CommitUser  `json:"committer"`
+	Parents   []string               `json:"parents"`
+	Stats     *gitea_sdk.CommitStats `json:"stats"`
+	Files     []CommitFile           `json:"files"`
+	Patch     string                 `json:"patch,omitempty"`
+	// Truncated is set if max_files left out files or max_bytes cut the
+	// patch short.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// CommitSummary is a commit listed by compare_refs.
+type CommitSummary struct {
+	SHA     string                `json:"sha"`
+	Message string                `json:"message"`
+	Author  *gitea_sdk.CommitUser `json:"author"`
+	HTMLURL string                `json:"html_url"`
+}
+
+// RefComparison is the result of compare_refs.
+type RefComparison struct {
+	Base string `json:"base"`
+	Head string `json:"head"`
+	// AheadBy counts the commits of head missing from base, BehindBy those
+	// of base missing from head.
+	AheadBy  int             `json:"ahead_by"`
+	BehindBy int             `json:"behind_by"`
+	Commits  []CommitSummary `json:"commits"`
+	Files    []CommitFile    `json:"files"`
+	// Truncated is set if max_commits or max_files left out entries.
+	Truncated bool `json:"truncated"`
+}
+
+// commitResponse is a commit as Gitea returns it. The SDK leaves out the
+// status of its files.
+type commitResponse struct {
+	gitea_sdk.Commit
+	Files []CommitFile `json:"files"`
+}
+
+// compareResponse is a comparison as Gitea returns it. The SDK leaves out its
+// files.
+type compareResponse struct {
+	TotalCommits int              `json:"total_commits"`
+	Commits      []commitResponse `json:"commits"`
+	Files        []CommitFile     `json:"files"`
 }
 
 func ListRepoCommitsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -63,9 +173,157 @@ func ListRepoCommitsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallT
 		SHA:  sha,
 		Path: path,
 	}
-	commits, _, err := gitea.Client().ListRepoCommits(owner, repo, opt)
+	commits, _, err := gitea.ClientFromContext(ctx).ListRepoCommits(owner, repo, opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("list repo commits err: %v", err))
 	}
-	return to.TextResult(commits)
+	return to.Result(commits)
+}
+
+func GetCommitFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called GetCommitFn")
+	owner, ok := req.GetArguments()["owner"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("owner is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("repo is required"))
+	}
+	sha, ok := req.GetArguments()["sha"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("sha is required"))
+	}
+	withPatch, _ := req.GetArguments()["patch"].(bool)
+	maxBytes, ok := req.GetArguments()["max_bytes"].(float64)
+	if !ok {
+		maxBytes = defaultPatchBytes
+	}
+	maxFiles, ok := req.GetArguments()["max_files"].(float64)
+	if !ok {
+		maxFiles = defaultCommitFiles
+	}
+
+	var commit commitResponse
+	err := gitea.Do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/%s/git/commits/%s?stat=true&files=true&verification=false", url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(sha)), nil, &commit)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get commit %v err: %v", sha, err))
+	}
+	result := CommitDetail{
+		HTMLURL: commit.HTMLURL,
+		Parents: []string{},
+		Stats:   commit.Stats,
+		Files:   commit.Files,
+	}
+	if commit.CommitMeta != nil {
+		result.SHA = commit.SHA
+	}
+	if commit.RepoCommit != nil {
+		result.Message = commit.RepoCommit.Message
+		result.Author = commit.RepoCommit.Author
+		result.Committer = commit.RepoCommit.Committer
+	}
+	for _, parent := range commit.Parents {
+		result.Parents = append(result.Parents, parent.SHA)
+	}
+	if result.Files == nil {
+		result.Files = []CommitFile{}
+	}
+	if len(result.Files) > int(maxFiles) {
+		result.Files = result.Files[:int(maxFiles)]
+		result.Truncated = true
+	}
+
+	if withPatch {
+		patch, _, err := gitea.ClientFromContext(ctx).GetCommitDiff(owner, repo, result.SHA)
+		if err != nil {
+			return to.ErrorResult(fmt.Errorf("get commit %v diff err: %v", sha, err))
+		}
+		if len(patch) > int(maxBytes) {
+			patch = truncate(patch, int(maxBytes))
+			result.Truncated = true
+		}
+		result.Patch = string(patch)
+	}
+	return to.Result(result)
+}
+
+// CompareRefsFn is the handler for "compare_refs" MCP tool requests. Gitea
+// only compares base...head, so the behind count is that of the commits of
+// base not in head, read from the total of a one-entry commit listing.
+func CompareRefsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called CompareRefsFn")
+	owner, ok := req.GetArguments()["owner"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("owner is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("repo is required"))
+	}
+	base, ok := req.GetArguments()["base"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("base is required"))
+	}
+	head, ok := req.GetArguments()["head"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("head is required"))
+	}
+	maxCommits, ok := req.GetArguments()["max_commits"].(float64)
+	if !ok {
+		maxCommits = defaultCompareCommits
+	}
+	maxFiles, ok := req.GetArguments()["max_files"].(float64)
+	if !ok {
+		maxFiles = defaultCompareFiles
+	}
+
+	var ahead compareResponse
+	basehead := url.PathEscape(base) + "..." + url.PathEscape(head)
+	err := gitea.Do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/%s/compare/%s", url.PathEscape(owner), url.PathEscape(repo), basehead), nil, &ahead)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("compare %v...%v err: %v", base, head, err))
+	}
+	query := url.Values{
+		"sha":          {base},
+		"not":          {head},
+		"limit":        {"1"},
+		"stat":         {"false"},
+		"files":        {"false"},
+		"verification": {"false"},
+	}
+	behind, err := gitea.Count(ctx, fmt.Sprintf("/repos/%s/%s/commits?%s", url.PathEscape(owner), url.PathEscape(repo), query.Encode()))
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("count commits of %v not in %v err: %v", base, head, err))
+	}
+
+	result := RefComparison{
+		Base:     base,
+		Head:     head,
+		AheadBy:  ahead.TotalCommits,
+		BehindBy: behind,
+		Commits:  []CommitSummary{},
+		Files:    []CommitFile{},
+	}
+	for _, c := range ahead.Commits {
+		if len(result.Commits) == int(maxCommits) {
+			result.Truncated = true
+			break
+		}
+		summary := CommitSummary{HTMLURL: c.HTMLURL}
+		if c.CommitMeta != nil {
+			summary.SHA = c.SHA
+		}
+		if c.RepoCommit != nil {
+			summary.Message = strings.TrimSpace(c.RepoCommit.Message)
+			summary.Author = c.RepoCommit.Author
+		}
+		result.Commits = append(result.Commits, summary)
+	}
+	if len(ahead.Files) > int(maxFiles) {
+		ahead.Files = ahead.Files[:int(maxFiles)]
+		result.Truncated = true
+	}
+	result.Files = append(result.Files, ahead.Files...)
+	return to.Result(result)
 }
@@ -0,0 +1,161 @@
+package repo
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"gitea.com/gitea/gitea-mcp/pkg/diff"
+	"gitea.com/gitea/gitea-mcp/pkg/gitea"
+	"gitea.com/gitea/gitea-mcp/pkg/log"
+	"gitea.com/gitea/gitea-mcp/pkg/to"
+
+	gitea_sdk "code.gitea.io/sdk/gitea"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const EditFileToolName = "edit_file"
+
+var EditFileTool = mcp.NewTool(
+	EditFileToolName,
+	mcp.WithDescription("Edit a file with search/replace edits or a unified diff and commit the result, without sending the whole file"),
+	mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
+	mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+	mcp.WithString("path", mcp.Required(), mcp.Description("file path")),
+	mcp.WithString("branch", mcp.Required(), mcp.Description("branch name")),
+	mcp.WithString("message", mcp.Required(), mcp.Description("commit message")),
+	mcp.WithString("sha", mcp.Description("SHA of the file the edits were made against; the edit fails if the file changed since")),
+	mcp.WithArray("edits", mcp.Description("edits applied in order, each replacing old_string, which must occur exactly once unless replace_all is set, with new_string"), mcp.Items(map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"old_string":  map[string]interface{}{"type": "string", "description": "exact text to replace, including whitespace"},
+			"new_string":  map[string]interface{}{"type": "string", "description": "replacement text"},
+			"replace_all": map[string]interface{}{"type": "boolean", "description": "replace every occurrence of old_string"},
+		},
+		"required": []string{"old_string", "new_string"},
+	})),
+	mcp.WithString("diff", mcp.Description("unified diff of the file to apply instead of edits; hunks are located by their lines, so line numbers may be off")),
+	to.OutputSchema[EditFileResult](),
+)
+
+func init() {
+	Tool.RegisterWrite(server.ServerTool{
+		Tool:    EditFileTool,
+		Handler: EditFileFn,
+	})
+}
+
+// EditFileResult is the result of edit_file.
+type EditFileResult struct {
+	// Commit is the SHA of the commit made.
+	Commit string `json:"commit"`
+	// SHA is the new SHA of the file, to pass to the next edit.
+	SHA  string `json:"sha"`
+	Diff string `json:"diff"`
+}
+
+// EditFileFn is the handler for "edit_file" MCP tool requests. It applies the
+// edits to the content at the head of branch and commits it with the SHA read,
+// so that Gitea refuses the commit if the file changed in between.
+func EditFileFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called EditFileFn")
+	owner, ok := req.GetArguments()["owner"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("owner is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("repo is required"))
+	}
+	filePath, ok := req.GetArguments()["path"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("path is required"))
+	}
+	branch, ok := req.GetArguments()["branch"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("branch is required"))
+	}
+	message, _ := req.GetArguments()["message"].(string)
+	sha, _ := req.GetArguments()["sha"].(string)
+	edits, hasEdits := req.GetArguments()["edits"].([]any)
+	patch, hasDiff := req.GetArguments()["diff"].(string)
+	if hasEdits == hasDiff {
+		return to.ErrorResult(fmt.Errorf("either edits or diff is required"))
+	}
+
+	client := gitea.ClientFromContext(ctx)
+	content, _, err := client.GetContents(owner, repo, branch, filePath)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get file err: %v", err))
+	}
+	if sha != "" && sha != content.SHA {
+		return to.ErrorResult(fmt.Errorf("%v changed since %v, its SHA is now %v", filePath, sha, content.SHA))
+	}
+	if content.Content == nil {
+		return to.ErrorResult(fmt.Errorf("%v is not a file", filePath))
+	}
+	data, err := base64.StdEncoding.DecodeString(*content.Content)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("decode base64 content err: %v", err))
+	}
+	if isBinary(data) {
+		return to.ErrorResult(fmt.Errorf("%v is a binary file", filePath))
+	}
+
+	old := string(data)
+	var edited string
+	if hasDiff {
+		edited, err = diff.Apply(old, patch)
+	} else {
+		edited, err = replace(old, edits)
+	}
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("edit %v err: %v", filePath, err))
+	}
+	if edited == old {
+		return to.ErrorResult(fmt.Errorf("the edits leave %v unchanged", filePath))
+	}
+
+	resp, _, err := client.UpdateFile(owner, repo, filePath, gitea_sdk.UpdateFileOptions{
+		SHA:     content.SHA,
+		Content: base64.StdEncoding.EncodeToString([]byte(edited)),
+		FileOptions: gitea_sdk.FileOptions{
+			Message:    message,
+			BranchName: branch,
+		},
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("update file err: %v", err))
+	}
+	result := EditFileResult{Diff: diff.Unified(filePath, old, edited, 3)}
+	if resp.Commit != nil {
+		result.Commit = resp.Commit.SHA
+	}
+	if resp.Content != nil {
+		result.SHA = resp.Content.SHA
+	}
+	return to.Result(result)
+}
+
+// replace applies the search/replace edits to content in order.
+func replace(content string, edits []any) (string, error) {
+	for i, e := range edits {
+		edit, _ := e.(map[string]any)
+		oldString, _ := edit["old_string"].(string)
+		newString, _ := edit["new_string"].(string)
+		replaceAll, _ := edit["replace_all"].(bool)
+		if oldString == "" {
+			return "", fmt.Errorf("edit %d: old_string is required", i+1)
+		}
+		switch n := strings.Count(content, oldString); {
+		case n == 0:
+			return "", fmt.Errorf("edit %d: old_string not found", i+1)
+		case n > 1 && !replaceAll:
+			return "", fmt.Errorf("edit %d: old_string found %d times, add surrounding lines to make it unique or set replace_all", i+1, n)
+		}
+		content = strings.ReplaceAll(content, oldString, newString)
+	}
+	return content, nil
+}
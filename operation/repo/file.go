@@ -1,16 +1,22 @@
 package repo
 
 import (
-	"bufio"
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
-	"encoding/json"
+	"encoding/hex"
 	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"unicode/utf8"
 
 	"gitea.com/gitea/gitea-mcp/pkg/gitea"
 	"gitea.com/gitea/gitea-mcp/pkg/log"
+	"gitea.com/gitea/gitea-mcp/pkg/ptr"
 	"gitea.com/gitea/gitea-mcp/pkg/to"
+	"gitea.com/gitea/gitea-mcp/pkg/tool"
 
 	gitea_sdk "code.gitea.io/sdk/gitea"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -32,8 +38,13 @@ var (
 		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
 		mcp.WithString("ref", mcp.Required(), mcp.Description("ref can be branch/tag/commit")),
-		mcp.WithString("filePath", mcp.Required(), mcp.Description("file path")),
-		mcp.WithBoolean("withLines", mcp.Description("whether to return file content with lines")),
+		mcp.WithString("path", mcp.Required(), mcp.Description("file path")),
+		mcp.WithBoolean("with_lines", mcp.Description("whether to return file content with lines")),
+		mcp.WithNumber("start_line", mcp.Description("first line to return, implies with_lines"), mcp.Min(1)),
+		mcp.WithNumber("end_line", mcp.Description("last line to return, implies with_lines"), mcp.Min(1)),
+		mcp.WithNumber("max_bytes", mcp.Description("maximum bytes of content to return, longer content is truncated"), mcp.Min(1)),
+		mcp.WithBoolean("raw", mcp.Description("read the file through the raw endpoint, returning its text and resolving Git LFS pointers to the stored object")),
+		to.OutputSchema[FileContent](),
 	)
 
 	GetDirContentTool = mcp.NewTool(
@@ -42,7 +53,8 @@ var (
 		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
 		mcp.WithString("ref", mcp.Required(), mcp.Description("ref can be branch/tag/commit")),
-		mcp.WithString("filePath", mcp.Required(), mcp.Description("directory path")),
+		mcp.WithString("path", mcp.Required(), mcp.Description("directory path")),
+		to.OutputSchema[[]*gitea_sdk.ContentsResponse](),
 	)
 
 	CreateFileTool = mcp.NewTool(
@@ -50,11 +62,12 @@ var (
 		mcp.WithDescription("Create file"),
 		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
-		mcp.WithString("filePath", mcp.Required(), mcp.Description("file path")),
+		mcp.WithString("path", mcp.Required(), mcp.Description("file path")),
 		mcp.WithString("content", mcp.Required(), mcp.Description("file content")),
 		mcp.WithString("message", mcp.Required(), mcp.Description("commit message")),
-		mcp.WithString("branch_name", mcp.Required(), mcp.Description("branch name")),
-		mcp.WithString("new_branch_name", mcp.Description("new branch name")),
+		mcp.WithString("branch", mcp.Required(), mcp.Description("branch name")),
+		mcp.WithString("new_branch", mcp.Description("new branch to create from branch and commit to")),
+		to.OutputSchema[string](),
 	)
 
 	UpdateFileTool = mcp.NewTool(
@@ -62,11 +75,12 @@ var (
 		mcp.WithDescription("Update file"),
 		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
-		mcp.WithString("filePath", mcp.Required(), mcp.Description("file path")),
+		mcp.WithString("path", mcp.Required(), mcp.Description("file path")),
 		mcp.WithString("sha", mcp.Required(), mcp.Description("sha is the SHA for the file that already exists")),
 		mcp.WithString("content", mcp.Required(), mcp.Description("file content")),
 		mcp.WithString("message", mcp.Required(), mcp.Description("commit message")),
-		mcp.WithString("branch_name", mcp.Required(), mcp.Description("branch name")),
+		mcp.WithString("branch", mcp.Required(), mcp.Description("branch name")),
+		to.OutputSchema[string](),
 	)
 
 	DeleteFileTool = mcp.NewTool(
@@ -74,10 +88,11 @@ var (
 		mcp.WithDescription("Delete file"),
 		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
-		mcp.WithString("filePath", mcp.Required(), mcp.Description("file path")),
+		mcp.WithString("path", mcp.Required(), mcp.Description("file path")),
 		mcp.WithString("message", mcp.Required(), mcp.Description("commit message")),
-		mcp.WithString("branch_name", mcp.Required(), mcp.Description("branch name")),
+		mcp.WithString("branch", mcp.Required(), mcp.Description("branch name")),
 		mcp.WithString("sha", mcp.Description("sha")),
+		to.OutputSchema[string](),
 	)
 )
 
@@ -85,23 +100,23 @@ func init() {
 	Tool.RegisterRead(server.ServerTool{
 		Tool:    GetFileContentTool,
 		Handler: GetFileContentFn,
-	})
+	}, tool.Alias("filePath", "path"), tool.Alias("withLines", "with_lines"))
 	Tool.RegisterRead(server.ServerTool{
 		Tool:    GetDirContentTool,
 		Handler: GetDirContentFn,
-	})
+	}, tool.Alias("filePath", "path"))
 	Tool.RegisterWrite(server.ServerTool{
 		Tool:    CreateFileTool,
 		Handler: CreateFileFn,
-	})
+	}, tool.Alias("filePath", "path"), tool.Alias("branch_name", "branch"), tool.Alias("new_branch_name", "new_branch"))
 	Tool.RegisterWrite(server.ServerTool{
 		Tool:    UpdateFileTool,
 		Handler: UpdateFileFn,
-	})
+	}, tool.Alias("filePath", "path"), tool.Alias("branch_name", "branch"))
 	Tool.RegisterWrite(server.ServerTool{
 		Tool:    DeleteFileTool,
 		Handler: DeleteFileFn,
-	})
+	}, tool.Alias("filePath", "path"), tool.Alias("branch_name", "branch"))
 }
 
 type ContentLine struct {
@@ -109,6 +124,27 @@ type ContentLine struct {
 	Content    string `json:"content"`
 }
 
+// FileContent is the result of get_file_content: the metadata of a file and
+// its content in the form requested.
+type FileContent struct {
+	*gitea_sdk.ContentsResponse
+	// Lines holds the requested lines instead of Content if with_lines,
+	// start_line or end_line is given.
+	Lines      []ContentLine `json:"lines,omitempty"`
+	TotalLines int           `json:"total_lines,omitempty"`
+	// Text holds the content of a file read in raw mode.
+	Text string `json:"text,omitempty"`
+	// Binary is set for files that are not UTF-8 text. Their content is
+	// left out and described by its type and SHA-256 instead.
+	Binary   bool   `json:"binary,omitempty"`
+	MimeType string `json:"mime_type,omitempty"`
+	SHA256   string `json:"sha256,omitempty"`
+	// LFS is set if the file is a Git LFS pointer, which raw mode resolves.
+	LFS bool `json:"lfs,omitempty"`
+	// Truncated is set if max_bytes cut the content short.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
 func GetFileContentFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	log.Debugf("Called GetFileFn")
 	owner, ok := req.GetArguments()["owner"].(string)
@@ -120,53 +156,126 @@ func GetFileContentFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 		return to.ErrorResult(fmt.Errorf("repo is required"))
 	}
 	ref, _ := req.GetArguments()["ref"].(string)
-	filePath, ok := req.GetArguments()["filePath"].(string)
+	filePath, ok := req.GetArguments()["path"].(string)
 	if !ok {
-		return to.ErrorResult(fmt.Errorf("filePath is required"))
+		return to.ErrorResult(fmt.Errorf("path is required"))
 	}
-	content, _, err := gitea.Client().GetContents(owner, repo, ref, filePath)
-	if err != nil {
-		return to.ErrorResult(fmt.Errorf("get file err: %v", err))
+	withLines, _ := req.GetArguments()["with_lines"].(bool)
+	startLine, hasStart := req.GetArguments()["start_line"].(float64)
+	endLine, hasEnd := req.GetArguments()["end_line"].(float64)
+	maxBytes, _ := req.GetArguments()["max_bytes"].(float64)
+	raw, _ := req.GetArguments()["raw"].(bool)
+	if hasStart && hasEnd && endLine < startLine {
+		return to.ErrorResult(fmt.Errorf("end_line %d is before start_line %d", int(endLine), int(startLine)))
 	}
-	withLines, _ := req.GetArguments()["withLines"].(bool)
-	if withLines {
-		rawContent, err := base64.StdEncoding.DecodeString(*content.Content)
+
+	var content *gitea_sdk.ContentsResponse
+	var data []byte
+	if raw {
+		var err error
+		data, _, err = gitea.ClientFromContext(ctx).GetFile(owner, repo, ref, filePath, true)
 		if err != nil {
-			return to.ErrorResult(fmt.Errorf("decode base64 content err: %v", err))
+			return to.ErrorResult(fmt.Errorf("get file err: %v", err))
 		}
-
-		contentLines := make([]ContentLine, 0)
-		line := 0
-
-		scanner := bufio.NewScanner(bytes.NewReader(rawContent))
-
-		for scanner.Scan() {
-			line++
-
-			contentLines = append(contentLines, ContentLine{
-				LineNumber: line,
-				Content:    scanner.Text(),
-			})
-
+		content = &gitea_sdk.ContentsResponse{
+			Name: path.Base(filePath),
+			Path: filePath,
+			Type: "file",
+			Size: int64(len(data)),
 		}
-		if err := scanner.Err(); err != nil {
-			return to.ErrorResult(fmt.Errorf("scan content err: %v", err))
+	} else {
+		var err error
+		content, _, err = gitea.ClientFromContext(ctx).GetContents(owner, repo, ref, filePath)
+		if err != nil {
+			return to.ErrorResult(fmt.Errorf("get file err: %v", err))
+		}
+		if content.Content != nil {
+			data, err = base64.StdEncoding.DecodeString(*content.Content)
+			if err != nil {
+				return to.ErrorResult(fmt.Errorf("decode base64 content err: %v", err))
+			}
 		}
+	}
+	result := FileContent{ContentsResponse: content}
+	result.LFS = !raw && IsLFSPointer(data)
+
+	if isBinary(data) {
+		sum := sha256.Sum256(data)
+		result.Binary = true
+		result.MimeType = http.DetectContentType(data)
+		result.SHA256 = hex.EncodeToString(sum[:])
+		content.Content, content.Encoding = nil, nil
+		return to.Result(result)
+	}
 
+	if withLines || hasStart || hasEnd {
+		lines := strings.Split(string(data), "\n")
 		// remove the last blank line if exists
 		// git does not consider the last line as a new line
-		if contentLines[len(contentLines)-1].Content == "" {
-			contentLines = contentLines[:len(contentLines)-1]
+		if lines[len(lines)-1] == "" {
+			lines = lines[:len(lines)-1]
 		}
-
-		contentBytes, err := json.MarshalIndent(contentLines, "", "  ")
-		if err != nil {
-			return to.ErrorResult(fmt.Errorf("marshal content lines err: %v", err))
+		if hasStart && int(startLine) > len(lines) {
+			return to.ErrorResult(fmt.Errorf("start_line %d is past the end of %s, total_lines is %d", int(startLine), filePath, len(lines)))
+		}
+		first, last := 1, len(lines)
+		if hasStart {
+			first = max(int(startLine), 1)
 		}
-		contentStr := string(contentBytes)
-		content.Content = &contentStr
+		if hasEnd {
+			last = min(int(endLine), last)
+		}
+		result.TotalLines = len(lines)
+		result.Lines = make([]ContentLine, 0, max(last-first+1, 0))
+		size := 0
+		for n := first; n <= last; n++ {
+			size += len(lines[n-1]) + 1
+			if maxBytes > 0 && size > int(maxBytes) {
+				result.Truncated = true
+				break
+			}
+			result.Lines = append(result.Lines, ContentLine{LineNumber: n, Content: lines[n-1]})
+		}
+		content.Content, content.Encoding = nil, nil
+		return to.Result(result)
+	}
+
+	if maxBytes > 0 && len(data) > int(maxBytes) {
+		data = truncate(data, int(maxBytes))
+		result.Truncated = true
+	}
+	if raw {
+		result.Text = string(data)
+	} else if result.Truncated {
+		content.Content = ptr.To(base64.StdEncoding.EncodeToString(data))
+	}
+	return to.Result(result)
+}
+
+// lfsPointerPrefix starts the files Git LFS stores in place of the objects.
+const lfsPointerPrefix = "version https://git-lfs.github.com/spec/v1\n"
+
+// IsLFSPointer reports whether data is a Git LFS pointer file.
+func IsLFSPointer(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(lfsPointerPrefix))
+}
+
+// isBinary reports whether data is not UTF-8 text, judged like git by a NUL
+// byte in its beginning.
+func isBinary(data []byte) bool {
+	head := data[:min(len(data), 8000)]
+	return bytes.IndexByte(head, 0) >= 0 || !utf8.Valid(data)
+}
+
+// truncate cuts data to at most n bytes without splitting a UTF-8 sequence.
+func truncate(data []byte, n int) []byte {
+	if n >= len(data) {
+		return data
+	}
+	for n > 0 && !utf8.RuneStart(data[n]) {
+		n--
 	}
-	return to.TextResult(content)
+	return data[:n]
 }
 
 func GetDirContentFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -180,15 +289,15 @@ func GetDirContentFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToo
 		return to.ErrorResult(fmt.Errorf("repo is required"))
 	}
 	ref, _ := req.GetArguments()["ref"].(string)
-	filePath, ok := req.GetArguments()["filePath"].(string)
+	filePath, ok := req.GetArguments()["path"].(string)
 	if !ok {
-		return to.ErrorResult(fmt.Errorf("filePath is required"))
+		return to.ErrorResult(fmt.Errorf("path is required"))
 	}
-	content, _, err := gitea.Client().ListContents(owner, repo, ref, filePath)
+	content, _, err := gitea.ClientFromContext(ctx).ListContents(owner, repo, ref, filePath)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("get dir content err: %v", err))
 	}
-	return to.TextResult(content)
+	return to.Result(content)
 }
 
 func CreateFileFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -201,26 +310,28 @@ func CreateFileFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRe
 	if !ok {
 		return to.ErrorResult(fmt.Errorf("repo is required"))
 	}
-	filePath, ok := req.GetArguments()["filePath"].(string)
+	filePath, ok := req.GetArguments()["path"].(string)
 	if !ok {
-		return to.ErrorResult(fmt.Errorf("filePath is required"))
+		return to.ErrorResult(fmt.Errorf("path is required"))
 	}
 	content, _ := req.GetArguments()["content"].(string)
 	message, _ := req.GetArguments()["message"].(string)
-	branchName, _ := req.GetArguments()["branch_name"].(string)
+	branchName, _ := req.GetArguments()["branch"].(string)
+	newBranchName, _ := req.GetArguments()["new_branch"].(string)
 	opt := gitea_sdk.CreateFileOptions{
 		Content: base64.StdEncoding.EncodeToString([]byte(content)),
 		FileOptions: gitea_sdk.FileOptions{
-			Message:    message,
-			BranchName: branchName,
+			Message:       message,
+			BranchName:    branchName,
+			NewBranchName: newBranchName,
 		},
 	}
 
-	_, _, err := gitea.Client().CreateFile(owner, repo, filePath, opt)
+	_, _, err := gitea.ClientFromContext(ctx).CreateFile(owner, repo, filePath, opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("create file err: %v", err))
 	}
-	return to.TextResult("Create file success")
+	return to.Result("Create file success")
 }
 
 func UpdateFileFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -233,9 +344,9 @@ func UpdateFileFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRe
 	if !ok {
 		return to.ErrorResult(fmt.Errorf("repo is required"))
 	}
-	filePath, ok := req.GetArguments()["filePath"].(string)
+	filePath, ok := req.GetArguments()["path"].(string)
 	if !ok {
-		return to.ErrorResult(fmt.Errorf("filePath is required"))
+		return to.ErrorResult(fmt.Errorf("path is required"))
 	}
 	sha, ok := req.GetArguments()["sha"].(string)
 	if !ok {
@@ -243,7 +354,7 @@ func UpdateFileFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRe
 	}
 	content, _ := req.GetArguments()["content"].(string)
 	message, _ := req.GetArguments()["message"].(string)
-	branchName, _ := req.GetArguments()["branch_name"].(string)
+	branchName, _ := req.GetArguments()["branch"].(string)
 
 	opt := gitea_sdk.UpdateFileOptions{
 		SHA:     sha,
@@ -253,11 +364,11 @@ func UpdateFileFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRe
 			BranchName: branchName,
 		},
 	}
-	_, _, err := gitea.Client().UpdateFile(owner, repo, filePath, opt)
+	_, _, err := gitea.ClientFromContext(ctx).UpdateFile(owner, repo, filePath, opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("update file err: %v", err))
 	}
-	return to.TextResult("Update file success")
+	return to.Result("Update file success")
 }
 
 func DeleteFileFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -270,12 +381,12 @@ func DeleteFileFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRe
 	if !ok {
 		return to.ErrorResult(fmt.Errorf("repo is required"))
 	}
-	filePath, ok := req.GetArguments()["filePath"].(string)
+	filePath, ok := req.GetArguments()["path"].(string)
 	if !ok {
-		return to.ErrorResult(fmt.Errorf("filePath is required"))
+		return to.ErrorResult(fmt.Errorf("path is required"))
 	}
 	message, _ := req.GetArguments()["message"].(string)
-	branchName, _ := req.GetArguments()["branch_name"].(string)
+	branchName, _ := req.GetArguments()["branch"].(string)
 	sha, ok := req.GetArguments()["sha"].(string)
 	if !ok {
 		return to.ErrorResult(fmt.Errorf("sha is required"))
@@ -287,9 +398,9 @@ func DeleteFileFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRe
 		},
 		SHA: sha,
 	}
-	_, err := gitea.Client().DeleteFile(owner, repo, filePath, opt)
+	_, err := gitea.ClientFromContext(ctx).DeleteFile(owner, repo, filePath, opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("delete file err: %v", err))
 	}
-	return to.TextResult("Delete file success")
+	return to.Result("Delete file success")
 }
@@ -0,0 +1,316 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"gitea.com/gitea/gitea-mcp/pkg/gitea"
+	"gitea.com/gitea/gitea-mcp/pkg/log"
+	"gitea.com/gitea/gitea-mcp/pkg/ptr"
+	"gitea.com/gitea/gitea-mcp/pkg/to"
+	"gitea.com/gitea/gitea-mcp/pkg/tool"
+
+	gitea_sdk "code.gitea.io/sdk/gitea"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	ListBranchProtectionsToolName  = "list_branch_protections"
+	GetBranchProtectionToolName    = "get_branch_protection"
+	CreateBranchProtectionToolName = "create_branch_protection"
+	EditBranchProtectionToolName   = "edit_branch_protection"
+	DeleteBranchProtectionToolName = "delete_branch_protection"
+
+	// branchProtectionVersion is the release that added the branch
+	// protection API.
+	branchProtectionVersion = "1.12"
+)
+
+// protectionOptions are the settings of a branch protection, shared by
+// create_branch_protection and edit_branch_protection.
+var protectionOptions = []mcp.ToolOption{
+	mcp.WithBoolean("enable_push", mcp.Description("allow pushing to the branch, restricted to the push whitelist if enabled")),
+	mcp.WithBoolean("enable_push_whitelist", mcp.Description("only allow the whitelisted users and teams to push")),
+	mcp.WithArray("push_whitelist_usernames", mcp.Description("users allowed to push"), mcp.Items(map[string]interface{}{"type": "string"})),
+	mcp.WithArray("push_whitelist_teams", mcp.Description("teams allowed to push"), mcp.Items(map[string]interface{}{"type": "string"})),
+	mcp.WithBoolean("push_whitelist_deploy_keys", mcp.Description("allow deploy keys with write access to push")),
+	mcp.WithBoolean("enable_merge_whitelist", mcp.Description("only allow the whitelisted users and teams to merge pull requests")),
+	mcp.WithArray("merge_whitelist_usernames", mcp.Description("users allowed to merge"), mcp.Items(map[string]interface{}{"type": "string"})),
+	mcp.WithArray("merge_whitelist_teams", mcp.Description("teams allowed to merge"), mcp.Items(map[string]interface{}{"type": "string"})),
+	mcp.WithBoolean("enable_status_check", mcp.Description("require status checks to pass before merging")),
+	mcp.WithArray("status_check_contexts", mcp.Description("status contexts that must pass, globs allowed"), mcp.Items(map[string]interface{}{"type": "string"})),
+	mcp.WithNumber("required_approvals", mcp.Description("approvals required before merging"), mcp.Min(0)),
+	mcp.WithBoolean("enable_approvals_whitelist", mcp.Description("only count approvals of the whitelisted users and teams")),
+	mcp.WithArray("approvals_whitelist_usernames", mcp.Description("users whose approvals count"), mcp.Items(map[string]interface{}{"type": "string"})),
+	mcp.WithArray("approvals_whitelist_teams", mcp.Description("teams whose approvals count"), mcp.Items(map[string]interface{}{"type": "string"})),
+	mcp.WithBoolean("block_on_rejected_reviews", mcp.Description("block merging while changes are requested")),
+	mcp.WithBoolean("block_on_official_review_requests", mcp.Description("block merging while official reviews are requested")),
+	mcp.WithBoolean("block_on_outdated_branch", mcp.Description("block merging while the head branch is behind the base")),
+	mcp.WithBoolean("dismiss_stale_approvals", mcp.Description("dismiss approvals when new commits are pushed")),
+	mcp.WithBoolean("require_signed_commits", mcp.Description("reject unsigned commits")),
+	mcp.WithString("protected_file_patterns", mcp.Description("semicolon-separated globs of files that cannot be changed by pushes")),
+	mcp.WithString("unprotected_file_patterns", mcp.Description("semicolon-separated globs of files that can be changed by pushes even without push access")),
+}
+
+var (
+	ListBranchProtectionsTool = mcp.NewTool(
+		ListBranchProtectionsToolName,
+		mcp.WithDescription("List the branch protection rules of a repository"),
+		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
+		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+		mcp.WithNumber("page", mcp.Description("page number"), mcp.DefaultNumber(1), mcp.Min(1)),
+		mcp.WithNumber("page_size", mcp.Description("page size"), mcp.DefaultNumber(20), mcp.Min(1)),
+		to.OutputSchema[[]*gitea_sdk.BranchProtection](),
+	)
+
+	GetBranchProtectionTool = mcp.NewTool(
+		GetBranchProtectionToolName,
+		mcp.WithDescription("Get a branch protection rule"),
+		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
+		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("rule name, the branch name or glob it protects")),
+		to.OutputSchema[*gitea_sdk.BranchProtection](),
+	)
+
+	CreateBranchProtectionTool = mcp.NewTool(
+		CreateBranchProtectionToolName,
+		append([]mcp.ToolOption{
+			mcp.WithDescription("Protect the branches matching a name or glob"),
+			mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
+			mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+			mcp.WithString("name", mcp.Required(), mcp.Description("branch name, or glob such as release/*, to protect")),
+			to.OutputSchema[*gitea_sdk.BranchProtection](),
+		}, protectionOptions...)...,
+	)
+
+	EditBranchProtectionTool = mcp.NewTool(
+		EditBranchProtectionToolName,
+		append([]mcp.ToolOption{
+			mcp.WithDescription("Change the settings of a branch protection rule, leaving those not given unchanged"),
+			mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
+			mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+			mcp.WithString("name", mcp.Required(), mcp.Description("rule name")),
+			to.OutputSchema[*gitea_sdk.BranchProtection](),
+		}, protectionOptions...)...,
+	)
+
+	DeleteBranchProtectionTool = mcp.NewTool(
+		DeleteBranchProtectionToolName,
+		mcp.WithDescription("Delete a branch protection rule"),
+		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
+		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+		mcp.WithString("name", mcp.Required(), mcp.Description("rule name")),
+		to.OutputSchema[string](),
+	)
+)
+
+func init() {
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    ListBranchProtectionsTool,
+		Handler: ListBranchProtectionsFn,
+	}, tool.MinVersion(branchProtectionVersion))
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    GetBranchProtectionTool,
+		Handler: GetBranchProtectionFn,
+	}, tool.MinVersion(branchProtectionVersion))
+	Tool.RegisterWrite(server.ServerTool{
+		Tool:    CreateBranchProtectionTool,
+		Handler: CreateBranchProtectionFn,
+	}, tool.MinVersion(branchProtectionVersion))
+	Tool.RegisterWrite(server.ServerTool{
+		Tool:    EditBranchProtectionTool,
+		Handler: EditBranchProtectionFn,
+	}, tool.MinVersion(branchProtectionVersion))
+	Tool.RegisterWrite(server.ServerTool{
+		Tool:    DeleteBranchProtectionTool,
+		Handler: DeleteBranchProtectionFn,
+	}, tool.MinVersion(branchProtectionVersion))
+}
+
+// protectionArgs returns the protection settings given as arguments, leaving
+// the others nil.
+func protectionArgs(args map[string]any) gitea_sdk.EditBranchProtectionOption {
+	boolArg := func(name string) *bool {
+		if v, ok := args[name].(bool); ok {
+			return &v
+		}
+		return nil
+	}
+	stringArg := func(name string) *string {
+		if v, ok := args[name].(string); ok {
+			return &v
+		}
+		return nil
+	}
+	listArg := func(name string) []string {
+		if _, ok := args[name]; !ok {
+			return nil
+		}
+		return stringArgs(args[name])
+	}
+	opt := gitea_sdk.EditBranchProtectionOption{
+		EnablePush:                    boolArg("enable_push"),
+		EnablePushWhitelist:           boolArg("enable_push_whitelist"),
+		PushWhitelistUsernames:        listArg("push_whitelist_usernames"),
+		PushWhitelistTeams:            listArg("push_whitelist_teams"),
+		PushWhitelistDeployKeys:       boolArg("push_whitelist_deploy_keys"),
+		EnableMergeWhitelist:          boolArg("enable_merge_whitelist"),
+		MergeWhitelistUsernames:       listArg("merge_whitelist_usernames"),
+		MergeWhitelistTeams:           listArg("merge_whitelist_teams"),
+		EnableStatusCheck:             boolArg("enable_status_check"),
+		StatusCheckContexts:           listArg("status_check_contexts"),
+		EnableApprovalsWhitelist:      boolArg("enable_approvals_whitelist"),
+		ApprovalsWhitelistUsernames:   listArg("approvals_whitelist_usernames"),
+		ApprovalsWhitelistTeams:       listArg("approvals_whitelist_teams"),
+		BlockOnRejectedReviews:        boolArg("block_on_rejected_reviews"),
+		BlockOnOfficialReviewRequests: boolArg("block_on_official_review_requests"),
+		BlockOnOutdatedBranch:         boolArg("block_on_outdated_branch"),
+		DismissStaleApprovals:         boolArg("dismiss_stale_approvals"),
+		RequireSignedCommits:          boolArg("require_signed_commits"),
+		ProtectedFilePatterns:         stringArg("protected_file_patterns"),
+		UnprotectedFilePatterns:       stringArg("unprotected_file_patterns"),
+	}
+	if v, ok := args["required_approvals"].(float64); ok {
+		opt.RequiredApprovals = ptr.To(int64(v))
+	}
+	return opt
+}
+
+func ListBranchProtectionsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListBranchProtectionsFn")
+	owner, ok := req.GetArguments()["owner"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("owner is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("repo is required"))
+	}
+	page, ok := req.GetArguments()["page"].(float64)
+	if !ok {
+		page = 1
+	}
+	pageSize, ok := req.GetArguments()["page_size"].(float64)
+	if !ok {
+		pageSize = 20
+	}
+	// Gitea returns every rule at once, so the page is cut out here.
+	protections, _, err := gitea.ClientFromContext(ctx).ListBranchProtections(owner, repo, gitea_sdk.ListBranchProtectionsOptions{})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list branch protections err: %v", err))
+	}
+	start := min(max(int(page)-1, 0)*max(int(pageSize), 1), len(protections))
+	end := min(start+max(int(pageSize), 1), len(protections))
+	return to.Result(protections[start:end])
+}
+
+func GetBranchProtectionFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called GetBranchProtectionFn")
+	owner, ok := req.GetArguments()["owner"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("owner is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("repo is required"))
+	}
+	name, ok := req.GetArguments()["name"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("name is required"))
+	}
+	protection, _, err := gitea.ClientFromContext(ctx).GetBranchProtection(owner, repo, name)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get branch protection %v err: %v", name, err))
+	}
+	return to.Result(protection)
+}
+
+func CreateBranchProtectionFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called CreateBranchProtectionFn")
+	owner, ok := req.GetArguments()["owner"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("owner is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("repo is required"))
+	}
+	name, ok := req.GetArguments()["name"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("name is required"))
+	}
+	edit := protectionArgs(req.GetArguments())
+	opt := gitea_sdk.CreateBranchProtectionOption{
+		BranchName:                    name,
+		RuleName:                      name,
+		EnablePush:                    ptr.Deref(edit.EnablePush, false),
+		EnablePushWhitelist:           ptr.Deref(edit.EnablePushWhitelist, false),
+		PushWhitelistUsernames:        edit.PushWhitelistUsernames,
+		PushWhitelistTeams:            edit.PushWhitelistTeams,
+		PushWhitelistDeployKeys:       ptr.Deref(edit.PushWhitelistDeployKeys, false),
+		EnableMergeWhitelist:          ptr.Deref(edit.EnableMergeWhitelist, false),
+		MergeWhitelistUsernames:       edit.MergeWhitelistUsernames,
+		MergeWhitelistTeams:           edit.MergeWhitelistTeams,
+		EnableStatusCheck:             ptr.Deref(edit.EnableStatusCheck, false),
+		StatusCheckContexts:           edit.StatusCheckContexts,
+		RequiredApprovals:             ptr.Deref(edit.RequiredApprovals, 0),
+		EnableApprovalsWhitelist:      ptr.Deref(edit.EnableApprovalsWhitelist, false),
+		ApprovalsWhitelistUsernames:   edit.ApprovalsWhitelistUsernames,
+		ApprovalsWhitelistTeams:       edit.ApprovalsWhitelistTeams,
+		BlockOnRejectedReviews:        ptr.Deref(edit.BlockOnRejectedReviews, false),
+		BlockOnOfficialReviewRequests: ptr.Deref(edit.BlockOnOfficialReviewRequests, false),
+		BlockOnOutdatedBranch:         ptr.Deref(edit.BlockOnOutdatedBranch, false),
+		DismissStaleApprovals:         ptr.Deref(edit.DismissStaleApprovals, false),
+		RequireSignedCommits:          ptr.Deref(edit.RequireSignedCommits, false),
+		ProtectedFilePatterns:         ptr.Deref(edit.ProtectedFilePatterns, ""),
+		UnprotectedFilePatterns:       ptr.Deref(edit.UnprotectedFilePatterns, ""),
+	}
+	protection, _, err := gitea.ClientFromContext(ctx).CreateBranchProtection(owner, repo, opt)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("create branch protection %v err: %v", name, err))
+	}
+	return to.Result(protection)
+}
+
+func EditBranchProtectionFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called EditBranchProtectionFn")
+	owner, ok := req.GetArguments()["owner"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("owner is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("repo is required"))
+	}
+	name, ok := req.GetArguments()["name"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("name is required"))
+	}
+	protection, _, err := gitea.ClientFromContext(ctx).EditBranchProtection(owner, repo, name, protectionArgs(req.GetArguments()))
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("edit branch protection %v err: %v", name, err))
+	}
+	return to.Result(protection)
+}
+
+func DeleteBranchProtectionFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called DeleteBranchProtectionFn")
+	owner, ok := req.GetArguments()["owner"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("owner is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("repo is required"))
+	}
+	name, ok := req.GetArguments()["name"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("name is required"))
+	}
+	_, err := gitea.ClientFromContext(ctx).DeleteBranchProtection(owner, repo, name)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("delete branch protection %v err: %v", name, err))
+	}
+	return to.Result("Branch Protection Deleted")
+}
@@ -9,6 +9,7 @@ import (
 	"gitea.com/gitea/gitea-mcp/pkg/log"
 	"gitea.com/gitea/gitea-mcp/pkg/ptr"
 	"gitea.com/gitea/gitea-mcp/pkg/to"
+	"gitea.com/gitea/gitea-mcp/pkg/tool"
 
 	gitea_sdk "code.gitea.io/sdk/gitea"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -29,12 +30,13 @@ var (
 		mcp.WithDescription("Create release"),
 		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
-		mcp.WithString("tag_name", mcp.Required(), mcp.Description("tag name")),
+		mcp.WithString("tag", mcp.Required(), mcp.Description("tag name")),
 		mcp.WithString("target", mcp.Required(), mcp.Description("target commitish")),
 		mcp.WithString("title", mcp.Required(), mcp.Description("release title")),
 		mcp.WithBoolean("is_draft", mcp.Description("Whether the release is draft"), mcp.DefaultBool(false)),
 		mcp.WithBoolean("is_pre_release", mcp.Description("Whether the release is pre-release"), mcp.DefaultBool(false)),
 		mcp.WithString("body", mcp.Description("release body")),
+		to.OutputSchema[string](),
 	)
 
 	DeleteReleaseTool = mcp.NewTool(
@@ -43,6 +45,7 @@ var (
 		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
 		mcp.WithNumber("id", mcp.Required(), mcp.Description("release id")),
+		to.OutputSchema[string](),
 	)
 
 	GetReleaseTool = mcp.NewTool(
@@ -51,6 +54,7 @@ var (
 		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
 		mcp.WithNumber("id", mcp.Required(), mcp.Description("release id")),
+		to.OutputSchema[*gitea_sdk.Release](),
 	)
 
 	GetLatestReleaseTool = mcp.NewTool(
@@ -58,6 +62,7 @@ var (
 		mcp.WithDescription("Get latest release"),
 		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+		to.OutputSchema[*gitea_sdk.Release](),
 	)
 
 	ListReleasesTool = mcp.NewTool(
@@ -68,7 +73,8 @@ var (
 		mcp.WithBoolean("is_draft", mcp.Description("Whether the release is draft"), mcp.DefaultBool(false)),
 		mcp.WithBoolean("is_pre_release", mcp.Description("Whether the release is pre-release"), mcp.DefaultBool(false)),
 		mcp.WithNumber("page", mcp.Description("page number"), mcp.DefaultNumber(1), mcp.Min(1)),
-		mcp.WithNumber("pageSize", mcp.Description("page size"), mcp.DefaultNumber(20), mcp.Min(1)),
+		mcp.WithNumber("page_size", mcp.Description("page size"), mcp.DefaultNumber(20), mcp.Min(1)),
+		to.OutputSchema[[]ListReleaseResult](),
 	)
 )
 
@@ -76,7 +82,7 @@ func init() {
 	Tool.RegisterWrite(server.ServerTool{
 		Tool:    CreateReleaseTool,
 		Handler: CreateReleaseFn,
-	})
+	}, tool.Alias("tag_name", "tag"))
 	Tool.RegisterWrite(server.ServerTool{
 		Tool:    DeleteReleaseTool,
 		Handler: DeleteReleaseFn,
@@ -92,7 +98,7 @@ func init() {
 	Tool.RegisterRead(server.ServerTool{
 		Tool:    ListReleasesTool,
 		Handler: ListReleasesFn,
-	})
+	}, tool.Alias("pageSize", "page_size"))
 }
 
 // To avoid return too many tokens, we need to provide at least information as possible
@@ -118,9 +124,9 @@ func CreateReleaseFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToo
 	if !ok {
 		return nil, fmt.Errorf("repo is required")
 	}
-	tagName, ok := req.GetArguments()["tag_name"].(string)
+	tagName, ok := req.GetArguments()["tag"].(string)
 	if !ok {
-		return nil, fmt.Errorf("tag_name is required")
+		return nil, fmt.Errorf("tag is required")
 	}
 	target, ok := req.GetArguments()["target"].(string)
 	if !ok {
@@ -134,7 +140,7 @@ func CreateReleaseFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToo
 	isPreRelease, _ := req.GetArguments()["is_pre_release"].(bool)
 	body, _ := req.GetArguments()["body"].(string)
 
-	_, _, err := gitea.Client().CreateRelease(owner, repo, gitea_sdk.CreateReleaseOption{
+	_, _, err := gitea.ClientFromContext(ctx).CreateRelease(owner, repo, gitea_sdk.CreateReleaseOption{
 		TagName:      tagName,
 		Target:       target,
 		Title:        title,
@@ -146,7 +152,7 @@ func CreateReleaseFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToo
 		return nil, fmt.Errorf("create release error: %v", err)
 	}
 
-	return mcp.NewToolResultText("Release Created"), nil
+	return to.Result("Release Created")
 }
 
 func DeleteReleaseFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -164,12 +170,12 @@ func DeleteReleaseFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToo
 		return nil, fmt.Errorf("id is required")
 	}
 
-	_, err := gitea.Client().DeleteRelease(owner, repo, int64(id))
+	_, err := gitea.ClientFromContext(ctx).DeleteRelease(owner, repo, int64(id))
 	if err != nil {
 		return nil, fmt.Errorf("delete release error: %v", err)
 	}
 
-	return to.TextResult("Release deleted successfully")
+	return to.Result("Release deleted successfully")
 }
 
 func GetReleaseFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -187,12 +193,12 @@ func GetReleaseFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRe
 		return nil, fmt.Errorf("id is required")
 	}
 
-	release, _, err := gitea.Client().GetRelease(owner, repo, int64(id))
+	release, _, err := gitea.ClientFromContext(ctx).GetRelease(owner, repo, int64(id))
 	if err != nil {
 		return nil, fmt.Errorf("get release error: %v", err)
 	}
 
-	return to.TextResult(release)
+	return to.Result(release)
 }
 
 func GetLatestReleaseFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -206,12 +212,12 @@ func GetLatestReleaseFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.Call
 		return nil, fmt.Errorf("repo is required")
 	}
 
-	release, _, err := gitea.Client().GetLatestRelease(owner, repo)
+	release, _, err := gitea.ClientFromContext(ctx).GetLatestRelease(owner, repo)
 	if err != nil {
 		return nil, fmt.Errorf("get latest release error: %v", err)
 	}
 
-	return to.TextResult(release)
+	return to.Result(release)
 }
 
 func ListReleasesFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -235,9 +241,9 @@ func ListReleasesFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTool
 		pIsPreRelease = ptr.To(isPreRelease)
 	}
 	page, _ := req.GetArguments()["page"].(float64)
-	pageSize, _ := req.GetArguments()["pageSize"].(float64)
+	pageSize, _ := req.GetArguments()["page_size"].(float64)
 
-	releases, _, err := gitea.Client().ListReleases(owner, repo, gitea_sdk.ListReleasesOptions{
+	releases, _, err := gitea.ClientFromContext(ctx).ListReleases(owner, repo, gitea_sdk.ListReleasesOptions{
 		ListOptions: gitea_sdk.ListOptions{
 			Page:     int(page),
 			PageSize: int(pageSize),
@@ -249,7 +255,7 @@ func ListReleasesFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTool
 		return nil, fmt.Errorf("list releases error: %v", err)
 	}
 
-	results := make([]ListReleaseResult, len(releases))
+	results := make([]ListReleaseResult, 0, len(releases))
 	for _, release := range releases {
 		results = append(results, ListReleaseResult{
 			ID:           release.ID,
@@ -262,5 +268,5 @@ func ListReleasesFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTool
 			PublishedAt:  release.PublishedAt,
 		})
 	}
-	return to.TextResult(results)
+	return to.Result(results)
 }
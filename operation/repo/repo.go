@@ -16,13 +16,16 @@ import (
 	"github.com/mark3labs/mcp-go/server"
 )
 
-var Tool = tool.New()
+var Tool = tool.New(tool.Scope(gitea.ScopeRepository))
 
 const (
-	CreateRepoToolName  = "create_repo"
-	ForkRepoToolName    = "fork_repo"
-	ListMyReposToolName = "list_my_repos"
-	DeleteRepoToolName  = "delete_repo"
+	CreateRepoToolName   = "create_repo"
+	ForkRepoToolName     = "fork_repo"
+	ListMyReposToolName  = "list_my_repos"
+	DeleteRepoToolName   = "delete_repo"
+	GetRepoToolName      = "get_repo"
+	EditRepoToolName     = "edit_repo"
+	TransferRepoToolName = "transfer_repo"
 )
 
 var (
@@ -40,22 +43,25 @@ var (
 		mcp.WithString("readme", mcp.Description("Readme of the repository to create")),
 		mcp.WithString("default_branch", mcp.Description("DefaultBranch of the repository (used when initializes and in template)")),
 		mcp.WithString("organization", mcp.Description("Organization name to create repository in (optional - defaults to personal account)")),
+		to.OutputSchema[*gitea_sdk.Repository](),
 	)
 
 	ForkRepoTool = mcp.NewTool(
 		ForkRepoToolName,
 		mcp.WithDescription("Fork repository"),
-		mcp.WithString("user", mcp.Required(), mcp.Description("User name of the repository to fork")),
+		mcp.WithString("owner", mcp.Required(), mcp.Description("Owner of the repository to fork")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("Repository name to fork")),
 		mcp.WithString("organization", mcp.Description("Organization name to fork")),
 		mcp.WithString("name", mcp.Description("Name of the forked repository")),
+		to.OutputSchema[string](),
 	)
 
 	ListMyReposTool = mcp.NewTool(
 		ListMyReposToolName,
 		mcp.WithDescription("List my repositories"),
 		mcp.WithNumber("page", mcp.Required(), mcp.Description("Page number"), mcp.DefaultNumber(1), mcp.Min(1)),
-		mcp.WithNumber("pageSize", mcp.Required(), mcp.Description("Page size number"), mcp.DefaultNumber(100), mcp.Min(1)),
+		mcp.WithNumber("page_size", mcp.Required(), mcp.Description("Page size number"), mcp.DefaultNumber(100), mcp.Min(1)),
+		to.OutputSchema[[]*gitea_sdk.Repository](),
 	)
 
 	DeleteRepoTool = mcp.NewTool(
@@ -63,6 +69,48 @@ var (
 		mcp.WithDescription("Delete repository"),
 		mcp.WithString("owner", mcp.Required(), mcp.Description("Repository owner")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("Repository name")),
+		to.OutputSchema[string](),
+	)
+
+	GetRepoTool = mcp.NewTool(
+		GetRepoToolName,
+		mcp.WithDescription("Get repository details and settings"),
+		mcp.WithString("owner", mcp.Required(), mcp.Description("Repository owner")),
+		mcp.WithString("repo", mcp.Required(), mcp.Description("Repository name")),
+		to.OutputSchema[*gitea_sdk.Repository](),
+	)
+
+	EditRepoTool = mcp.NewTool(
+		EditRepoToolName,
+		mcp.WithDescription("Edit repository settings, only the given settings are changed"),
+		mcp.WithString("owner", mcp.Required(), mcp.Description("Repository owner")),
+		mcp.WithString("repo", mcp.Required(), mcp.Description("Repository name")),
+		mcp.WithString("description", mcp.Description("Description of the repository")),
+		mcp.WithString("website", mcp.Description("URL with more information about the repository")),
+		mcp.WithString("default_branch", mcp.Description("Default branch of the repository")),
+		mcp.WithBoolean("private", mcp.Description("Whether the repository is private")),
+		mcp.WithBoolean("has_issues", mcp.Description("Whether issues are enabled")),
+		mcp.WithBoolean("has_wiki", mcp.Description("Whether the wiki is enabled")),
+		mcp.WithBoolean("has_pull_requests", mcp.Description("Whether pull requests are enabled")),
+		mcp.WithBoolean("has_projects", mcp.Description("Whether projects are enabled")),
+		mcp.WithBoolean("allow_merge_commits", mcp.Description("Whether pull requests can be merged with a merge commit")),
+		mcp.WithBoolean("allow_rebase", mcp.Description("Whether pull requests can be rebased")),
+		mcp.WithBoolean("allow_rebase_explicit", mcp.Description("Whether pull requests can be rebased with a merge commit")),
+		mcp.WithBoolean("allow_squash_merge", mcp.Description("Whether pull requests can be squashed")),
+		mcp.WithBoolean("allow_fast_forward_only_merge", mcp.Description("Whether pull requests can be merged by fast-forward only")),
+		mcp.WithString("default_merge_style", mcp.Description("Merge style preselected when merging pull requests"), mcp.Enum("merge", "rebase", "rebase-merge", "squash", "fast-forward-only")),
+		mcp.WithBoolean("archived", mcp.Description("Whether the repository is archived, which makes it read-only")),
+		to.OutputSchema[*gitea_sdk.Repository](),
+	)
+
+	TransferRepoTool = mcp.NewTool(
+		TransferRepoToolName,
+		mcp.WithDescription("Transfer repository ownership to another user or organization"),
+		mcp.WithString("owner", mcp.Required(), mcp.Description("Repository owner")),
+		mcp.WithString("repo", mcp.Required(), mcp.Description("Repository name")),
+		mcp.WithString("new_owner", mcp.Required(), mcp.Description("User or organization to transfer the repository to")),
+		mcp.WithArray("team_ids", mcp.Description("IDs of the new owner's teams to give access, organizations only"), mcp.Items(map[string]interface{}{"type": "number"})),
+		to.OutputSchema[*gitea_sdk.Repository](),
 	)
 )
 
@@ -74,7 +122,7 @@ func init() {
 	Tool.RegisterWrite(server.ServerTool{
 		Tool:    ForkRepoTool,
 		Handler: ForkRepoFn,
-	})
+	}, tool.Alias("user", "owner"))
 	Tool.RegisterWrite(server.ServerTool{
 		Tool:    DeleteRepoTool,
 		Handler: DeleteRepoFn,
@@ -82,7 +130,19 @@ func init() {
 	Tool.RegisterRead(server.ServerTool{
 		Tool:    ListMyReposTool,
 		Handler: ListMyReposFn,
+	}, tool.Alias("pageSize", "page_size"))
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    GetRepoTool,
+		Handler: GetRepoFn,
+	})
+	Tool.RegisterWrite(server.ServerTool{
+		Tool:    EditRepoTool,
+		Handler: EditRepoFn,
 	})
+	Tool.RegisterWrite(server.ServerTool{
+		Tool:    TransferRepoTool,
+		Handler: TransferRepoFn,
+	}, tool.MinVersion("1.12"))
 }
 
 func RegisterTool(s *server.MCPServer) {
@@ -152,24 +212,24 @@ func CreateRepoFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRe
 	var repo *gitea_sdk.Repository
 	var err error
 	if organization != "" {
-		repo, _, err = gitea.Client().CreateOrgRepo(organization, opt)
+		repo, _, err = gitea.ClientFromContext(ctx).CreateOrgRepo(organization, opt)
 		if err != nil {
 			return to.ErrorResult(fmt.Errorf("create organization repository '%s' in '%s' err: %v", name, organization, err))
 		}
 	} else {
-		repo, _, err = gitea.Client().CreateRepo(opt)
+		repo, _, err = gitea.ClientFromContext(ctx).CreateRepo(opt)
 		if err != nil {
 			return to.ErrorResult(fmt.Errorf("create repository '%s' err: %v", name, err))
 		}
 	}
-	return to.TextResult(repo)
+	return to.Result(repo)
 }
 
 func ForkRepoFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	log.Debugf("Called ForkRepoFn")
-	user, ok := req.GetArguments()["user"].(string)
+	owner, ok := req.GetArguments()["owner"].(string)
 	if !ok {
-		return to.ErrorResult(errors.New("user name is required"))
+		return to.ErrorResult(errors.New("owner is required"))
 	}
 	repo, ok := req.GetArguments()["repo"].(string)
 	if !ok {
@@ -189,11 +249,11 @@ func ForkRepoFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResu
 		Organization: organizationPtr,
 		Name:         namePtr,
 	}
-	_, _, err := gitea.Client().CreateFork(user, repo, opt)
+	_, _, err := gitea.ClientFromContext(ctx).CreateFork(owner, repo, opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("fork repository error: %v", err))
 	}
-	return to.TextResult("Fork success")
+	return to.Result("Fork success")
 }
 
 func ListMyReposFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -202,7 +262,7 @@ func ListMyReposFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 	if !ok {
 		page = 1
 	}
-	pageSize, ok := req.GetArguments()["pageSize"].(float64)
+	pageSize, ok := req.GetArguments()["page_size"].(float64)
 	if !ok {
 		pageSize = 100
 	}
@@ -212,12 +272,12 @@ func ListMyReposFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 			PageSize: int(pageSize),
 		},
 	}
-	repos, _, err := gitea.Client().ListMyRepos(opt)
+	repos, _, err := gitea.ClientFromContext(ctx).ListMyRepos(opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("list my repositories error: %v", err))
 	}
 
-	return to.TextResult(repos)
+	return to.Result(repos)
 }
 
 func DeleteRepoFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -231,9 +291,118 @@ func DeleteRepoFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRe
 		return to.ErrorResult(errors.New("repository name is required"))
 	}
 
-	_, err := gitea.Client().DeleteRepo(owner, repo)
+	_, err := gitea.ClientFromContext(ctx).DeleteRepo(owner, repo)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("delete repository '%s/%s' error: %v", owner, repo, err))
 	}
-	return to.TextResult("Repository deleted successfully")
+	return to.Result("Repository deleted successfully")
+}
+
+func GetRepoFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called GetRepoFn")
+	owner, ok := req.GetArguments()["owner"].(string)
+	if !ok {
+		return to.ErrorResult(errors.New("owner is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(errors.New("repository name is required"))
+	}
+
+	repository, _, err := gitea.ClientFromContext(ctx).GetRepo(owner, repo)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get repository '%s/%s' error: %v", owner, repo, err))
+	}
+	return to.Result(repository)
+}
+
+func EditRepoFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called EditRepoFn")
+	args := req.GetArguments()
+	owner, ok := args["owner"].(string)
+	if !ok {
+		return to.ErrorResult(errors.New("owner is required"))
+	}
+	repo, ok := args["repo"].(string)
+	if !ok {
+		return to.ErrorResult(errors.New("repository name is required"))
+	}
+	boolArg := func(name string) *bool {
+		if v, ok := args[name].(bool); ok {
+			return &v
+		}
+		return nil
+	}
+	stringArg := func(name string) *string {
+		if v, ok := args[name].(string); ok {
+			return &v
+		}
+		return nil
+	}
+
+	opt := gitea_sdk.EditRepoOption{
+		Description:               stringArg("description"),
+		Website:                   stringArg("website"),
+		DefaultBranch:             stringArg("default_branch"),
+		Private:                   boolArg("private"),
+		HasIssues:                 boolArg("has_issues"),
+		HasWiki:                   boolArg("has_wiki"),
+		HasPullRequests:           boolArg("has_pull_requests"),
+		HasProjects:               boolArg("has_projects"),
+		AllowMerge:                boolArg("allow_merge_commits"),
+		AllowRebase:               boolArg("allow_rebase"),
+		AllowRebaseMerge:          boolArg("allow_rebase_explicit"),
+		AllowSquash:               boolArg("allow_squash_merge"),
+		AllowFastForwardOnlyMerge: boolArg("allow_fast_forward_only_merge"),
+		Archived:                  boolArg("archived"),
+	}
+	// Fast-forward-only merges came with Gitea 1.21, older versions ignore
+	// the setting.
+	if opt.AllowFastForwardOnlyMerge != nil || args["default_merge_style"] == "fast-forward-only" {
+		if err := gitea.RequireVersion(ctx, "1.21"); err != nil {
+			return to.ErrorResult(fmt.Errorf("fast-forward-only merges %v", err))
+		}
+	}
+	if style, ok := args["default_merge_style"].(string); ok {
+		opt.DefaultMergeStyle = ptr.To(gitea_sdk.MergeStyle(style))
+	}
+	repository, _, err := gitea.ClientFromContext(ctx).EditRepo(owner, repo, opt)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("edit repository '%s/%s' error: %v", owner, repo, err))
+	}
+	return to.Result(repository)
+}
+
+func TransferRepoFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called TransferRepoFn")
+	owner, ok := req.GetArguments()["owner"].(string)
+	if !ok {
+		return to.ErrorResult(errors.New("owner is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(errors.New("repository name is required"))
+	}
+	newOwner, ok := req.GetArguments()["new_owner"].(string)
+	if !ok || newOwner == "" {
+		return to.ErrorResult(errors.New("new_owner is required"))
+	}
+	opt := gitea_sdk.TransferRepoOption{NewOwner: newOwner}
+	if ids, ok := req.GetArguments()["team_ids"].([]any); ok {
+		teamIDs := make([]int64, 0, len(ids))
+		for _, id := range ids {
+			teamID, ok := id.(float64)
+			if !ok {
+				return to.ErrorResult(errors.New("invalid team ID in team_ids array"))
+			}
+			teamIDs = append(teamIDs, int64(teamID))
+		}
+		opt.TeamIDs = &teamIDs
+	}
+
+	repository, _, err := gitea.ClientFromContext(ctx).TransferRepo(owner, repo, opt)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("transfer repository '%s/%s' to '%s' error: %v", owner, repo, newOwner, err))
+	}
+	return to.Result(repository)
 }
@@ -0,0 +1,178 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"gitea.com/gitea/gitea-mcp/operation/pull"
+	"gitea.com/gitea/gitea-mcp/pkg/gitea"
+	"gitea.com/gitea/gitea-mcp/pkg/log"
+	"gitea.com/gitea/gitea-mcp/pkg/to"
+
+	gitea_sdk "code.gitea.io/sdk/gitea"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	ListCommitStatusesToolName = "list_commit_statuses"
+	GetCombinedStatusToolName  = "get_combined_status"
+	CreateCommitStatusToolName = "create_commit_status"
+)
+
+var (
+	ListCommitStatusesTool = mcp.NewTool(
+		ListCommitStatusesToolName,
+		mcp.WithDescription("List the statuses reported for a commit, newest first, by ref or by pull request"),
+		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
+		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+		mcp.WithString("ref", mcp.Description("branch, tag or commit SHA, required unless index is given")),
+		mcp.WithNumber("index", mcp.Description("pull request index whose head commit to use instead of ref")),
+		mcp.WithNumber("page", mcp.Description("page number"), mcp.DefaultNumber(1), mcp.Min(1)),
+		mcp.WithNumber("page_size", mcp.Description("page size"), mcp.DefaultNumber(50), mcp.Min(1)),
+		to.OutputSchema[[]*gitea_sdk.Status](),
+	)
+
+	GetCombinedStatusTool = mcp.NewTool(
+		GetCombinedStatusToolName,
+		mcp.WithDescription("Get the combined state of the latest status of each context for a commit, by ref or by pull request"),
+		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
+		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+		mcp.WithString("ref", mcp.Description("branch, tag or commit SHA, required unless index is given")),
+		mcp.WithNumber("index", mcp.Description("pull request index whose head commit to use instead of ref")),
+		to.OutputSchema[*gitea_sdk.CombinedStatus](),
+	)
+
+	CreateCommitStatusTool = mcp.NewTool(
+		CreateCommitStatusToolName,
+		mcp.WithDescription("Report a status, such as a check result, for a commit or the head commit of a pull request"),
+		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
+		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+		mcp.WithString("sha", mcp.Description("commit SHA, required unless index is given")),
+		mcp.WithNumber("index", mcp.Description("pull request index whose head commit to use instead of sha")),
+		mcp.WithString("state", mcp.Required(), mcp.Description("status state"), mcp.Enum("pending", "success", "error", "failure", "warning")),
+		mcp.WithString("context", mcp.Description("name of the check, statuses of the same context replace each other"), mcp.DefaultString("default")),
+		mcp.WithString("description", mcp.Description("short description of the status")),
+		mcp.WithString("target_url", mcp.Description("URL with details of the status")),
+		to.OutputSchema[*gitea_sdk.Status](),
+	)
+)
+
+func init() {
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    ListCommitStatusesTool,
+		Handler: ListCommitStatusesFn,
+	})
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    GetCombinedStatusTool,
+		Handler: GetCombinedStatusFn,
+	})
+	Tool.RegisterWrite(server.ServerTool{
+		Tool:    CreateCommitStatusTool,
+		Handler: CreateCommitStatusFn,
+	})
+}
+
+// statusRef returns the commit a status tool addresses: the head of pull
+// request index if given, otherwise the argument named key.
+func statusRef(ctx context.Context, req mcp.CallToolRequest, owner, repo, key string) (string, error) {
+	if index, ok := req.GetArguments()["index"].(float64); ok {
+		return pull.HeadSHA(ctx, owner, repo, int64(index))
+	}
+	ref, ok := req.GetArguments()[key].(string)
+	if !ok || ref == "" {
+		return "", fmt.Errorf("%s or index is required", key)
+	}
+	return ref, nil
+}
+
+func ListCommitStatusesFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListCommitStatusesFn")
+	owner, ok := req.GetArguments()["owner"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("owner is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("repo is required"))
+	}
+	page, ok := req.GetArguments()["page"].(float64)
+	if !ok {
+		page = 1
+	}
+	pageSize, ok := req.GetArguments()["page_size"].(float64)
+	if !ok {
+		pageSize = 50
+	}
+	ref, err := statusRef(ctx, req, owner, repo, "ref")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	statuses, _, err := gitea.ClientFromContext(ctx).ListStatuses(owner, repo, ref, gitea_sdk.ListStatusesOption{
+		ListOptions: gitea_sdk.ListOptions{
+			Page:     int(page),
+			PageSize: int(pageSize),
+		},
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list statuses of %v err: %v", ref, err))
+	}
+	return to.Result(statuses)
+}
+
+func GetCombinedStatusFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called GetCombinedStatusFn")
+	owner, ok := req.GetArguments()["owner"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("owner is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("repo is required"))
+	}
+	ref, err := statusRef(ctx, req, owner, repo, "ref")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	status, _, err := gitea.ClientFromContext(ctx).GetCombinedStatus(owner, repo, ref)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get combined status of %v err: %v", ref, err))
+	}
+	return to.Result(status)
+}
+
+func CreateCommitStatusFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called CreateCommitStatusFn")
+	owner, ok := req.GetArguments()["owner"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("owner is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("repo is required"))
+	}
+	state, ok := req.GetArguments()["state"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("state is required"))
+	}
+	statusContext, ok := req.GetArguments()["context"].(string)
+	if !ok {
+		statusContext = "default"
+	}
+	description, _ := req.GetArguments()["description"].(string)
+	targetURL, _ := req.GetArguments()["target_url"].(string)
+	sha, err := statusRef(ctx, req, owner, repo, "sha")
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	status, _, err := gitea.ClientFromContext(ctx).CreateStatus(owner, repo, sha, gitea_sdk.CreateStatusOption{
+		State:       gitea_sdk.StatusState(state),
+		TargetURL:   targetURL,
+		Description: description,
+		Context:     statusContext,
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("create status of %v err: %v", sha, err))
+	}
+	return to.Result(status)
+}
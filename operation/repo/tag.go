@@ -7,6 +7,7 @@ import (
 	"gitea.com/gitea/gitea-mcp/pkg/gitea"
 	"gitea.com/gitea/gitea-mcp/pkg/log"
 	"gitea.com/gitea/gitea-mcp/pkg/to"
+	"gitea.com/gitea/gitea-mcp/pkg/tool"
 
 	gitea_sdk "code.gitea.io/sdk/gitea"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -26,9 +27,10 @@ var (
 		mcp.WithDescription("Create tag"),
 		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
-		mcp.WithString("tag_name", mcp.Required(), mcp.Description("tag name")),
+		mcp.WithString("tag", mcp.Required(), mcp.Description("tag name")),
 		mcp.WithString("target", mcp.Description("target commitish"), mcp.DefaultString("")),
 		mcp.WithString("message", mcp.Description("tag message"), mcp.DefaultString("")),
+		to.OutputSchema[string](),
 	)
 
 	DeleteTagTool = mcp.NewTool(
@@ -36,7 +38,8 @@ var (
 		mcp.WithDescription("Delete tag"),
 		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
-		mcp.WithString("tag_name", mcp.Required(), mcp.Description("tag name")),
+		mcp.WithString("tag", mcp.Required(), mcp.Description("tag name")),
+		to.OutputSchema[string](),
 	)
 
 	GetTagTool = mcp.NewTool(
@@ -44,7 +47,8 @@ var (
 		mcp.WithDescription("Get tag"),
 		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
-		mcp.WithString("tag_name", mcp.Required(), mcp.Description("tag name")),
+		mcp.WithString("tag", mcp.Required(), mcp.Description("tag name")),
+		to.OutputSchema[*gitea_sdk.Tag](),
 	)
 
 	ListTagsTool = mcp.NewTool(
@@ -53,7 +57,8 @@ var (
 		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
 		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
 		mcp.WithNumber("page", mcp.Description("page number"), mcp.DefaultNumber(1), mcp.Min(1)),
-		mcp.WithNumber("pageSize", mcp.Description("page size"), mcp.DefaultNumber(20), mcp.Min(1)),
+		mcp.WithNumber("page_size", mcp.Description("page size"), mcp.DefaultNumber(20), mcp.Min(1)),
+		to.OutputSchema[[]ListTagResult](),
 	)
 )
 
@@ -61,19 +66,19 @@ func init() {
 	Tool.RegisterWrite(server.ServerTool{
 		Tool:    CreateTagTool,
 		Handler: CreateTagFn,
-	})
+	}, tool.Alias("tag_name", "tag"), tool.MinVersion("1.15"))
 	Tool.RegisterWrite(server.ServerTool{
 		Tool:    DeleteTagTool,
 		Handler: DeleteTagFn,
-	})
+	}, tool.Alias("tag_name", "tag"), tool.MinVersion("1.14"))
 	Tool.RegisterRead(server.ServerTool{
 		Tool:    GetTagTool,
 		Handler: GetTagFn,
-	})
+	}, tool.Alias("tag_name", "tag"), tool.MinVersion("1.15"))
 	Tool.RegisterRead(server.ServerTool{
 		Tool:    ListTagsTool,
 		Handler: ListTagsFn,
-	})
+	}, tool.Alias("pageSize", "page_size"))
 }
 
 // To avoid return too many tokens, we need to provide at least information as possible
@@ -95,14 +100,14 @@ func CreateTagFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRes
 	if !ok {
 		return nil, fmt.Errorf("repo is required")
 	}
-	tagName, ok := req.GetArguments()["tag_name"].(string)
+	tagName, ok := req.GetArguments()["tag"].(string)
 	if !ok {
-		return nil, fmt.Errorf("tag_name is required")
+		return nil, fmt.Errorf("tag is required")
 	}
 	target, _ := req.GetArguments()["target"].(string)
 	message, _ := req.GetArguments()["message"].(string)
 
-	_, _, err := gitea.Client().CreateTag(owner, repo, gitea_sdk.CreateTagOption{
+	_, _, err := gitea.ClientFromContext(ctx).CreateTag(owner, repo, gitea_sdk.CreateTagOption{
 		TagName: tagName,
 		Target:  target,
 		Message: message,
@@ -111,7 +116,7 @@ func CreateTagFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRes
 		return nil, fmt.Errorf("create tag error: %v", err)
 	}
 
-	return mcp.NewToolResultText("Tag Created"), nil
+	return to.Result("Tag Created")
 }
 
 func DeleteTagFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -124,17 +129,17 @@ func DeleteTagFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolRes
 	if !ok {
 		return nil, fmt.Errorf("repo is required")
 	}
-	tagName, ok := req.GetArguments()["tag_name"].(string)
+	tagName, ok := req.GetArguments()["tag"].(string)
 	if !ok {
-		return nil, fmt.Errorf("tag_name is required")
+		return nil, fmt.Errorf("tag is required")
 	}
 
-	_, err := gitea.Client().DeleteTag(owner, repo, tagName)
+	_, err := gitea.ClientFromContext(ctx).DeleteTag(owner, repo, tagName)
 	if err != nil {
 		return nil, fmt.Errorf("delete tag error: %v", err)
 	}
 
-	return to.TextResult("Tag deleted")
+	return to.Result("Tag deleted")
 }
 
 func GetTagFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -147,17 +152,17 @@ func GetTagFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult
 	if !ok {
 		return nil, fmt.Errorf("repo is required")
 	}
-	tagName, ok := req.GetArguments()["tag_name"].(string)
+	tagName, ok := req.GetArguments()["tag"].(string)
 	if !ok {
-		return nil, fmt.Errorf("tag_name is required")
+		return nil, fmt.Errorf("tag is required")
 	}
 
-	tag, _, err := gitea.Client().GetTag(owner, repo, tagName)
+	tag, _, err := gitea.ClientFromContext(ctx).GetTag(owner, repo, tagName)
 	if err != nil {
 		return nil, fmt.Errorf("get tag error: %v", err)
 	}
 
-	return to.TextResult(tag)
+	return to.Result(tag)
 }
 
 func ListTagsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -171,9 +176,9 @@ func ListTagsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResu
 		return nil, fmt.Errorf("repo is required")
 	}
 	page, _ := req.GetArguments()["page"].(float64)
-	pageSize, _ := req.GetArguments()["pageSize"].(float64)
+	pageSize, _ := req.GetArguments()["page_size"].(float64)
 
-	tags, _, err := gitea.Client().ListRepoTags(owner, repo, gitea_sdk.ListRepoTagsOptions{
+	tags, _, err := gitea.ClientFromContext(ctx).ListRepoTags(owner, repo, gitea_sdk.ListRepoTagsOptions{
 		ListOptions: gitea_sdk.ListOptions{
 			Page:     int(page),
 			PageSize: int(pageSize),
@@ -191,5 +196,5 @@ func ListTagsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResu
 			Commit: tag.Commit,
 		})
 	}
-	return to.TextResult(results)
+	return to.Result(results)
 }
@@ -0,0 +1,157 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"gitea.com/gitea/gitea-mcp/pkg/gitea"
+	"gitea.com/gitea/gitea-mcp/pkg/log"
+	"gitea.com/gitea/gitea-mcp/pkg/to"
+
+	gitea_sdk "code.gitea.io/sdk/gitea"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	ListRepoTopicsToolName  = "list_repo_topics"
+	SetRepoTopicsToolName   = "set_repo_topics"
+	AddRepoTopicToolName    = "add_repo_topic"
+	DeleteRepoTopicToolName = "delete_repo_topic"
+)
+
+var (
+	ListRepoTopicsTool = mcp.NewTool(
+		ListRepoTopicsToolName,
+		mcp.WithDescription("List the topics of a repository"),
+		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
+		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+		to.OutputSchema[[]string](),
+	)
+
+	SetRepoTopicsTool = mcp.NewTool(
+		SetRepoTopicsToolName,
+		mcp.WithDescription("Replace all topics of a repository, an empty list removes them"),
+		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
+		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+		mcp.WithArray("topics", mcp.Required(), mcp.Description("topics, lowercase letters, digits, dashes and dots"), mcp.Items(map[string]interface{}{"type": "string"})),
+		to.OutputSchema[string](),
+	)
+
+	AddRepoTopicTool = mcp.NewTool(
+		AddRepoTopicToolName,
+		mcp.WithDescription("Add a topic to a repository"),
+		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
+		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+		mcp.WithString("topic", mcp.Required(), mcp.Description("topic, lowercase letters, digits, dashes and dots")),
+		to.OutputSchema[string](),
+	)
+
+	DeleteRepoTopicTool = mcp.NewTool(
+		DeleteRepoTopicToolName,
+		mcp.WithDescription("Remove a topic from a repository"),
+		mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
+		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+		mcp.WithString("topic", mcp.Required(), mcp.Description("topic to remove")),
+		to.OutputSchema[string](),
+	)
+)
+
+func init() {
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    ListRepoTopicsTool,
+		Handler: ListRepoTopicsFn,
+	})
+	Tool.RegisterWrite(server.ServerTool{
+		Tool:    SetRepoTopicsTool,
+		Handler: SetRepoTopicsFn,
+	})
+	Tool.RegisterWrite(server.ServerTool{
+		Tool:    AddRepoTopicTool,
+		Handler: AddRepoTopicFn,
+	})
+	Tool.RegisterWrite(server.ServerTool{
+		Tool:    DeleteRepoTopicTool,
+		Handler: DeleteRepoTopicFn,
+	})
+}
+
+func ListRepoTopicsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListRepoTopicsFn")
+	owner, ok := req.GetArguments()["owner"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("owner is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("repo is required"))
+	}
+	topics, _, err := gitea.ClientFromContext(ctx).ListRepoTopics(owner, repo, gitea_sdk.ListRepoTopicsOptions{})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list topics of %v/%v err: %v", owner, repo, err))
+	}
+	return to.Result(topics)
+}
+
+func SetRepoTopicsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called SetRepoTopicsFn")
+	owner, ok := req.GetArguments()["owner"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("owner is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("repo is required"))
+	}
+	if _, ok := req.GetArguments()["topics"]; !ok {
+		return to.ErrorResult(fmt.Errorf("topics is required"))
+	}
+	topics := stringArgs(req.GetArguments()["topics"])
+	_, err := gitea.ClientFromContext(ctx).SetRepoTopics(owner, repo, topics)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("set topics of %v/%v err: %v", owner, repo, err))
+	}
+	return to.Result("Topics set successfully")
+}
+
+func AddRepoTopicFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called AddRepoTopicFn")
+	owner, ok := req.GetArguments()["owner"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("owner is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("repo is required"))
+	}
+	topic, ok := req.GetArguments()["topic"].(string)
+	if !ok || topic == "" {
+		return to.ErrorResult(fmt.Errorf("topic is required"))
+	}
+	_, err := gitea.ClientFromContext(ctx).AddRepoTopic(owner, repo, topic)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("add topic %v err: %v", topic, err))
+	}
+	return to.Result("Topic added successfully")
+}
+
+func DeleteRepoTopicFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called DeleteRepoTopicFn")
+	owner, ok := req.GetArguments()["owner"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("owner is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("repo is required"))
+	}
+	topic, ok := req.GetArguments()["topic"].(string)
+	if !ok || topic == "" {
+		return to.ErrorResult(fmt.Errorf("topic is required"))
+	}
+	_, err := gitea.ClientFromContext(ctx).DeleteRepoTopic(owner, repo, topic)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("delete topic %v err: %v", topic, err))
+	}
+	return to.Result("Topic deleted successfully")
+}
@@ -0,0 +1,186 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"gitea.com/gitea/gitea-mcp/pkg/gitea"
+	"gitea.com/gitea/gitea-mcp/pkg/glob"
+	"gitea.com/gitea/gitea-mcp/pkg/log"
+	"gitea.com/gitea/gitea-mcp/pkg/to"
+
+	gitea_sdk "code.gitea.io/sdk/gitea"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const GetRepoTreeToolName = "get_repo_tree"
+
+var GetRepoTreeTool = mcp.NewTool(
+	GetRepoTreeToolName,
+	mcp.WithDescription("List the files and directories of a repository recursively, optionally below a path and filtered by globs"),
+	mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
+	mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+	mcp.WithString("ref", mcp.Description("branch, tag or commit, defaults to the default branch")),
+	mcp.WithString("path", mcp.Description("directory to list, defaults to the repository root")),
+	mcp.WithArray("include", mcp.Description("only list entries matching one of these globs, such as *.go or src/**; a glob without a slash matches names at any depth"), mcp.Items(map[string]interface{}{"type": "string"})),
+	mcp.WithArray("exclude", mcp.Description("leave out entries matching one of these globs"), mcp.Items(map[string]interface{}{"type": "string"})),
+	mcp.WithNumber("max_depth", mcp.Description("directory levels below path to list, 0 for all"), mcp.DefaultNumber(0), mcp.Min(0)),
+	mcp.WithNumber("max_entries", mcp.Description("maximum number of entries to return"), mcp.DefaultNumber(1000), mcp.Min(1)),
+	mcp.WithNumber("page", mcp.Description("page of the tree"), mcp.DefaultNumber(1), mcp.Min(1)),
+	mcp.WithNumber("page_size", mcp.Description("tree entries per page, before filtering"), mcp.DefaultNumber(1000), mcp.Min(1)),
+	to.OutputSchema[RepoTree](),
+)
+
+func init() {
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    GetRepoTreeTool,
+		Handler: GetRepoTreeFn,
+	})
+}
+
+// RepoTree is a page of the tree listed by get_repo_tree.
+type RepoTree struct {
+	// SHA is the tree object of path.
+	SHA     string      `json:"sha"`
+	Entries []TreeEntry `json:"entries"`
+	Page    int         `json:"page"`
+	// TotalCount is the number of entries in the tree before filtering.
+	TotalCount int `json:"total_count"`
+	// Truncated is set if Gitea has more entries on later pages.
+	Truncated bool `json:"truncated"`
+	// Capped is set if max_entries left out entries of this page.
+	Capped bool `json:"capped"`
+}
+
+// TreeEntry is a file ("blob"), directory ("tree") or submodule ("commit").
+type TreeEntry struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+	Size int64  `json:"size,omitempty"`
+	SHA  string `json:"sha"`
+}
+
+// GetRepoTreeFn is the handler for "get_repo_tree" MCP tool requests. It
+// lists a page of the git tree below path in one request and filters it by
+// depth and globs.
+func GetRepoTreeFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called GetRepoTreeFn")
+	owner, ok := req.GetArguments()["owner"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("owner is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("repo is required"))
+	}
+	ref, _ := req.GetArguments()["ref"].(string)
+	dir, _ := req.GetArguments()["path"].(string)
+	dir = strings.Trim(dir, "/")
+	include := stringArgs(req.GetArguments()["include"])
+	exclude := stringArgs(req.GetArguments()["exclude"])
+	maxDepth, _ := req.GetArguments()["max_depth"].(float64)
+	maxEntries, ok := req.GetArguments()["max_entries"].(float64)
+	if !ok {
+		maxEntries = 1000
+	}
+	page, ok := req.GetArguments()["page"].(float64)
+	if !ok {
+		page = 1
+	}
+	pageSize, ok := req.GetArguments()["page_size"].(float64)
+	if !ok {
+		pageSize = 1000
+	}
+
+	client := gitea.ClientFromContext(ctx)
+	if ref == "" {
+		r, _, err := client.GetRepo(owner, repo)
+		if err != nil {
+			return to.ErrorResult(fmt.Errorf("get %v/%v err: %v", owner, repo, err))
+		}
+		ref = r.DefaultBranch
+	}
+	// The trees API lists a tree object, so a subdirectory is looked up in
+	// its parent first.
+	sha := ref
+	if dir != "" {
+		parent := path.Dir(dir)
+		if parent == "." {
+			parent = ""
+		}
+		entries, _, err := client.ListContents(owner, repo, ref, parent)
+		if err != nil {
+			return to.ErrorResult(fmt.Errorf("get %v/%v/%v err: %v", owner, repo, dir, err))
+		}
+		sha = ""
+		for _, entry := range entries {
+			if entry.Path == dir && entry.Type == "dir" {
+				sha = entry.SHA
+			}
+		}
+		if sha == "" {
+			return to.ErrorResult(fmt.Errorf("%v is not a directory of %v/%v at %v", dir, owner, repo, ref))
+		}
+	}
+
+	// The SDK sends the page size as limit, which the trees API ignores.
+	query := url.Values{}
+	query.Set("page", fmt.Sprint(int(page)))
+	query.Set("per_page", fmt.Sprint(int(pageSize)))
+	if maxDepth != 1 {
+		query.Set("recursive", "true")
+	}
+	var tree gitea_sdk.GitTreeResponse
+	err := gitea.Do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/%s/git/trees/%s?%s", url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(sha), query.Encode()), nil, &tree)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get %v/%v tree err: %v", owner, repo, err))
+	}
+
+	result := RepoTree{
+		SHA:        tree.SHA,
+		Entries:    []TreeEntry{},
+		Page:       tree.Page,
+		TotalCount: tree.TotalCount,
+		Truncated:  tree.Truncated,
+	}
+	for _, entry := range tree.Entries {
+		if maxDepth > 0 && strings.Count(entry.Path, "/") >= int(maxDepth) {
+			continue
+		}
+		p := entry.Path
+		if dir != "" {
+			p = dir + "/" + p
+		}
+		if len(include) > 0 && !glob.MatchAny(include, p) || glob.MatchAny(exclude, p) {
+			continue
+		}
+		if len(result.Entries) == int(maxEntries) {
+			result.Capped = true
+			break
+		}
+		result.Entries = append(result.Entries, TreeEntry{
+			Path: p,
+			Type: entry.Type,
+			Size: entry.Size,
+			SHA:  entry.SHA,
+		})
+	}
+	return to.Result(result)
+}
+
+// stringArgs returns the strings of an array argument.
+func stringArgs(v any) []string {
+	items, _ := v.([]any)
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list
+}
@@ -0,0 +1,281 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"gitea.com/gitea/gitea-mcp/operation/repo"
+	"gitea.com/gitea/gitea-mcp/pkg/gitea"
+	"gitea.com/gitea/gitea-mcp/pkg/glob"
+	"gitea.com/gitea/gitea-mcp/pkg/log"
+	"gitea.com/gitea/gitea-mcp/pkg/to"
+	"gitea.com/gitea/gitea-mcp/pkg/tool"
+
+	gitea_sdk "code.gitea.io/sdk/gitea"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const SearchCodeToolName = "search_code"
+
+const (
+	// maxCodeSearchResults caps max_results.
+	maxCodeSearchResults = 200
+	// maxCodeSearchFiles is the number of files read at most per search.
+	maxCodeSearchFiles = 500
+	// maxCodeSearchFileSize is the size of the largest file searched.
+	maxCodeSearchFileSize = 1 << 20
+	// maxCodeSearchTreePages bounds the tree listing, Gitea returns 1000
+	// entries per page.
+	maxCodeSearchTreePages = 10
+	// maxCodeSearchContext caps context_lines.
+	maxCodeSearchContext = 20
+	// codeSearchWorkers is the number of files read concurrently.
+	codeSearchWorkers = 8
+)
+
+var SearchCodeTool = mcp.NewTool(
+	SearchCodeToolName,
+	mcp.WithDescription("Search the files of a repository at a ref for a text or regular expression and return the matching lines with context. Gitea's code indexer is not available through its API, so every search reads the files of the tree, up to 500 files of at most 1 MiB each"),
+	mcp.WithString("owner", mcp.Required(), mcp.Description("repository owner")),
+	mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+	mcp.WithString("query", mcp.Required(), mcp.Description("text to search for, or a regular expression if regexp is set")),
+	mcp.WithString("ref", mcp.Description("branch, tag or commit to search, defaults to the default branch")),
+	mcp.WithArray("paths", mcp.Description("only search files matching one of these globs, such as *.go or docs/**; a glob without a slash matches file names at any depth"), mcp.Items(map[string]interface{}{"type": "string"})),
+	mcp.WithBoolean("regexp", mcp.Description("treat query as a regular expression")),
+	mcp.WithBoolean("case_sensitive", mcp.Description("match case, searches ignore case by default")),
+	mcp.WithNumber("context_lines", mcp.Description("lines of context before and after each match"), mcp.DefaultNumber(2), mcp.Min(0), mcp.Max(maxCodeSearchContext)),
+	mcp.WithNumber("max_results", mcp.Description("maximum number of matches to return"), mcp.DefaultNumber(50), mcp.Min(1), mcp.Max(maxCodeSearchResults)),
+	to.OutputSchema[CodeSearchResult](),
+)
+
+func init() {
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    SearchCodeTool,
+		Handler: SearchCodeFn,
+	}, tool.Scope(gitea.ScopeRepository))
+}
+
+// CodeSearchResult is the result of search_code.
+type CodeSearchResult struct {
+	Matches []CodeMatch `json:"matches"`
+	// Truncated is set if the search stopped before covering every file,
+	// because of max_results or the limits on the files read.
+	Truncated bool `json:"truncated"`
+	// Skipped are the files that were not searched.
+	Skipped []SkippedFile `json:"skipped,omitempty"`
+}
+
+// SkippedFile is a file search_code could not search, with the reason:
+// "too large", "binary", "lfs" for a Git LFS pointer, or the error reading
+// it.
+type SkippedFile struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// CodeMatch is a matching line with the lines around it.
+type CodeMatch struct {
+	Path  string             `json:"path"`
+	Line  int                `json:"line"`
+	Lines []repo.ContentLine `json:"lines"`
+}
+
+// SearchCodeFn is the handler for "search_code" MCP tool requests. Gitea's API
+// does not expose its code indexer, so the files of the tree are fetched and
+// searched here, within the limits above. Files that cannot be read are
+// skipped rather than failing the search.
+func SearchCodeFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called SearchCodeFn")
+	owner, ok := req.GetArguments()["owner"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("owner is required"))
+	}
+	repoName, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("repo is required"))
+	}
+	query, ok := req.GetArguments()["query"].(string)
+	if !ok || query == "" {
+		return to.ErrorResult(fmt.Errorf("query is required"))
+	}
+	ref, _ := req.GetArguments()["ref"].(string)
+	var globs []string
+	if paths, ok := req.GetArguments()["paths"].([]any); ok {
+		for _, p := range paths {
+			if pattern, ok := p.(string); ok {
+				globs = append(globs, pattern)
+			}
+		}
+	}
+	isRegexp, _ := req.GetArguments()["regexp"].(bool)
+	caseSensitive, _ := req.GetArguments()["case_sensitive"].(bool)
+	contextLines, ok := req.GetArguments()["context_lines"].(float64)
+	if !ok {
+		contextLines = 2
+	}
+	contextLines = min(max(contextLines, 0), maxCodeSearchContext)
+	maxResults, ok := req.GetArguments()["max_results"].(float64)
+	if !ok {
+		maxResults = 50
+	}
+	maxResults = min(max(maxResults, 1), maxCodeSearchResults)
+
+	pattern := regexp.QuoteMeta(query)
+	if isRegexp {
+		pattern = query
+	}
+	if !caseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("invalid query: %v", err))
+	}
+
+	client := gitea.ClientFromContext(ctx)
+	if ref == "" {
+		r, _, err := client.GetRepo(owner, repoName)
+		if err != nil {
+			return to.ErrorResult(fmt.Errorf("get %v/%v err: %v", owner, repoName, err))
+		}
+		ref = r.DefaultBranch
+	}
+
+	result := CodeSearchResult{Matches: []CodeMatch{}}
+	var (
+		files    []gitea_sdk.GitEntry
+		tooLarge []SkippedFile
+	)
+	for page := 1; ; page++ {
+		tree, _, err := client.GetTrees(owner, repoName, gitea_sdk.ListTreeOptions{
+			ListOptions: gitea_sdk.ListOptions{Page: page},
+			Ref:         ref,
+			Recursive:   true,
+		})
+		if err != nil {
+			return to.ErrorResult(fmt.Errorf("get %v/%v tree err: %v", owner, repoName, err))
+		}
+		for _, entry := range tree.Entries {
+			if entry.Type != "blob" || len(globs) > 0 && !glob.MatchAny(globs, entry.Path) {
+				continue
+			}
+			if entry.Size > maxCodeSearchFileSize {
+				tooLarge = append(tooLarge, SkippedFile{Path: entry.Path, Reason: "too large"})
+				continue
+			}
+			files = append(files, entry)
+		}
+		if !tree.Truncated {
+			break
+		}
+		if page == maxCodeSearchTreePages {
+			result.Truncated = true
+			break
+		}
+	}
+	if len(files) > maxCodeSearchFiles {
+		files = files[:maxCodeSearchFiles]
+		result.Truncated = true
+	}
+
+	search := func(p string) searchedFile {
+		content, _, err := client.GetFile(owner, repoName, ref, p)
+		switch {
+		case err != nil:
+			return searchedFile{path: p, skipped: err.Error()}
+		case bytes.IndexByte(content[:min(len(content), 8000)], 0) >= 0:
+			return searchedFile{path: p, skipped: "binary"}
+		case repo.IsLFSPointer(content):
+			return searchedFile{path: p, skipped: "lfs"}
+		}
+		return searchedFile{path: p, matches: grep(p, content, re, int(contextLines))}
+	}
+	result.Skipped = tooLarge
+	outcomes := searchFiles(ctx, files, int(maxResults), search)
+	if len(outcomes) < len(files) {
+		result.Truncated = true
+	}
+	for _, searched := range outcomes {
+		if searched.skipped != "" {
+			result.Skipped = append(result.Skipped, SkippedFile{Path: searched.path, Reason: searched.skipped})
+			continue
+		}
+		if remaining := int(maxResults) - len(result.Matches); len(searched.matches) > remaining {
+			result.Matches = append(result.Matches, searched.matches[:remaining]...)
+			result.Truncated = true
+			break
+		}
+		result.Matches = append(result.Matches, searched.matches...)
+	}
+	if ctx.Err() != nil {
+		return to.ErrorResult(fmt.Errorf("search %v/%v err: %v", owner, repoName, ctx.Err()))
+	}
+	return to.Result(result)
+}
+
+// searchedFile is the outcome of searching a file: its matches, or why it
+// was skipped.
+type searchedFile struct {
+	path    string
+	matches []CodeMatch
+	skipped string
+}
+
+// searchFiles searches files with codeSearchWorkers workers and returns the
+// outcomes in the order of files. Files are handed out in order and no more
+// once maxResults matches were found, so the outcomes cover a prefix of files
+// holding at least maxResults matches, or all of them.
+func searchFiles(ctx context.Context, files []gitea_sdk.GitEntry, maxResults int, search func(string) searchedFile) []searchedFile {
+	searched := make([]searchedFile, len(files))
+	var (
+		wg    sync.WaitGroup
+		found atomic.Int64
+		next  = make(chan int)
+	)
+	for range min(codeSearchWorkers, len(files)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				searched[i] = search(files[i].Path)
+				found.Add(int64(len(searched[i].matches)))
+			}
+		}()
+	}
+	n := 0
+	for n < len(files) && found.Load() < int64(maxResults) && ctx.Err() == nil {
+		next <- n
+		n++
+	}
+	close(next)
+	wg.Wait()
+	return searched[:n]
+}
+
+// grep returns the lines of content matching re with up to around lines
+// before and after each.
+func grep(p string, content []byte, re *regexp.Regexp, around int) []CodeMatch {
+	lines := strings.Split(string(content), "\n")
+	// git does not consider the last line as a new line
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	var matches []CodeMatch
+	for i, line := range lines {
+		if !re.MatchString(line) {
+			continue
+		}
+		m := CodeMatch{Path: p, Line: i + 1}
+		for j := max(i-around, 0); j <= min(i+around, len(lines)-1); j++ {
+			m.Lines = append(m.Lines, repo.ContentLine{LineNumber: j + 1, Content: lines[j]})
+		}
+		matches = append(matches, m)
+	}
+	return matches
+}
@@ -29,7 +29,8 @@ var (
 		mcp.WithDescription("search users"),
 		mcp.WithString("keyword", mcp.Description("Keyword")),
 		mcp.WithNumber("page", mcp.Description("Page"), mcp.DefaultNumber(1)),
-		mcp.WithNumber("pageSize", mcp.Description("PageSize"), mcp.DefaultNumber(100)),
+		mcp.WithNumber("page_size", mcp.Description("PageSize"), mcp.DefaultNumber(100)),
+		to.OutputSchema[[]*gitea_sdk.User](),
 	)
 
 	SearOrgTeamsTool = mcp.NewTool(
@@ -37,24 +38,26 @@ var (
 		mcp.WithDescription("search organization teams"),
 		mcp.WithString("org", mcp.Description("organization name")),
 		mcp.WithString("query", mcp.Description("search organization teams")),
-		mcp.WithBoolean("includeDescription", mcp.Description("include description?")),
+		mcp.WithBoolean("include_description", mcp.Description("include description?")),
 		mcp.WithNumber("page", mcp.Description("Page"), mcp.DefaultNumber(1)),
-		mcp.WithNumber("pageSize", mcp.Description("PageSize"), mcp.DefaultNumber(100)),
+		mcp.WithNumber("page_size", mcp.Description("PageSize"), mcp.DefaultNumber(100)),
+		to.OutputSchema[[]*gitea_sdk.Team](),
 	)
 
 	SearchReposTool = mcp.NewTool(
 		SearchReposToolName,
 		mcp.WithDescription("search repos"),
 		mcp.WithString("keyword", mcp.Description("Keyword")),
-		mcp.WithBoolean("keywordIsTopic", mcp.Description("KeywordIsTopic")),
-		mcp.WithBoolean("keywordInDescription", mcp.Description("KeywordInDescription")),
-		mcp.WithNumber("ownerID", mcp.Description("OwnerID")),
-		mcp.WithBoolean("isPrivate", mcp.Description("IsPrivate")),
-		mcp.WithBoolean("isArchived", mcp.Description("IsArchived")),
+		mcp.WithBoolean("keyword_is_topic", mcp.Description("KeywordIsTopic")),
+		mcp.WithBoolean("keyword_in_description", mcp.Description("KeywordInDescription")),
+		mcp.WithNumber("owner_id", mcp.Description("OwnerID")),
+		mcp.WithBoolean("is_private", mcp.Description("IsPrivate")),
+		mcp.WithBoolean("is_archived", mcp.Description("IsArchived")),
 		mcp.WithString("sort", mcp.Description("Sort")),
 		mcp.WithString("order", mcp.Description("Order")),
 		mcp.WithNumber("page", mcp.Description("Page"), mcp.DefaultNumber(1)),
-		mcp.WithNumber("pageSize", mcp.Description("PageSize"), mcp.DefaultNumber(100)),
+		mcp.WithNumber("page_size", mcp.Description("PageSize"), mcp.DefaultNumber(100)),
+		to.OutputSchema[[]*gitea_sdk.Repository](),
 	)
 )
 
@@ -62,15 +65,25 @@ func init() {
 	Tool.RegisterRead(server.ServerTool{
 		Tool:    SearchUsersTool,
 		Handler: SearchUsersFn,
-	})
+	}, tool.Scope(gitea.ScopeUser), tool.Alias("pageSize", "page_size"))
 	Tool.RegisterRead(server.ServerTool{
 		Tool:    SearOrgTeamsTool,
 		Handler: SearchOrgTeamsFn,
-	})
+	}, tool.Scope(gitea.ScopeOrganization),
+		tool.Alias("includeDescription", "include_description"),
+		tool.Alias("pageSize", "page_size"),
+	)
 	Tool.RegisterRead(server.ServerTool{
 		Tool:    SearchReposTool,
 		Handler: SearchReposFn,
-	})
+	}, tool.Scope(gitea.ScopeRepository),
+		tool.Alias("keywordIsTopic", "keyword_is_topic"),
+		tool.Alias("keywordInDescription", "keyword_in_description"),
+		tool.Alias("ownerID", "owner_id"),
+		tool.Alias("isPrivate", "is_private"),
+		tool.Alias("isArchived", "is_archived"),
+		tool.Alias("pageSize", "page_size"),
+	)
 }
 
 func SearchUsersFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -83,7 +96,7 @@ func SearchUsersFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 	if !ok {
 		page = 1
 	}
-	pageSize, ok := req.GetArguments()["pageSize"].(float64)
+	pageSize, ok := req.GetArguments()["page_size"].(float64)
 	if !ok {
 		pageSize = 100
 	}
@@ -94,11 +107,11 @@ func SearchUsersFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 			PageSize: int(pageSize),
 		},
 	}
-	users, _, err := gitea.Client().SearchUsers(opt)
+	users, _, err := gitea.ClientFromContext(ctx).SearchUsers(opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("search users err: %v", err))
 	}
-	return to.TextResult(users)
+	return to.Result(users)
 }
 
 func SearchOrgTeamsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -111,12 +124,12 @@ func SearchOrgTeamsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 	if !ok {
 		return to.ErrorResult(fmt.Errorf("query is required"))
 	}
-	includeDescription, _ := req.GetArguments()["includeDescription"].(bool)
+	includeDescription, _ := req.GetArguments()["include_description"].(bool)
 	page, ok := req.GetArguments()["page"].(float64)
 	if !ok {
 		page = 1
 	}
-	pageSize, ok := req.GetArguments()["pageSize"].(float64)
+	pageSize, ok := req.GetArguments()["page_size"].(float64)
 	if !ok {
 		pageSize = 100
 	}
@@ -128,11 +141,11 @@ func SearchOrgTeamsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallTo
 			PageSize: int(pageSize),
 		},
 	}
-	teams, _, err := gitea.Client().SearchOrgTeams(org, &opt)
+	teams, _, err := gitea.ClientFromContext(ctx).SearchOrgTeams(org, &opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("search organization teams error: %v", err))
 	}
-	return to.TextResult(teams)
+	return to.Result(teams)
 }
 
 func SearchReposFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -141,16 +154,16 @@ func SearchReposFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 	if !ok {
 		return to.ErrorResult(fmt.Errorf("keyword is required"))
 	}
-	keywordIsTopic, _ := req.GetArguments()["keywordIsTopic"].(bool)
-	keywordInDescription, _ := req.GetArguments()["keywordInDescription"].(bool)
-	ownerID, _ := req.GetArguments()["ownerID"].(float64)
+	keywordIsTopic, _ := req.GetArguments()["keyword_is_topic"].(bool)
+	keywordInDescription, _ := req.GetArguments()["keyword_in_description"].(bool)
+	ownerID, _ := req.GetArguments()["owner_id"].(float64)
 	var pIsPrivate *bool
-	isPrivate, ok := req.GetArguments()["isPrivate"].(bool)
+	isPrivate, ok := req.GetArguments()["is_private"].(bool)
 	if ok {
 		pIsPrivate = ptr.To(isPrivate)
 	}
 	var pIsArchived *bool
-	isArchived, ok := req.GetArguments()["isArchived"].(bool)
+	isArchived, ok := req.GetArguments()["is_archived"].(bool)
 	if ok {
 		pIsArchived = ptr.To(isArchived)
 	}
@@ -160,7 +173,7 @@ func SearchReposFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 	if !ok {
 		page = 1
 	}
-	pageSize, ok := req.GetArguments()["pageSize"].(float64)
+	pageSize, ok := req.GetArguments()["page_size"].(float64)
 	if !ok {
 		pageSize = 100
 	}
@@ -178,9 +191,9 @@ func SearchReposFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 			PageSize: int(pageSize),
 		},
 	}
-	repos, _, err := gitea.Client().SearchRepos(opt)
+	repos, _, err := gitea.ClientFromContext(ctx).SearchRepos(opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("search repos error: %v", err))
 	}
-	return to.TextResult(repos)
+	return to.Result(repos)
 }
@@ -0,0 +1,124 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"gitea.com/gitea/gitea-mcp/pkg/flag"
+	"gitea.com/gitea/gitea-mcp/pkg/log"
+	"gitea.com/gitea/gitea-mcp/pkg/to"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+var errShuttingDown = errors.New("server is shutting down")
+
+// calls tracks the tool calls in flight so a shutdown can wait for them.
+var calls inflight
+
+type inflight struct {
+	mu       sync.Mutex
+	draining bool
+	wg       sync.WaitGroup
+}
+
+func (f *inflight) begin() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.draining {
+		return false
+	}
+	f.wg.Add(1)
+	return true
+}
+
+func (f *inflight) end() {
+	f.wg.Done()
+}
+
+func (f *inflight) isDraining() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.draining
+}
+
+// drain refuses new calls and waits for the running ones until timeout. It
+// reports whether all of them finished.
+func (f *inflight) drain(timeout time.Duration) bool {
+	f.mu.Lock()
+	f.draining = true
+	f.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		f.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// trackCalls is a tool handler middleware registering every call with calls.
+func trackCalls(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !calls.begin() {
+			return to.ErrorResult(errShuttingDown)
+		}
+		defer calls.end()
+		return next(ctx, req)
+	}
+}
+
+// rejectWhileDraining answers new HTTP requests with 503 once a shutdown has
+// started, so clients reconnect to another replica.
+func rejectWhileDraining(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.isDraining() {
+			w.Header().Set("Connection", "close")
+			http.Error(w, errShuttingDown.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serveUntil runs serve until it returns or ctx is done. On cancellation it
+// stops taking new tool calls, waits for the running ones up to
+// flag.ShutdownTimeout and then calls stop with the time that is left.
+func serveUntil(ctx context.Context, serve func() error, stop func(context.Context) error) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- serve()
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Infof("Shutting down, waiting up to %s for in-flight tool calls", flag.ShutdownTimeout)
+	deadline := time.Now().Add(flag.ShutdownTimeout)
+	if !calls.drain(flag.ShutdownTimeout) {
+		log.Warnf("Shutdown timeout reached with tool calls still running")
+	}
+
+	stopCtx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	if err := stop(stopCtx); err != nil {
+		log.Errorf("shutdown server err: %v", err)
+	}
+	<-errCh
+	return ctx.Err()
+}
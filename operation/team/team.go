@@ -0,0 +1,243 @@
+package team
+
+import (
+	"context"
+	"fmt"
+
+	"gitea.com/gitea/gitea-mcp/pkg/gitea"
+	"gitea.com/gitea/gitea-mcp/pkg/log"
+	"gitea.com/gitea/gitea-mcp/pkg/params"
+	"gitea.com/gitea/gitea-mcp/pkg/to"
+	"gitea.com/gitea/gitea-mcp/pkg/tool"
+
+	gitea_sdk "code.gitea.io/sdk/gitea"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+var Tool = tool.New(tool.Scope(gitea.ScopeOrganization))
+
+const (
+	ListOrgTeamsToolName     = "list_org_teams"
+	ListTeamMembersToolName  = "list_team_members"
+	AddTeamMemberToolName    = "add_team_member"
+	RemoveTeamMemberToolName = "remove_team_member"
+	ListTeamReposToolName    = "list_team_repos"
+	AddTeamRepoToolName      = "add_team_repo"
+	RemoveTeamRepoToolName   = "remove_team_repo"
+)
+
+var (
+	ListOrgTeamsTool = mcp.NewTool(
+		ListOrgTeamsToolName,
+		mcp.WithDescription("List the teams of an organization"),
+		mcp.WithString("org", mcp.Required(), mcp.Description("organization name")),
+		mcp.WithNumber("page", mcp.Description("page number"), mcp.DefaultNumber(1), mcp.Min(1)),
+		mcp.WithNumber("page_size", mcp.Description("page size"), mcp.DefaultNumber(50), mcp.Min(1)),
+		to.OutputSchema[[]*gitea_sdk.Team](),
+	)
+
+	ListTeamMembersTool = mcp.NewTool(
+		ListTeamMembersToolName,
+		mcp.WithDescription("List the members of a team"),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description("team ID, as listed by list_org_teams")),
+		mcp.WithNumber("page", mcp.Description("page number"), mcp.DefaultNumber(1), mcp.Min(1)),
+		mcp.WithNumber("page_size", mcp.Description("page size"), mcp.DefaultNumber(50), mcp.Min(1)),
+		to.OutputSchema[[]*gitea_sdk.User](),
+	)
+
+	AddTeamMemberTool = mcp.NewTool(
+		AddTeamMemberToolName,
+		mcp.WithDescription("Add a user to a team, giving them the team's access to its repositories"),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description("team ID, as listed by list_org_teams")),
+		mcp.WithString("username", mcp.Required(), mcp.Description("user to add")),
+		to.OutputSchema[string](),
+	)
+
+	RemoveTeamMemberTool = mcp.NewTool(
+		RemoveTeamMemberToolName,
+		mcp.WithDescription("Remove a user from a team"),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description("team ID, as listed by list_org_teams")),
+		mcp.WithString("username", mcp.Required(), mcp.Description("user to remove")),
+		to.OutputSchema[string](),
+	)
+
+	ListTeamReposTool = mcp.NewTool(
+		ListTeamReposToolName,
+		mcp.WithDescription("List the repositories a team has access to"),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description("team ID, as listed by list_org_teams")),
+		mcp.WithNumber("page", mcp.Description("page number"), mcp.DefaultNumber(1), mcp.Min(1)),
+		mcp.WithNumber("page_size", mcp.Description("page size"), mcp.DefaultNumber(50), mcp.Min(1)),
+		to.OutputSchema[[]*gitea_sdk.Repository](),
+	)
+
+	AddTeamRepoTool = mcp.NewTool(
+		AddTeamRepoToolName,
+		mcp.WithDescription("Give a team access to a repository of its organization"),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description("team ID, as listed by list_org_teams")),
+		mcp.WithString("org", mcp.Required(), mcp.Description("organization owning the repository")),
+		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+		to.OutputSchema[string](),
+	)
+
+	RemoveTeamRepoTool = mcp.NewTool(
+		RemoveTeamRepoToolName,
+		mcp.WithDescription("Remove a team's access to a repository"),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description("team ID, as listed by list_org_teams")),
+		mcp.WithString("org", mcp.Required(), mcp.Description("organization owning the repository")),
+		mcp.WithString("repo", mcp.Required(), mcp.Description("repository name")),
+		to.OutputSchema[string](),
+	)
+)
+
+func init() {
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    ListOrgTeamsTool,
+		Handler: ListOrgTeamsFn,
+	})
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    ListTeamMembersTool,
+		Handler: ListTeamMembersFn,
+	})
+	Tool.RegisterWrite(server.ServerTool{
+		Tool:    AddTeamMemberTool,
+		Handler: AddTeamMemberFn,
+	})
+	Tool.RegisterWrite(server.ServerTool{
+		Tool:    RemoveTeamMemberTool,
+		Handler: RemoveTeamMemberFn,
+	})
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    ListTeamReposTool,
+		Handler: ListTeamReposFn,
+	})
+	Tool.RegisterWrite(server.ServerTool{
+		Tool:    AddTeamRepoTool,
+		Handler: AddTeamRepoFn,
+	})
+	Tool.RegisterWrite(server.ServerTool{
+		Tool:    RemoveTeamRepoTool,
+		Handler: RemoveTeamRepoFn,
+	})
+}
+
+func ListOrgTeamsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListOrgTeamsFn")
+	org, ok := req.GetArguments()["org"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("org is required"))
+	}
+	teams, _, err := gitea.ClientFromContext(ctx).ListOrgTeams(org, gitea_sdk.ListTeamsOptions{
+		ListOptions: params.ListOptions(req),
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list teams of %v err: %v", org, err))
+	}
+	return to.Result(teams)
+}
+
+func ListTeamMembersFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListTeamMembersFn")
+	id, ok := req.GetArguments()["id"].(float64)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("team id is required"))
+	}
+	members, _, err := gitea.ClientFromContext(ctx).ListTeamMembers(int64(id), gitea_sdk.ListTeamMembersOptions{
+		ListOptions: params.ListOptions(req),
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list members of team %v err: %v", int64(id), err))
+	}
+	return to.Result(members)
+}
+
+func AddTeamMemberFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called AddTeamMemberFn")
+	id, ok := req.GetArguments()["id"].(float64)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("team id is required"))
+	}
+	username, ok := req.GetArguments()["username"].(string)
+	if !ok || username == "" {
+		return to.ErrorResult(fmt.Errorf("username is required"))
+	}
+	_, err := gitea.ClientFromContext(ctx).AddTeamMember(int64(id), username)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("add %v to team %v err: %v", username, int64(id), err))
+	}
+	return to.Result("Team member added successfully")
+}
+
+func RemoveTeamMemberFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called RemoveTeamMemberFn")
+	id, ok := req.GetArguments()["id"].(float64)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("team id is required"))
+	}
+	username, ok := req.GetArguments()["username"].(string)
+	if !ok || username == "" {
+		return to.ErrorResult(fmt.Errorf("username is required"))
+	}
+	_, err := gitea.ClientFromContext(ctx).RemoveTeamMember(int64(id), username)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("remove %v from team %v err: %v", username, int64(id), err))
+	}
+	return to.Result("Team member removed successfully")
+}
+
+func ListTeamReposFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called ListTeamReposFn")
+	id, ok := req.GetArguments()["id"].(float64)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("team id is required"))
+	}
+	repos, _, err := gitea.ClientFromContext(ctx).ListTeamRepositories(int64(id), gitea_sdk.ListTeamRepositoriesOptions{
+		ListOptions: params.ListOptions(req),
+	})
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("list repositories of team %v err: %v", int64(id), err))
+	}
+	return to.Result(repos)
+}
+
+func AddTeamRepoFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called AddTeamRepoFn")
+	id, ok := req.GetArguments()["id"].(float64)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("team id is required"))
+	}
+	org, ok := req.GetArguments()["org"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("org is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("repo is required"))
+	}
+	_, err := gitea.ClientFromContext(ctx).AddTeamRepository(int64(id), org, repo)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("add %v/%v to team %v err: %v", org, repo, int64(id), err))
+	}
+	return to.Result("Team repository added successfully")
+}
+
+func RemoveTeamRepoFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called RemoveTeamRepoFn")
+	id, ok := req.GetArguments()["id"].(float64)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("team id is required"))
+	}
+	org, ok := req.GetArguments()["org"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("org is required"))
+	}
+	repo, ok := req.GetArguments()["repo"].(string)
+	if !ok {
+		return to.ErrorResult(fmt.Errorf("repo is required"))
+	}
+	_, err := gitea.ClientFromContext(ctx).RemoveTeamRepository(int64(id), org, repo)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("remove %v/%v from team %v err: %v", org, repo, int64(id), err))
+	}
+	return to.Result("Team repository removed successfully")
+}
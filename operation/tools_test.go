@@ -0,0 +1,1873 @@
+package operation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gitea.com/gitea/gitea-mcp/pkg/giteatest"
+	"gitea.com/gitea/gitea-mcp/pkg/tool"
+)
+
+// seed fills fake with the fixture every tool test starts from. IDs are
+// handed out in creation order:
+//
+//	1 test, 2 alice, 3 acme, 4 acme/owners, 5 acme/devs, 6 test/demo,
+//	7 label bug, 8 label enhancement, 9 issue #1, 10 comment on #1,
+//	11 pull #2, 12 release v1.0.0, 13 acme/infra
+func seed(fake *giteatest.Server) {
+	fake.AddUser("alice")
+	fake.AddOrg("acme")
+	fake.AddTeam("acme", "owners", "Administrators")
+	fake.AddTeam("acme", "devs", "Developers")
+
+	repo := fake.AddRepo("test", "demo")
+	repo.Commit("main", "Add code", map[string]string{
+		"src/main.go":   "package main\n",
+		"docs/guide.md": "# Guide\n",
+	})
+	repo.Commit("feature", "Add feature", map[string]string{
+		"src/feature.go": "package main\n\nfunc feature() {}\n",
+	})
+	bug := repo.AddLabel("bug", "#ee0701")
+	repo.AddLabel("enhancement", "a2eeef")
+	repo.AddIssue("Crash on start", "It crashes.")
+	repo.AddComment(1, "Reproduced.")
+	repo.LabelIssue(1, bug)
+	repo.AddPull("Add feature", "feature", "main")
+	repo.AddTag("v0.9.0", "Beta")
+	repo.AddRelease("v1.0.0", "First release")
+
+	fake.AddRepo("acme", "infra")
+}
+
+func demo(fake *giteatest.Server) *giteatest.Repo {
+	return fake.Repo("test", "demo")
+}
+
+// args returns the arguments addressing test/demo plus extra.
+func args(extra map[string]any) map[string]any {
+	a := map[string]any{"owner": "test", "repo": "demo"}
+	for k, v := range extra {
+		a[k] = v
+	}
+	return a
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+type toolTest struct {
+	tool string
+	name string
+	// setup changes the fixture before the call.
+	setup func(fake *giteatest.Server)
+	args  map[string]any
+	// want is the expected structured content, which the result must
+	// contain as described by matchJSON.
+	want string
+	// wantErr is a substring of the expected error.
+	wantErr string
+	// check inspects the fake after a successful call.
+	check func(t *testing.T, fake *giteatest.Server)
+}
+
+var toolTests = []toolTest{
+	// User
+	{
+		tool: "get_my_user_info", name: "ok",
+		want: `{"result":{"id":1,"login":"test"}}`,
+	},
+	{
+		tool: "get_user_orgs", name: "ok",
+		args: map[string]any{"page": 1, "page_size": 10},
+		want: `{"result":[{"username":"acme"}]}`,
+	},
+	{
+		tool: "get_token_capabilities", name: "ok",
+		want: `{"result":{"read_only":false,"unavailable_tools":[]}}`,
+	},
+
+	// Search
+	{
+		tool: "search_users", name: "ok",
+		args: map[string]any{"keyword": "ali"},
+		want: `{"result":[{"id":2,"login":"alice"}]}`,
+	},
+	{
+		tool: "search_users", name: "missing keyword",
+		wantErr: "keyword is required",
+	},
+	{
+		tool: "search_org_teams", name: "in description",
+		args: map[string]any{"org": "acme", "query": "admin", "include_description": true},
+		want: `{"result":[{"id":4,"name":"owners"}]}`,
+	},
+	{
+		tool: "search_org_teams", name: "name only",
+		args: map[string]any{"org": "acme", "query": "admin"},
+		want: `{"result":[]}`,
+	},
+	{
+		tool: "search_org_teams", name: "missing org",
+		args:    map[string]any{"query": "admin"},
+		wantErr: "organization is required",
+	},
+	{
+		tool: "search_org_teams", name: "unknown org",
+		args:    map[string]any{"org": "nope", "query": "admin"},
+		wantErr: "search organization teams error: The target couldn't be found.",
+	},
+	{
+		tool: "search_repos", name: "ok",
+		args: map[string]any{"keyword": "inf"},
+		want: `{"result":[{"full_name":"acme/infra"}]}`,
+	},
+	{
+		tool: "search_repos", name: "by owner",
+		args: map[string]any{"keyword": "", "owner_id": 1},
+		want: `{"result":[{"full_name":"test/demo"}]}`,
+	},
+	{
+		tool: "search_repos", name: "missing keyword",
+		wantErr: "keyword is required",
+	},
+	{
+		tool: "search_code", name: "ok",
+		args: args(map[string]any{"query": "PACKAGE"}),
+		want: `{"result":{"matches":[{"path":"src/main.go","line":1,"lines":[{"line":1,"content":"package main"}]}],"truncated":false}}`,
+	},
+	{
+		tool: "search_code", name: "ref, paths and context",
+		args: args(map[string]any{"query": "func", "ref": "feature", "paths": []any{"src/**"}, "context_lines": 1}),
+		want: `{"result":{"matches":[{"path":"src/feature.go","line":3,"lines":[{"line":2,"content":""},{"line":3,"content":"func feature() {}"}]}]}}`,
+	},
+	{
+		tool: "search_code", name: "file name glob",
+		args: args(map[string]any{"query": "package", "ref": "feature", "paths": []any{"feature.*"}}),
+		want: `{"result":{"matches":[{"path":"src/feature.go","line":1}]}}`,
+	},
+	{
+		tool: "search_code", name: "case sensitive regexp",
+		args: args(map[string]any{"query": "^# [A-Z]", "regexp": true, "case_sensitive": true}),
+		want: `{"result":{"matches":[{"path":"docs/guide.md","line":1}]}}`,
+	},
+	{
+		tool: "search_code", name: "max results",
+		args: args(map[string]any{"query": "e", "max_results": 1}),
+		want: `{"result":{"matches":[{"path":"README.md","line":1,"lines":[{"line":1,"content":"# demo"}]}],"truncated":true}}`,
+	},
+	{
+		tool: "search_code", name: "skips unreadable files",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).Commit("main", "Add logo", map[string]string{"logo.png": "\x89PNG\x00package"})
+			demo(fake).CommitLFS("main", "Add model", "model.txt", "package weights\n")
+			demo(fake).Unreadable("src/main.go")
+		},
+		args: args(map[string]any{"query": "package"}),
+		want: `{"result":{"matches":[],"truncated":false,"skipped":[{"path":"logo.png","reason":"binary"},{"path":"model.txt","reason":"lfs"},{"path":"src/main.go","reason":"The target couldn't be found."}]}}`,
+	},
+	{
+		tool: "search_code", name: "too large",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).Commit("main", "Add dump", map[string]string{"dump.sql": "-- package\n" + strings.Repeat("x", 1<<20)})
+		},
+		args: args(map[string]any{"query": "package"}),
+		want: `{"result":{"matches":[{"path":"src/main.go"}],"truncated":false,"skipped":[{"path":"dump.sql","reason":"too large"}]}}`,
+	},
+	{
+		tool: "search_code", name: "invalid regexp",
+		args:    args(map[string]any{"query": "(", "regexp": true}),
+		wantErr: "invalid query: error parsing regexp",
+	},
+	{
+		tool: "search_code", name: "missing query",
+		args:    args(nil),
+		wantErr: "query is required",
+	},
+	{
+		tool: "search_code", name: "unknown repo",
+		args:    map[string]any{"owner": "test", "repo": "nope", "query": "x"},
+		wantErr: "get test/nope err: The target couldn't be found.",
+	},
+
+	// Teams
+	{
+		tool: "list_org_teams", name: "ok",
+		args: map[string]any{"org": "acme"},
+		want: `{"result":[{"id":4,"name":"owners"},{"id":5,"name":"devs"}]}`,
+	},
+	{
+		tool: "list_org_teams", name: "unknown org",
+		args:    map[string]any{"org": "nope"},
+		wantErr: "list teams of nope err: The target couldn't be found.",
+	},
+	{
+		tool: "list_team_members", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			fake.AddTeamMember(5, "alice")
+		},
+		args: map[string]any{"id": 5},
+		want: `{"result":[{"login":"alice"}]}`,
+	},
+	{
+		tool: "list_team_members", name: "unknown team",
+		args:    map[string]any{"id": 99},
+		wantErr: "list members of team 99 err: The target couldn't be found.",
+	},
+	{
+		tool: "add_team_member", name: "ok",
+		args: map[string]any{"id": 5, "username": "alice"},
+		want: `{"result":"Team member added successfully"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if members := fake.TeamMembers(5); strings.Join(members, ",") != "alice" {
+				t.Errorf("members = %v", members)
+			}
+		},
+	},
+	{
+		tool: "add_team_member", name: "unknown user",
+		args:    map[string]any{"id": 5, "username": "bob"},
+		wantErr: "add bob to team 5 err: The target couldn't be found.",
+	},
+	{
+		tool: "remove_team_member", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			fake.AddTeamMember(5, "alice")
+		},
+		args: map[string]any{"id": 5, "username": "alice"},
+		want: `{"result":"Team member removed successfully"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if members := fake.TeamMembers(5); len(members) != 0 {
+				t.Errorf("members = %v", members)
+			}
+		},
+	},
+	{
+		tool: "remove_team_member", name: "missing id",
+		args:    map[string]any{"username": "alice"},
+		wantErr: "team id is required",
+	},
+	{
+		tool: "list_team_repos", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			fake.AddTeamRepo(5, fake.Repo("acme", "infra"))
+		},
+		args: map[string]any{"id": 5},
+		want: `{"result":[{"full_name":"acme/infra"}]}`,
+	},
+	{
+		tool: "list_team_repos", name: "none",
+		args: map[string]any{"id": 4},
+		want: `{"result":[]}`,
+	},
+	{
+		tool: "add_team_repo", name: "ok",
+		args: map[string]any{"id": 5, "org": "acme", "repo": "infra"},
+		want: `{"result":"Team repository added successfully"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if repos := fake.TeamRepos(5); strings.Join(repos, ",") != "acme/infra" {
+				t.Errorf("repos = %v", repos)
+			}
+		},
+	},
+	{
+		tool: "add_team_repo", name: "other owner",
+		args:    map[string]any{"id": 5, "org": "test", "repo": "demo"},
+		wantErr: "add test/demo to team 5 err: repository does not belong to the team's organization",
+	},
+	{
+		tool: "remove_team_repo", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			fake.AddTeamRepo(5, fake.Repo("acme", "infra"))
+		},
+		args: map[string]any{"id": 5, "org": "acme", "repo": "infra"},
+		want: `{"result":"Team repository removed successfully"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if repos := fake.TeamRepos(5); len(repos) != 0 {
+				t.Errorf("repos = %v", repos)
+			}
+		},
+	},
+	{
+		tool: "remove_team_repo", name: "unknown repo",
+		args:    map[string]any{"id": 5, "org": "acme", "repo": "nope"},
+		wantErr: "remove acme/nope from team 5 err: The target couldn't be found.",
+	},
+
+	// Organizations
+	{
+		tool: "get_org", name: "ok",
+		args: map[string]any{"org": "acme"},
+		want: `{"result":{"id":3,"username":"acme","visibility":"public"}}`,
+	},
+	{
+		tool: "get_org", name: "not found",
+		args:    map[string]any{"org": "nope"},
+		wantErr: "get org nope err: The target couldn't be found.",
+	},
+	{
+		tool: "list_org_repos", name: "ok",
+		args: map[string]any{"org": "acme"},
+		want: `{"result":[{"full_name":"acme/infra"}]}`,
+	},
+	{
+		tool: "list_org_repos", name: "sorted and paged",
+		setup: func(fake *giteatest.Server) {
+			fake.AddRepo("acme", "api")
+			fake.AddRepo("acme", "web")
+		},
+		args: map[string]any{"org": "acme", "keyword": "I", "sort": "alpha", "order": "desc", "page": 2, "page_size": 1},
+		want: `{"result":[{"full_name":"acme/api"}]}`,
+	},
+	{
+		tool: "list_org_repos", name: "filtered",
+		setup: func(fake *giteatest.Server) {
+			fake.AddRepo("acme", "legacy").Archived = true
+		},
+		args: map[string]any{"org": "acme", "is_archived": true},
+		want: `{"result":[{"full_name":"acme/legacy"}]}`,
+	},
+	{
+		tool: "list_org_repos", name: "unknown org",
+		args:    map[string]any{"org": "nope"},
+		wantErr: "list repos of nope err: The target couldn't be found.",
+	},
+	{
+		tool: "list_org_members", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			fake.AddTeamMember(5, "alice")
+		},
+		args: map[string]any{"org": "acme"},
+		want: `{"result":[{"login":"alice"},{"login":"test"}]}`,
+	},
+	{
+		tool: "list_org_members", name: "unknown org",
+		args:    map[string]any{"org": "nope"},
+		wantErr: "list members of nope err: The target couldn't be found.",
+	},
+	{
+		tool: "list_org_public_members", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			fake.PublicizeMember("acme", "test")
+		},
+		args: map[string]any{"org": "acme"},
+		want: `{"result":[{"login":"test"}]}`,
+	},
+	{
+		tool: "list_org_public_members", name: "none",
+		args: map[string]any{"org": "acme"},
+		want: `{"result":[]}`,
+	},
+	{
+		tool: "check_org_membership", name: "public member",
+		setup: func(fake *giteatest.Server) {
+			fake.AddTeamMember(5, "alice")
+			fake.PublicizeMember("acme", "alice")
+		},
+		args: map[string]any{"org": "acme", "username": "alice"},
+		want: `{"result":{"member":true,"public":true}}`,
+	},
+	{
+		tool: "check_org_membership", name: "member",
+		args: map[string]any{"org": "acme", "username": "test"},
+		want: `{"result":{"member":true,"public":false}}`,
+	},
+	{
+		tool: "check_org_membership", name: "not a member",
+		args: map[string]any{"org": "acme", "username": "alice"},
+		want: `{"result":{"member":false,"public":false}}`,
+	},
+	{
+		tool: "create_org", name: "ok",
+		args: map[string]any{"name": "widgets", "full_name": "Widgets Inc.", "visibility": "private"},
+		want: `{"result":{"username":"widgets","full_name":"Widgets Inc.","visibility":"private"}}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if fake.Org("widgets") == nil {
+				t.Error("widgets was not created")
+			}
+		},
+	},
+	{
+		tool: "create_org", name: "name taken",
+		args:    map[string]any{"name": "alice"},
+		wantErr: "create org alice err: user already exists [name: alice]",
+	},
+	{
+		tool: "edit_org", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			fake.Org("acme").Website = "https://acme.example.com"
+		},
+		args: map[string]any{"org": "acme", "description": "Makers of everything", "visibility": "limited"},
+		want: `{"result":{"username":"acme","full_name":"acme","description":"Makers of everything","website":"https://acme.example.com","visibility":"limited"}}`,
+	},
+	{
+		tool: "edit_org", name: "not found",
+		args:    map[string]any{"org": "nope", "description": "x"},
+		wantErr: "get org nope err: The target couldn't be found.",
+	},
+	{
+		tool: "list_org_labels", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			fake.AddOrgLabel("acme", "security", "#d73a4a")
+		},
+		args: map[string]any{"org": "acme"},
+		want: `{"result":[{"id":14,"name":"security","color":"d73a4a"}]}`,
+	},
+	{
+		tool: "list_org_labels", name: "unknown org",
+		args:    map[string]any{"org": "nope"},
+		wantErr: "list nope/labels err: The target couldn't be found.",
+	},
+	{
+		tool: "get_org_label", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			fake.AddOrgLabel("acme", "security", "#d73a4a")
+		},
+		args: map[string]any{"org": "acme", "id": 14},
+		want: `{"result":{"id":14,"name":"security"}}`,
+	},
+	{
+		tool: "get_org_label", name: "not found",
+		args:    map[string]any{"org": "acme", "id": 99},
+		wantErr: "get acme/label/99 err: The target couldn't be found.",
+	},
+	{
+		tool: "create_org_label", name: "ok",
+		args: map[string]any{"org": "acme", "name": "security", "color": "#d73a4a", "description": "Security issues"},
+		want: `{"result":{"name":"security","color":"d73a4a","description":"Security issues"}}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if labels := fake.OrgLabels("acme"); len(labels) != 1 {
+				t.Errorf("labels = %+v", labels)
+			}
+		},
+	},
+	{
+		tool: "create_org_label", name: "missing color",
+		args:    map[string]any{"org": "acme", "name": "security"},
+		wantErr: "color is required",
+	},
+	{
+		tool: "edit_org_label", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			fake.AddOrgLabel("acme", "security", "#d73a4a")
+		},
+		args: map[string]any{"org": "acme", "id": 14, "name": "sec"},
+		want: `{"result":{"id":14,"name":"sec","color":"d73a4a"}}`,
+	},
+	{
+		tool: "edit_org_label", name: "not found",
+		args:    map[string]any{"org": "acme", "id": 99, "name": "sec"},
+		wantErr: "edit acme/label/99 err: The target couldn't be found.",
+	},
+	{
+		tool: "delete_org_label", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			fake.AddOrgLabel("acme", "security", "#d73a4a")
+		},
+		args: map[string]any{"org": "acme", "id": 14},
+		want: `{"result":"Label deleted successfully"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if labels := fake.OrgLabels("acme"); len(labels) != 0 {
+				t.Errorf("labels = %+v", labels)
+			}
+		},
+	},
+	{
+		tool: "delete_org_label", name: "not found",
+		args:    map[string]any{"org": "acme", "id": 99},
+		wantErr: "delete acme/label/99 err: The target couldn't be found.",
+	},
+
+	// Repositories
+	{
+		tool: "create_repo", name: "personal",
+		args: map[string]any{"name": "tools", "description": "Tooling", "auto_init": true},
+		want: `{"result":{"full_name":"test/tools","description":"Tooling","empty":false}}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if readme, _ := fake.Repo("test", "tools").File("", "README.md"); readme != "# tools\n\nTooling\n" {
+				t.Errorf("README.md = %q", readme)
+			}
+		},
+	},
+	{
+		tool: "create_repo", name: "organization",
+		args: map[string]any{"name": "web", "organization": "acme", "private": true},
+		want: `{"result":{"full_name":"acme/web","private":true,"empty":true}}`,
+	},
+	{
+		tool: "create_repo", name: "exists",
+		args:    map[string]any{"name": "demo"},
+		wantErr: "create repository 'demo' err: The repository with the same name already exists.",
+	},
+	{
+		tool: "create_repo", name: "missing name",
+		wantErr: "repository name is required",
+	},
+	{
+		tool: "fork_repo", name: "into organization",
+		args: map[string]any{"owner": "test", "repo": "demo", "organization": "acme"},
+		want: `{"result":"Fork success"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if fork := fake.Repo("acme", "demo"); fork == nil || !fork.Fork {
+				t.Errorf("acme/demo is not a fork")
+			}
+		},
+	},
+	{
+		tool: "fork_repo", name: "exists",
+		args:    map[string]any{"owner": "test", "repo": "demo"},
+		wantErr: "fork repository error: The repository with the same name already exists.",
+	},
+	{
+		tool: "fork_repo", name: "legacy user",
+		args: map[string]any{"user": "test", "repo": "demo", "name": "demo-fork"},
+		want: `{"result":"Fork success"}`,
+	},
+	{
+		tool: "fork_repo", name: "missing owner",
+		args:    map[string]any{"repo": "demo"},
+		wantErr: "owner is required",
+	},
+	{
+		tool: "list_my_repos", name: "ok",
+		args: map[string]any{"page": 1, "page_size": 10},
+		want: `{"result":[{"full_name":"acme/infra"},{"full_name":"test/demo"}]}`,
+	},
+	{
+		tool: "list_my_repos", name: "page",
+		args: map[string]any{"page": 2, "page_size": 1},
+		want: `{"result":[{"full_name":"test/demo"}]}`,
+	},
+	{
+		tool: "delete_repo", name: "ok",
+		args: map[string]any{"owner": "acme", "repo": "infra"},
+		want: `{"result":"Repository deleted successfully"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if fake.Repo("acme", "infra") != nil {
+				t.Error("acme/infra still exists")
+			}
+		},
+	},
+	{
+		tool: "delete_repo", name: "not found",
+		args:    map[string]any{"owner": "test", "repo": "nope"},
+		wantErr: "delete repository 'test/nope' error: The target couldn't be found.",
+	},
+	{
+		tool: "delete_repo", name: "missing owner",
+		args:    map[string]any{"repo": "demo"},
+		wantErr: "owner is required",
+	},
+	{
+		tool: "get_repo", name: "ok",
+		args: args(nil),
+		want: `{"result":{"full_name":"test/demo","default_branch":"main","has_issues":true,"archived":false}}`,
+	},
+	{
+		tool: "get_repo", name: "not found",
+		args:    map[string]any{"owner": "test", "repo": "nope"},
+		wantErr: "get repository 'test/nope' error: The target couldn't be found.",
+	},
+	{
+		tool: "edit_repo", name: "ok",
+		args: args(map[string]any{
+			"description":         "Demo app",
+			"website":             "https://demo.example.com",
+			"default_branch":      "feature",
+			"has_wiki":            false,
+			"allow_rebase":        false,
+			"default_merge_style": "squash",
+			"archived":            true,
+		}),
+		want: `{"result":{"description":"Demo app","website":"https://demo.example.com","default_branch":"feature","has_issues":true,"has_wiki":false,"allow_rebase":false,"allow_squash_merge":true,"default_merge_style":"squash","archived":true}}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if repo := demo(fake); !repo.Archived || repo.DefaultBranch != "feature" {
+				t.Errorf("repo = %+v", repo.Repository)
+			}
+		},
+	},
+	{
+		tool: "edit_repo", name: "missing branch",
+		args:    args(map[string]any{"default_branch": "nope"}),
+		wantErr: "edit repository 'test/demo' error: branch does not exist [name: nope]",
+	},
+	{
+		tool: "transfer_repo", name: "ok",
+		args: args(map[string]any{"new_owner": "acme", "team_ids": []any{5}}),
+		want: `{"result":{"full_name":"acme/demo","owner":{"login":"acme"}}}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if fake.Repo("test", "demo") != nil || fake.Repo("acme", "demo") == nil {
+				t.Error("test/demo was not moved to acme/demo")
+			}
+			if repos := fake.TeamRepos(5); strings.Join(repos, ",") != "acme/demo" {
+				t.Errorf("team repos = %v", repos)
+			}
+		},
+	},
+	{
+		tool: "transfer_repo", name: "teams of a user",
+		args:    args(map[string]any{"new_owner": "alice", "team_ids": []any{5}}),
+		wantErr: "transfer repository 'test/demo' to 'alice' error: Teams can only be added to organization-owned repositories",
+	},
+	{
+		tool: "transfer_repo", name: "name taken",
+		setup: func(fake *giteatest.Server) {
+			fake.AddRepo("alice", "demo")
+		},
+		args:    args(map[string]any{"new_owner": "alice"}),
+		wantErr: "transfer repository 'test/demo' to 'alice' error: repository already exists [uname: alice, name: demo]",
+	},
+	{
+		tool: "transfer_repo", name: "missing new owner",
+		args:    args(nil),
+		wantErr: "new_owner is required",
+	},
+
+	// Topics
+	{
+		tool: "list_repo_topics", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).SetTopics("mcp", "go")
+		},
+		args: args(nil),
+		want: `{"result":["go","mcp"]}`,
+	},
+	{
+		tool: "list_repo_topics", name: "none",
+		args: args(nil),
+		want: `{"result":[]}`,
+	},
+	{
+		tool: "set_repo_topics", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).SetTopics("old")
+		},
+		args: args(map[string]any{"topics": []any{"MCP", "go", "go"}}),
+		want: `{"result":"Topics set successfully"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if topics := demo(fake).Topics(); strings.Join(topics, ",") != "go,mcp" {
+				t.Errorf("topics = %v", topics)
+			}
+		},
+	},
+	{
+		tool: "set_repo_topics", name: "invalid",
+		args:    args(map[string]any{"topics": []any{"-bad"}}),
+		wantErr: "set topics of test/demo err: Topic names are invalid",
+	},
+	{
+		tool: "add_repo_topic", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).SetTopics("mcp")
+		},
+		args: args(map[string]any{"topic": "Go"}),
+		want: `{"result":"Topic added successfully"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if topics := demo(fake).Topics(); strings.Join(topics, ",") != "go,mcp" {
+				t.Errorf("topics = %v", topics)
+			}
+		},
+	},
+	{
+		tool: "add_repo_topic", name: "invalid",
+		args:    args(map[string]any{"topic": "no spaces"}),
+		wantErr: "add topic no spaces err: Topic name is invalid",
+	},
+	{
+		tool: "delete_repo_topic", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).SetTopics("go", "mcp")
+		},
+		args: args(map[string]any{"topic": "go"}),
+		want: `{"result":"Topic deleted successfully"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if topics := demo(fake).Topics(); strings.Join(topics, ",") != "mcp" {
+				t.Errorf("topics = %v", topics)
+			}
+		},
+	},
+	{
+		tool: "delete_repo_topic", name: "not found",
+		args:    args(map[string]any{"topic": "go"}),
+		wantErr: "delete topic go err: The target couldn't be found.",
+	},
+
+	// Collaborators
+	{
+		tool: "list_repo_collaborators", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).AddCollaborator("alice", "read")
+		},
+		args: args(nil),
+		want: `{"result":[{"permission":"read","role_name":"read","user":{"login":"alice"}}]}`,
+	},
+	{
+		tool: "list_repo_collaborators", name: "more than fetched at once",
+		setup: func(fake *giteatest.Server) {
+			for i := range 10 {
+				login := fmt.Sprintf("user%d", i)
+				fake.AddUser(login)
+				if i%2 == 1 {
+					demo(fake).AddCollaborator(login, "write")
+					continue
+				}
+				demo(fake).AddCollaborator(login, "read")
+			}
+		},
+		args: args(map[string]any{"page_size": 500}),
+		want: `{"result":[` +
+			`{"permission":"read","user":{"login":"user0"}},{"permission":"write","user":{"login":"user1"}},` +
+			`{"permission":"read","user":{"login":"user2"}},{"permission":"write","user":{"login":"user3"}},` +
+			`{"permission":"read","user":{"login":"user4"}},{"permission":"write","user":{"login":"user5"}},` +
+			`{"permission":"read","user":{"login":"user6"}},{"permission":"write","user":{"login":"user7"}},` +
+			`{"permission":"read","user":{"login":"user8"}},{"permission":"write","user":{"login":"user9"}}]}`,
+	},
+	{
+		tool: "list_repo_collaborators", name: "none",
+		args: args(nil),
+		want: `{"result":[]}`,
+	},
+	{
+		tool: "add_repo_collaborator", name: "ok",
+		args: args(map[string]any{"username": "alice", "permission": "admin"}),
+		want: `{"result":"Collaborator added successfully"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if mode := demo(fake).Collaborators()["alice"]; mode != "admin" {
+				t.Errorf("alice has %q access", mode)
+			}
+		},
+	},
+	{
+		tool: "add_repo_collaborator", name: "default permission",
+		args: args(map[string]any{"username": "alice"}),
+		want: `{"result":"Collaborator added successfully"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if mode := demo(fake).Collaborators()["alice"]; mode != "write" {
+				t.Errorf("alice has %q access", mode)
+			}
+		},
+	},
+	{
+		tool: "add_repo_collaborator", name: "unknown user",
+		args:    args(map[string]any{"username": "bob"}),
+		wantErr: "add collaborator bob err: user does not exist [uid: 0, name: bob]",
+	},
+	{
+		tool: "remove_repo_collaborator", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).AddCollaborator("alice", "write")
+		},
+		args: args(map[string]any{"username": "alice"}),
+		want: `{"result":"Collaborator removed successfully"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if collaborators := demo(fake).Collaborators(); len(collaborators) != 0 {
+				t.Errorf("collaborators = %v", collaborators)
+			}
+		},
+	},
+	{
+		tool: "remove_repo_collaborator", name: "missing username",
+		args:    args(nil),
+		wantErr: "username is required",
+	},
+	{
+		tool: "get_repo_permission", name: "collaborator",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).AddCollaborator("alice", "write")
+		},
+		args: args(map[string]any{"username": "alice"}),
+		want: `{"result":{"permission":"write","user":{"login":"alice"}}}`,
+	},
+	{
+		tool: "get_repo_permission", name: "owner",
+		args: args(map[string]any{"username": "test"}),
+		want: `{"result":{"permission":"owner"}}`,
+	},
+	{
+		tool: "get_repo_permission", name: "team member",
+		setup: func(fake *giteatest.Server) {
+			fake.AddTeamMember(5, "alice")
+			fake.AddTeamRepo(5, fake.Repo("acme", "infra"))
+		},
+		args: map[string]any{"owner": "acme", "repo": "infra", "username": "alice"},
+		want: `{"result":{"permission":"read"}}`,
+	},
+	{
+		tool: "get_repo_permission", name: "private",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).Private = true
+		},
+		args: args(map[string]any{"username": "alice"}),
+		want: `{"result":{"permission":"none"}}`,
+	},
+	{
+		tool: "get_repo_permission", name: "unknown user",
+		args:    args(map[string]any{"username": "bob"}),
+		wantErr: "get permission of bob err: The target couldn't be found.",
+	},
+
+	// Branches
+	{
+		tool: "list_branches", name: "ok",
+		args: args(nil),
+		want: `{"result":[{"name":"feature","commit":{"message":"Add feature"}},{"name":"main","commit":{"message":"Add code"}}]}`,
+	},
+	{
+		tool: "list_branches", name: "missing repo",
+		args:    map[string]any{"owner": "test"},
+		wantErr: "repo is required",
+	},
+	{
+		tool: "create_branch", name: "ok",
+		args: args(map[string]any{"branch": "dev", "old_branch": "feature"}),
+		want: `{"result":"Branch Created"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			dev, _ := demo(fake).Branch("dev")
+			feature, _ := demo(fake).Branch("feature")
+			if dev != feature {
+				t.Errorf("dev = %s, want %s", dev, feature)
+			}
+		},
+	},
+	{
+		tool: "create_branch", name: "exists",
+		args:    args(map[string]any{"branch": "main", "old_branch": "feature"}),
+		wantErr: "create branch error: The branch already exists.",
+	},
+	{
+		tool: "create_branch", name: "missing branch",
+		args:    args(map[string]any{"old_branch": "main"}),
+		wantErr: "branch is required",
+	},
+	{
+		tool: "delete_branch", name: "ok",
+		args: args(map[string]any{"branch": "feature"}),
+		want: `{"result":"Branch Deleted"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if _, ok := demo(fake).Branch("feature"); ok {
+				t.Error("feature still exists")
+			}
+		},
+	},
+	{
+		tool: "delete_branch", name: "default branch",
+		args:    args(map[string]any{"branch": "main"}),
+		wantErr: "delete branch error: 403 Forbidden",
+	},
+	{
+		tool: "delete_branch", name: "not found",
+		args:    args(map[string]any{"branch": "nope"}),
+		wantErr: "delete branch error: 404 Not Found",
+	},
+	{
+		tool: "delete_branch", name: "protected",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).Protect("feat*")
+		},
+		args:    args(map[string]any{"branch": "feature"}),
+		wantErr: "delete branch error: 403 Forbidden",
+	},
+	{
+		tool: "list_branches", name: "protected",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).Protect("feat*")
+		},
+		args: args(nil),
+		want: `{"result":[{"name":"feature","protected":true,"effective_branch_protection_name":"feat*","user_can_push":false},{"name":"main","protected":false,"user_can_push":true}]}`,
+	},
+
+	// Branch protections
+	{
+		tool: "list_branch_protections", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).Protect("main")
+			demo(fake).Protect("release/*")
+		},
+		args: args(nil),
+		want: `{"result":[{"rule_name":"main","enable_push":false},{"rule_name":"release/*"}]}`,
+	},
+	{
+		tool: "list_branch_protections", name: "page",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).Protect("main")
+			demo(fake).Protect("release/*")
+			demo(fake).Protect("stable")
+		},
+		args: args(map[string]any{"page": 2, "page_size": 2}),
+		want: `{"result":[{"rule_name":"stable"}]}`,
+	},
+	{
+		tool: "list_branch_protections", name: "page past the end",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).Protect("main")
+		},
+		args: args(map[string]any{"page": 3, "page_size": 2}),
+		want: `{"result":[]}`,
+	},
+	{
+		tool: "get_branch_protection", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).Protect("release/*")
+		},
+		args: args(map[string]any{"name": "release/*"}),
+		want: `{"result":{"rule_name":"release/*","required_approvals":0}}`,
+	},
+	{
+		tool: "get_branch_protection", name: "not found",
+		args:    args(map[string]any{"name": "main"}),
+		wantErr: "get branch protection main err: The target couldn't be found.",
+	},
+	{
+		tool: "create_branch_protection", name: "ok",
+		args: args(map[string]any{"name": "main", "enable_push": true, "enable_push_whitelist": true, "push_whitelist_usernames": []any{"alice"},
+			"required_approvals": 2, "enable_status_check": true, "status_check_contexts": []any{"ci/*"}, "dismiss_stale_approvals": true}),
+		want: `{"result":{"rule_name":"main","enable_push":true,"enable_push_whitelist":true,"push_whitelist_usernames":["alice"],` +
+			`"required_approvals":2,"enable_status_check":true,"status_check_contexts":["ci/*"],"dismiss_stale_approvals":true,"block_on_rejected_reviews":false}}`,
+	},
+	{
+		tool: "create_branch_protection", name: "exists",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).Protect("main")
+		},
+		args:    args(map[string]any{"name": "main"}),
+		wantErr: "create branch protection main err: Branch protection already exist",
+	},
+	{
+		tool: "edit_branch_protection", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).Protect("main")
+		},
+		args: args(map[string]any{"name": "main", "required_approvals": 1, "block_on_rejected_reviews": true}),
+		want: `{"result":{"rule_name":"main","enable_push":false,"required_approvals":1,"block_on_rejected_reviews":true,"status_check_contexts":[]}}`,
+	},
+	{
+		tool: "edit_branch_protection", name: "not found",
+		args:    args(map[string]any{"name": "main", "required_approvals": 1}),
+		wantErr: "edit branch protection main err: The target couldn't be found.",
+	},
+	{
+		tool: "delete_branch_protection", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).Protect("feature")
+		},
+		args: args(map[string]any{"name": "feature"}),
+		want: `{"result":"Branch Protection Deleted"}`,
+	},
+	{
+		tool: "delete_branch_protection", name: "not found",
+		args:    args(map[string]any{"name": "main"}),
+		wantErr: "delete branch protection main err: The target couldn't be found.",
+	},
+
+	// Commits
+	{
+		tool: "list_repo_commits", name: "ok",
+		args: args(map[string]any{"page": 1, "page_size": 10}),
+		want: `{"result":[{"commit":{"message":"Add code"}},{"commit":{"message":"Initial commit"}}]}`,
+	},
+	{
+		tool: "list_repo_commits", name: "branch and path",
+		args: args(map[string]any{"sha": "feature", "path": "src/feature.go", "page": 1, "page_size": 10}),
+		want: `{"result":[{"commit":{"message":"Add feature"},"files":[{"filename":"src/feature.go"}]}]}`,
+	},
+	{
+		tool: "list_repo_commits", name: "missing page",
+		args:    args(map[string]any{"page_size": 10}),
+		wantErr: "page is required",
+	},
+	{
+		tool: "get_commit", name: "ok",
+		args: args(map[string]any{"sha": "feature"}),
+		want: `{"result":{"message":"Add feature","stats":{"total":3,"additions":3,"deletions":0},"files":[{"filename":"src/feature.go","status":"added"}]}}`,
+	},
+	{
+		tool: "get_commit", name: "patch",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).Commit("main", "Update guide", map[string]string{"docs/guide.md": "# User guide\n", "src/main.go": ""})
+		},
+		args: args(map[string]any{"sha": "main", "patch": true}),
+		want: `{"result":{"stats":{"total":3,"additions":1,"deletions":2},"files":[{"filename":"docs/guide.md","status":"modified"},{"filename":"src/main.go","status":"modified"}],` +
+			`"patch":"diff --git a/docs/guide.md b/docs/guide.md\n--- a/docs/guide.md\n+++ b/docs/guide.md\n@@ -1 +1 @@\n-# Guide\n+# User guide\n` +
+			`diff --git a/src/main.go b/src/main.go\n--- a/src/main.go\n+++ b/src/main.go\n@@ -1 +0,0 @@\n-package main\n"}}`,
+	},
+	{
+		tool: "get_commit", name: "max files",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).Commit("main", "Update guide", map[string]string{"docs/guide.md": "# User guide\n", "src/main.go": ""})
+		},
+		args: args(map[string]any{"sha": "main", "max_files": 1}),
+		want: `{"result":{"stats":{"total":3},"files":[{"filename":"docs/guide.md","status":"modified"}],"truncated":true}}`,
+	},
+	{
+		tool: "get_commit", name: "truncated patch",
+		args: args(map[string]any{"sha": "feature", "patch": true, "max_bytes": 10}),
+		want: `{"result":{"patch":"diff --git","truncated":true}}`,
+	},
+	{
+		tool: "get_commit", name: "not found",
+		args:    args(map[string]any{"sha": "nope"}),
+		wantErr: "get commit nope err: object does not exist [id: nope, rel_path: ]",
+	},
+	{
+		tool: "compare_refs", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).Commit("main", "Update guide", map[string]string{"docs/guide.md": "# User guide\n"})
+		},
+		args: args(map[string]any{"base": "main", "head": "feature"}),
+		want: `{"result":{"base":"main","head":"feature","ahead_by":1,"behind_by":1,"commits":[{"message":"Add feature"}],"files":[{"filename":"src/feature.go","status":"added"}],"truncated":false}}`,
+	},
+	{
+		tool: "compare_refs", name: "truncated",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).Commit("feature", "Document feature", map[string]string{"docs/feature.md": "# Feature\n"})
+		},
+		args: args(map[string]any{"base": "main", "head": "feature", "max_commits": 1, "max_files": 1}),
+		want: `{"result":{"ahead_by":2,"behind_by":0,"commits":[{"message":"Document feature"}],"files":[{"filename":"docs/feature.md","status":"added"}],"truncated":true}}`,
+	},
+	{
+		tool: "compare_refs", name: "not found",
+		args:    args(map[string]any{"base": "main", "head": "nope"}),
+		wantErr: "compare main...nope err: The target couldn't be found.",
+	},
+	{
+		tool: "get_file_blame", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).Commit("main", "Expand guide\n\nAdd an intro.", map[string]string{"docs/guide.md": "# Guide\n\nIntro.\nUsage.\n"})
+			demo(fake).Commit("main", "Fix usage", map[string]string{"docs/guide.md": "# Guide\n\nIntro.\nUsage: run it.\n"})
+		},
+		args: args(map[string]any{"path": "docs/guide.md"}),
+		want: `{"result":{"path":"docs/guide.md","hunks":[` +
+			`{"start_line":1,"end_line":1,"summary":"Add code","author":"test","lines":["# Guide"]},` +
+			`{"start_line":2,"end_line":3,"summary":"Expand guide","lines":["","Intro."]},` +
+			`{"start_line":4,"end_line":4,"summary":"Fix usage","lines":["Usage: run it."]}],"truncated":false}}`,
+	},
+	{
+		tool: "get_file_blame", name: "line range",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).Commit("main", "Expand guide", map[string]string{"docs/guide.md": "# Guide\n\nIntro.\n"})
+		},
+		args: args(map[string]any{"path": "docs/guide.md", "ref": "main", "start_line": 3, "end_line": 9}),
+		want: `{"result":{"hunks":[{"start_line":3,"end_line":3,"summary":"Expand guide","lines":["Intro."]}]}}`,
+	},
+	{
+		tool: "get_file_blame", name: "whole history read",
+		setup: func(fake *giteatest.Server) {
+			// With the commit adding it, the file has 100 commits.
+			for i := range 99 {
+				demo(fake).Commit("main", fmt.Sprintf("Edit %d", i), map[string]string{"docs/guide.md": fmt.Sprintf("# Guide\n%d\n", i)})
+			}
+		},
+		args: args(map[string]any{"path": "docs/guide.md", "start_line": 1, "end_line": 1}),
+		want: `{"result":{"hunks":[{"summary":"Add code","lines":["# Guide"]}],"truncated":false}}`,
+	},
+	{
+		tool: "get_file_blame", name: "history cut short",
+		setup: func(fake *giteatest.Server) {
+			for i := range 100 {
+				demo(fake).Commit("main", fmt.Sprintf("Edit %d", i), map[string]string{"docs/guide.md": fmt.Sprintf("# Guide\n%d\n", i)})
+			}
+		},
+		args: args(map[string]any{"path": "docs/guide.md", "start_line": 1, "end_line": 1}),
+		want: `{"result":{"hunks":[{"summary":"Edit 0","lines":["# Guide"]}],"truncated":true}}`,
+	},
+	{
+		tool: "get_file_blame", name: "not found",
+		args:    args(map[string]any{"path": "nope.md"}),
+		wantErr: "no commits of nope.md found",
+	},
+	{
+		tool: "list_commit_statuses", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).AddStatus("main", "pending", "ci/build")
+			demo(fake).AddStatus("main", "success", "ci/build")
+		},
+		args: args(map[string]any{"ref": "main"}),
+		want: `{"result":[{"status":"success","context":"ci/build"},{"status":"pending","context":"ci/build"}]}`,
+	},
+	{
+		tool: "list_commit_statuses", name: "pull request",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).AddStatus("feature", "failure", "ci/test")
+		},
+		args: args(map[string]any{"index": 2}),
+		want: `{"result":[{"status":"failure","context":"ci/test"}]}`,
+	},
+	{
+		tool: "list_commit_statuses", name: "missing ref",
+		args:    args(nil),
+		wantErr: "ref or index is required",
+	},
+	{
+		tool: "get_combined_status", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).AddStatus("feature", "failure", "ci/test")
+			demo(fake).AddStatus("feature", "success", "ci/test")
+			demo(fake).AddStatus("feature", "pending", "ci/lint")
+		},
+		args: args(map[string]any{"index": 2}),
+		want: `{"result":{"state":"pending","total_count":2,"statuses":[{"status":"pending","context":"ci/lint"},{"status":"success","context":"ci/test"}]}}`,
+	},
+	{
+		tool: "get_combined_status", name: "pull request not found",
+		args:    args(map[string]any{"index": 9}),
+		wantErr: "get test/demo/pr/9 err: The target couldn't be found.",
+	},
+	{
+		tool: "create_commit_status", name: "ok",
+		args: args(map[string]any{"index": 2, "state": "success", "context": "agent/review", "description": "Looks good", "target_url": "https://ci.example.com/1"}),
+		want: `{"result":{"status":"success","context":"agent/review","description":"Looks good","target_url":"https://ci.example.com/1"}}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			sha, _ := demo(fake).Branch("feature")
+			if got := demo(fake).Statuses(sha); len(got) != 1 || got[0].Context != "agent/review" {
+				t.Errorf("statuses of feature = %v", got)
+			}
+		},
+	},
+	{
+		tool: "create_commit_status", name: "invalid state",
+		args:    args(map[string]any{"sha": "main", "state": "done"}),
+		wantErr: "create status of main err: [State]: In",
+	},
+
+	// Files
+	{
+		tool: "get_file_content", name: "ok",
+		args: args(map[string]any{"ref": "main", "path": "docs/guide.md"}),
+		want: `{"result":{"path":"docs/guide.md","type":"file","encoding":"base64","content":"IyBHdWlkZQo=","sha":"` + giteatest.BlobSHA("# Guide\n") + `"}}`,
+	},
+	{
+		tool: "get_file_content", name: "with lines",
+		args: args(map[string]any{"ref": "feature", "path": "src/feature.go", "with_lines": true}),
+		want: `{"result":{"path":"src/feature.go","lines":[{"line":1,"content":"package main"},{"line":2,"content":""},{"line":3,"content":"func feature() {}"}],"total_lines":3}}`,
+	},
+	{
+		tool: "get_file_content", name: "line range",
+		args: args(map[string]any{"ref": "feature", "path": "src/feature.go", "start_line": 2, "end_line": 5}),
+		want: `{"result":{"path":"src/feature.go","lines":[{"line":2,"content":""},{"line":3,"content":"func feature() {}"}],"total_lines":3}}`,
+	},
+	{
+		tool: "get_file_content", name: "from line",
+		args: args(map[string]any{"ref": "feature", "path": "src/feature.go", "start_line": 3}),
+		want: `{"result":{"lines":[{"line":3,"content":"func feature() {}"}]}}`,
+	},
+	{
+		tool: "get_file_content", name: "lines of an empty file",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).Commit("main", "Add empty file", map[string]string{"empty.txt": ""})
+		},
+		args: args(map[string]any{"ref": "main", "path": "empty.txt", "with_lines": true}),
+		want: `{"result":{"path":"empty.txt"}}`,
+	},
+	{
+		tool: "get_file_content", name: "end before start",
+		args:    args(map[string]any{"ref": "main", "path": "README.md", "start_line": 3, "end_line": 2}),
+		wantErr: "end_line 2 is before start_line 3",
+	},
+	{
+		tool: "get_file_content", name: "start past the end",
+		args:    args(map[string]any{"ref": "feature", "path": "src/feature.go", "start_line": 4}),
+		wantErr: "start_line 4 is past the end of src/feature.go, total_lines is 3",
+	},
+	{
+		tool: "get_file_content", name: "max bytes",
+		args: args(map[string]any{"ref": "main", "path": "docs/guide.md", "max_bytes": 3}),
+		want: `{"result":{"path":"docs/guide.md","encoding":"base64","content":"IyBH","size":8,"truncated":true}}`,
+	},
+	{
+		tool: "get_file_content", name: "max bytes of lines",
+		args: args(map[string]any{"ref": "feature", "path": "src/feature.go", "with_lines": true, "max_bytes": 14}),
+		want: `{"result":{"lines":[{"line":1,"content":"package main"},{"line":2,"content":""}],"total_lines":3,"truncated":true}}`,
+	},
+	{
+		tool: "get_file_content", name: "binary",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).Commit("main", "Add logo", map[string]string{"logo.png": "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"})
+		},
+		args: args(map[string]any{"ref": "main", "path": "logo.png"}),
+		want: `{"result":{"path":"logo.png","binary":true,"mime_type":"image/png","sha256":"` + sha256Hex("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR") + `"}}`,
+	},
+	{
+		tool: "get_file_content", name: "raw",
+		args: args(map[string]any{"ref": "main", "path": "docs/guide.md", "raw": true}),
+		want: `{"result":{"name":"guide.md","path":"docs/guide.md","type":"file","size":8,"text":"# Guide\n"}}`,
+	},
+	{
+		tool: "get_file_content", name: "lfs pointer",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).CommitLFS("main", "Add model", "model.txt", "weights\n")
+		},
+		args: args(map[string]any{"ref": "main", "path": "model.txt"}),
+		want: `{"result":{"path":"model.txt","lfs":true}}`,
+	},
+	{
+		tool: "get_file_content", name: "raw lfs object",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).CommitLFS("main", "Add model", "model.txt", "weights\n")
+		},
+		args: args(map[string]any{"ref": "main", "path": "model.txt", "raw": true, "start_line": 1}),
+		want: `{"result":{"path":"model.txt","lines":[{"line":1,"content":"weights"}]}}`,
+	},
+	{
+		tool: "get_file_content", name: "raw not found",
+		args:    args(map[string]any{"ref": "main", "path": "nope.md", "raw": true}),
+		wantErr: "get file err: The target couldn't be found.",
+	},
+	{
+		tool: "get_file_content", name: "canonical name wins over legacy",
+		args: args(map[string]any{"ref": "main", "filePath": "docs/guide.md", "path": "README.md"}),
+		want: `{"result":{"path":"README.md"}}`,
+	},
+	{
+		tool: "get_file_content", name: "not found",
+		args:    args(map[string]any{"ref": "main", "path": "nope.md"}),
+		wantErr: "get file err: object does not exist [id: , rel_path: nope.md]",
+	},
+	{
+		tool: "get_file_content", name: "missing path",
+		args:    args(map[string]any{"ref": "main"}),
+		wantErr: "path is required",
+	},
+	{
+		tool: "get_dir_content", name: "ok",
+		args: args(map[string]any{"ref": "feature", "path": "src"}),
+		want: `{"result":[{"name":"feature.go","type":"file"},{"name":"main.go","type":"file"}]}`,
+	},
+	{
+		tool: "get_dir_content", name: "root",
+		args: args(map[string]any{"ref": "main", "path": ""}),
+		want: `{"result":[{"name":"docs","type":"dir"},{"name":"src","type":"dir"},{"name":"README.md","type":"file"}]}`,
+	},
+	{
+		tool: "get_dir_content", name: "missing path",
+		args:    args(map[string]any{"ref": "main"}),
+		wantErr: "path is required",
+	},
+	{
+		tool: "get_repo_tree", name: "ok",
+		args: args(nil),
+		want: `{"result":{"entries":[{"path":"README.md","type":"blob","size":7},{"path":"docs","type":"tree"},{"path":"docs/guide.md","type":"blob","size":8},{"path":"src","type":"tree"},{"path":"src/main.go","type":"blob","sha":"` + giteatest.BlobSHA("package main\n") + `"}],"page":1,"total_count":5,"truncated":false,"capped":false}}`,
+	},
+	{
+		tool: "get_repo_tree", name: "path and depth",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).Commit("feature", "Add test", map[string]string{"src/internal/feature_test.go": "package internal\n"})
+		},
+		args: args(map[string]any{"ref": "feature", "path": "/src/", "max_depth": 1}),
+		want: `{"result":{"entries":[{"path":"src/feature.go"},{"path":"src/internal","type":"tree"},{"path":"src/main.go"}],"total_count":3}}`,
+	},
+	{
+		tool: "get_repo_tree", name: "include and exclude",
+		args: args(map[string]any{"ref": "feature", "include": []any{"*.go", "docs/**"}, "exclude": []any{"**/main.go"}}),
+		want: `{"result":{"entries":[{"path":"docs"},{"path":"docs/guide.md"},{"path":"src/feature.go"}]}}`,
+	},
+	{
+		tool: "get_repo_tree", name: "page",
+		args: args(map[string]any{"page": 2, "page_size": 2}),
+		want: `{"result":{"entries":[{"path":"docs/guide.md"},{"path":"src"}],"page":2,"truncated":true}}`,
+	},
+	{
+		tool: "get_repo_tree", name: "max entries",
+		args: args(map[string]any{"max_entries": 1}),
+		want: `{"result":{"entries":[{"path":"README.md"}],"truncated":false,"capped":true}}`,
+	},
+	{
+		tool: "get_repo_tree", name: "file path",
+		args:    args(map[string]any{"path": "docs/guide.md"}),
+		wantErr: "docs/guide.md is not a directory of test/demo at main",
+	},
+	{
+		tool: "get_repo_tree", name: "unknown path",
+		args:    args(map[string]any{"path": "nope/src"}),
+		wantErr: "get test/demo/nope/src err: object does not exist [id: , rel_path: nope]",
+	},
+	{
+		tool: "get_repo_tree", name: "unknown ref",
+		args:    args(map[string]any{"ref": "nope"}),
+		wantErr: "get test/demo tree err: sha not provided",
+	},
+	{
+		tool: "create_file", name: "ok",
+		args: args(map[string]any{"path": "docs/faq.md", "content": "# FAQ\n", "message": "Add FAQ", "branch": "main"}),
+		want: `{"result":"Create file success"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if content, _ := demo(fake).File("main", "docs/faq.md"); content != "# FAQ\n" {
+				t.Errorf("docs/faq.md = %q", content)
+			}
+		},
+	},
+	{
+		tool: "create_file", name: "new branch",
+		args: args(map[string]any{"path": "docs/faq.md", "content": "# FAQ\n", "message": "Add FAQ", "branch": "main", "new_branch": "faq"}),
+		want: `{"result":"Create file success"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if content, _ := demo(fake).File("faq", "docs/faq.md"); content != "# FAQ\n" {
+				t.Errorf("docs/faq.md on faq = %q", content)
+			}
+			if _, ok := demo(fake).File("main", "docs/faq.md"); ok {
+				t.Errorf("docs/faq.md was committed to main")
+			}
+		},
+	},
+	{
+		tool: "create_file", name: "legacy arguments",
+		args: args(map[string]any{"filePath": "docs/faq.md", "content": "# FAQ\n", "message": "Add FAQ", "branch_name": "main"}),
+		want: `{"result":"Create file success"}`,
+	},
+	{
+		tool: "create_file", name: "exists",
+		args:    args(map[string]any{"path": "README.md", "content": "x", "message": "Add README", "branch": "main"}),
+		wantErr: "create file err: repository file already exists [path: README.md]",
+	},
+	{
+		tool: "create_file", name: "missing path",
+		args:    args(map[string]any{"content": "x", "message": "m", "branch": "main"}),
+		wantErr: "path is required",
+	},
+	{
+		tool: "update_file", name: "ok",
+		args: args(map[string]any{"path": "README.md", "sha": giteatest.BlobSHA("# demo\n"), "content": "# Demo\n", "message": "Capitalize", "branch": "main"}),
+		want: `{"result":"Update file success"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if content, _ := demo(fake).File("main", "README.md"); content != "# Demo\n" {
+				t.Errorf("README.md = %q", content)
+			}
+		},
+	},
+	{
+		tool: "update_file", name: "stale sha",
+		args:    args(map[string]any{"path": "README.md", "sha": "0000000", "content": "x", "message": "m", "branch": "main"}),
+		wantErr: "update file err: sha does not match [given: 0000000, expected: " + giteatest.BlobSHA("# demo\n") + "]",
+	},
+	{
+		tool: "update_file", name: "missing sha",
+		args:    args(map[string]any{"path": "README.md", "content": "x", "message": "m", "branch": "main"}),
+		wantErr: "sha is required",
+	},
+	{
+		tool: "delete_file", name: "ok",
+		args: args(map[string]any{"path": "docs/guide.md", "sha": giteatest.BlobSHA("# Guide\n"), "message": "Remove guide", "branch": "main"}),
+		want: `{"result":"Delete file success"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if _, ok := demo(fake).File("main", "docs/guide.md"); ok {
+				t.Error("docs/guide.md still exists")
+			}
+		},
+	},
+	{
+		tool: "delete_file", name: "not found",
+		args:    args(map[string]any{"path": "nope.md", "sha": "0000000", "message": "m", "branch": "main"}),
+		wantErr: "delete file err: unexpected Status: 404",
+	},
+	{
+		tool: "delete_file", name: "missing sha",
+		args:    args(map[string]any{"path": "README.md", "message": "m", "branch": "main"}),
+		wantErr: "sha is required",
+	},
+	{
+		tool: "edit_file", name: "edits",
+		args: args(map[string]any{"path": "src/feature.go", "branch": "feature", "message": "Rename feature", "edits": []any{
+			map[string]any{"old_string": "func feature()", "new_string": "func Feature()"},
+			map[string]any{"old_string": "{}", "new_string": "{\n\tprintln()\n}"},
+		}}),
+		want: `{"result":{"sha":"` + giteatest.BlobSHA("package main\n\nfunc Feature() {\n\tprintln()\n}\n") + `","diff":"--- a/src/feature.go\n+++ b/src/feature.go\n@@ -1,3 +1,5 @@\n package main\n \n-func feature() {}\n+func Feature() {\n+\tprintln()\n+}\n"}}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if content, _ := demo(fake).File("feature", "src/feature.go"); content != "package main\n\nfunc Feature() {\n\tprintln()\n}\n" {
+				t.Errorf("src/feature.go = %q", content)
+			}
+		},
+	},
+	{
+		tool: "edit_file", name: "diff",
+		args: args(map[string]any{"path": "src/feature.go", "branch": "feature", "message": "Rename feature", "sha": giteatest.BlobSHA("package main\n\nfunc feature() {}\n"),
+			"diff": "@@ -3 +3 @@\n-func feature() {}\n+func Feature() {}\n"}),
+		want: `{"result":{"diff":"--- a/src/feature.go\n+++ b/src/feature.go\n@@ -1,3 +1,3 @@\n package main\n \n-func feature() {}\n+func Feature() {}\n"}}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if content, _ := demo(fake).File("feature", "src/feature.go"); content != "package main\n\nfunc Feature() {}\n" {
+				t.Errorf("src/feature.go = %q", content)
+			}
+		},
+	},
+	{
+		tool: "edit_file", name: "ambiguous",
+		args:    args(map[string]any{"path": "src/feature.go", "branch": "feature", "message": "m", "edits": []any{map[string]any{"old_string": "main", "new_string": "x"}, map[string]any{"old_string": "\n", "new_string": "\r\n"}}}),
+		wantErr: "edit src/feature.go err: edit 2: old_string found 3 times, add surrounding lines to make it unique or set replace_all",
+	},
+	{
+		tool: "edit_file", name: "replace all",
+		args: args(map[string]any{"path": "src/feature.go", "branch": "feature", "message": "m", "edits": []any{map[string]any{"old_string": "\n", "new_string": "\r\n", "replace_all": true}}}),
+		want: `{"result":{"sha":"` + giteatest.BlobSHA("package main\r\n\r\nfunc feature() {}\r\n") + `"}}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if content, _ := demo(fake).File("feature", "src/feature.go"); content != "package main\r\n\r\nfunc feature() {}\r\n" {
+				t.Errorf("src/feature.go = %q", content)
+			}
+		},
+	},
+	{
+		tool: "edit_file", name: "not found",
+		args:    args(map[string]any{"path": "src/feature.go", "branch": "feature", "message": "m", "edits": []any{map[string]any{"old_string": "func main", "new_string": "x"}}}),
+		wantErr: "edit src/feature.go err: edit 1: old_string not found",
+	},
+	{
+		tool: "edit_file", name: "diff does not apply",
+		args:    args(map[string]any{"path": "src/feature.go", "branch": "feature", "message": "m", "diff": "@@ -1 +1 @@\n-package lib\n+package main\n"}),
+		wantErr: "edit src/feature.go err: hunk 1 (@@ -1 +1 @@) does not apply: its lines were not found",
+	},
+	{
+		tool: "edit_file", name: "stale sha",
+		args:    args(map[string]any{"path": "src/feature.go", "branch": "feature", "message": "m", "sha": "0000000", "edits": []any{map[string]any{"old_string": "main", "new_string": "lib"}}}),
+		wantErr: "src/feature.go changed since 0000000, its SHA is now " + giteatest.BlobSHA("package main\n\nfunc feature() {}\n"),
+	},
+	{
+		tool: "edit_file", name: "unchanged",
+		args:    args(map[string]any{"path": "src/feature.go", "branch": "feature", "message": "m", "edits": []any{map[string]any{"old_string": "main", "new_string": "main"}}}),
+		wantErr: "the edits leave src/feature.go unchanged",
+	},
+	{
+		tool: "edit_file", name: "edits and diff",
+		args:    args(map[string]any{"path": "src/feature.go", "branch": "feature", "message": "m", "edits": []any{}, "diff": ""}),
+		wantErr: "either edits or diff is required",
+	},
+
+	// Tags
+	{
+		tool: "list_tags", name: "ok",
+		args: args(nil),
+		want: `{"result":[{"name":"v0.9.0"},{"name":"v1.0.0"}]}`,
+	},
+	{
+		tool: "get_tag", name: "ok",
+		args: args(map[string]any{"tag": "v0.9.0"}),
+		want: `{"result":{"name":"v0.9.0","message":"Beta"}}`,
+	},
+	{
+		tool: "get_tag", name: "not found",
+		args:    args(map[string]any{"tag": "v9"}),
+		wantErr: "get tag error: The target couldn't be found.",
+	},
+	{
+		tool: "create_tag", name: "ok",
+		args: args(map[string]any{"tag": "v1.1.0", "target": "feature", "message": "Feature"}),
+		want: `{"result":"Tag Created"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			tag, _ := demo(fake).Tag("v1.1.0")
+			feature, _ := demo(fake).Branch("feature")
+			if tag != feature {
+				t.Errorf("v1.1.0 = %s, want %s", tag, feature)
+			}
+		},
+	},
+	{
+		tool: "create_tag", name: "exists",
+		args:    args(map[string]any{"tag": "v0.9.0"}),
+		wantErr: "create tag error: tag v0.9.0 already exists",
+	},
+	{
+		tool: "create_tag", name: "missing name",
+		args:    args(nil),
+		wantErr: "tag is required",
+	},
+	{
+		tool: "delete_tag", name: "ok",
+		args: args(map[string]any{"tag": "v0.9.0"}),
+		want: `{"result":"Tag deleted"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if _, ok := demo(fake).Tag("v0.9.0"); ok {
+				t.Error("v0.9.0 still exists")
+			}
+		},
+	},
+	{
+		tool: "delete_tag", name: "released",
+		args:    args(map[string]any{"tag": "v1.0.0"}),
+		wantErr: "delete tag error: a tag attached to a release cannot be deleted directly",
+	},
+
+	// Releases
+	{
+		tool: "list_releases", name: "ok",
+		args: args(nil),
+		want: `{"result":[{"id":12,"tag_name":"v1.0.0","target_commitish":"main","title":"First release","draft":false,"prerelease":false}]}`,
+	},
+	{
+		tool: "list_releases", name: "pre-releases",
+		args: args(map[string]any{"is_pre_release": true}),
+		want: `{"result":[]}`,
+	},
+	{
+		tool: "get_release", name: "ok",
+		args: args(map[string]any{"id": 12}),
+		want: `{"result":{"id":12,"tag_name":"v1.0.0","name":"First release"}}`,
+	},
+	{
+		tool: "get_release", name: "missing id",
+		args:    args(nil),
+		wantErr: "id is required",
+	},
+	{
+		tool: "get_latest_release", name: "ok",
+		args: args(nil),
+		want: `{"result":{"id":12,"tag_name":"v1.0.0"}}`,
+	},
+	{
+		tool: "get_latest_release", name: "none",
+		args:    map[string]any{"owner": "acme", "repo": "infra"},
+		wantErr: "get latest release error: The target couldn't be found.",
+	},
+	{
+		tool: "create_release", name: "ok",
+		args: args(map[string]any{"tag": "v1.1.0", "target": "feature", "title": "Feature release", "is_pre_release": true}),
+		want: `{"result":"Release Created"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			releases := demo(fake).Releases()
+			if len(releases) != 2 || releases[0].TagName != "v1.1.0" || !releases[0].IsPrerelease {
+				t.Errorf("releases = %+v", releases)
+			}
+		},
+	},
+	{
+		tool: "create_release", name: "exists",
+		args:    args(map[string]any{"tag": "v1.0.0", "target": "main", "title": "Again"}),
+		wantErr: "create release error: release tag already exist [tag_name: v1.0.0]",
+	},
+	{
+		tool: "create_release", name: "missing title",
+		args:    args(map[string]any{"tag": "v1.1.0", "target": "main"}),
+		wantErr: "title is required",
+	},
+	{
+		tool: "delete_release", name: "ok",
+		args: args(map[string]any{"id": 12}),
+		want: `{"result":"Release deleted successfully"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if releases := demo(fake).Releases(); len(releases) != 0 {
+				t.Errorf("releases = %+v", releases)
+			}
+		},
+	},
+	{
+		tool: "delete_release", name: "not found",
+		args:    args(map[string]any{"id": 99}),
+		wantErr: "delete release error: The target couldn't be found.",
+	},
+
+	// Issues
+	{
+		tool: "get_issue_by_index", name: "ok",
+		args: args(map[string]any{"index": 1}),
+		want: `{"result":{"id":9,"number":1,"title":"Crash on start","state":"open","labels":[{"name":"bug"}],"comments":1}}`,
+	},
+	{
+		tool: "get_issue_by_index", name: "missing index",
+		args:    args(nil),
+		wantErr: "index is required",
+	},
+	{
+		tool: "list_repo_issues", name: "ok",
+		args: args(nil),
+		want: `{"result":[{"number":2,"title":"Add feature"},{"number":1,"title":"Crash on start"}]}`,
+	},
+	{
+		tool: "list_repo_issues", name: "closed",
+		args: args(map[string]any{"state": "closed"}),
+		want: `{"result":[]}`,
+	},
+	{
+		tool: "create_issue", name: "ok",
+		args: args(map[string]any{"title": "Write docs", "body": "The guide is empty."}),
+		want: `{"result":{"number":3,"title":"Write docs","body":"The guide is empty.","state":"open","user":{"login":"test"}}}`,
+	},
+	{
+		tool: "create_issue", name: "empty title",
+		args:    args(map[string]any{"title": "", "body": "b"}),
+		wantErr: "create test/demo/issue err: title is empty",
+	},
+	{
+		tool: "create_issue", name: "missing body",
+		args:    args(map[string]any{"title": "t"}),
+		wantErr: "body is required",
+	},
+	{
+		tool: "edit_issue", name: "ok",
+		args: args(map[string]any{"index": 1, "title": "Crash on startup", "state": "closed", "assignees": []any{"alice"}}),
+		want: `{"result":{"number":1,"title":"Crash on startup","body":"It crashes.","state":"closed","assignees":[{"login":"alice"}]}}`,
+	},
+	{
+		tool: "edit_issue", name: "unknown assignee",
+		args:    args(map[string]any{"index": 1, "assignees": []any{"bob"}}),
+		wantErr: "edit test/demo/issue/1 err: user does not exist [uid: 0, name: bob]",
+	},
+	{
+		tool: "edit_issue", name: "invalid assignee",
+		args:    args(map[string]any{"index": 1, "assignees": []any{2}}),
+		wantErr: "invalid username in assignees array",
+	},
+	{
+		tool: "create_issue_comment", name: "ok",
+		args: args(map[string]any{"index": 1, "body": "Fixed in main."}),
+		want: `{"result":{"id":14,"body":"Fixed in main.","user":{"login":"test"}}}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if n := demo(fake).Issue(1).Comments; n != 2 {
+				t.Errorf("comments = %d, want 2", n)
+			}
+		},
+	},
+	{
+		tool: "create_issue_comment", name: "no issue",
+		args:    args(map[string]any{"index": 42, "body": "b"}),
+		wantErr: "create test/demo/issue/42/comment err: The target couldn't be found.",
+	},
+	{
+		tool: "edit_issue_comment", name: "ok",
+		args: args(map[string]any{"comment_id": 10, "body": "Cannot reproduce."}),
+		want: `{"result":{"id":10,"body":"Cannot reproduce."}}`,
+	},
+	{
+		tool: "edit_issue_comment", name: "missing id",
+		args:    args(map[string]any{"body": "b"}),
+		wantErr: "comment ID is required",
+	},
+	{
+		tool: "get_issue_comments_by_index", name: "ok",
+		args: args(map[string]any{"index": 1}),
+		want: `{"result":[{"id":10,"body":"Reproduced."}]}`,
+	},
+	{
+		tool: "get_issue_comments_by_index", name: "no issue",
+		args:    args(map[string]any{"index": 42}),
+		wantErr: "get test/demo/issues/42/comments err: The target couldn't be found.",
+	},
+
+	// Labels
+	{
+		tool: "list_repo_labels", name: "ok",
+		args: args(nil),
+		want: `{"result":[{"id":7,"name":"bug","color":"ee0701"},{"id":8,"name":"enhancement","color":"a2eeef"}]}`,
+	},
+	{
+		tool: "get_repo_label", name: "ok",
+		args: args(map[string]any{"id": 8}),
+		want: `{"result":{"id":8,"name":"enhancement"}}`,
+	},
+	{
+		tool: "get_repo_label", name: "not found",
+		args:    args(map[string]any{"id": 99}),
+		wantErr: "get test/demo/label/99 err: The target couldn't be found.",
+	},
+	{
+		tool: "create_repo_label", name: "ok",
+		args: args(map[string]any{"name": "docs", "color": "#0075ca", "description": "Documentation"}),
+		want: `{"result":{"id":14,"name":"docs","color":"0075ca","description":"Documentation"}}`,
+	},
+	{
+		tool: "create_repo_label", name: "invalid color",
+		args:    args(map[string]any{"name": "docs", "color": "blue"}),
+		wantErr: "create test/demo/label err: invalid color format",
+	},
+	{
+		tool: "create_repo_label", name: "missing color",
+		args:    args(map[string]any{"name": "docs"}),
+		wantErr: "color is required",
+	},
+	{
+		tool: "edit_repo_label", name: "ok",
+		args: args(map[string]any{"id": 7, "name": "defect"}),
+		want: `{"result":{"id":7,"name":"defect","color":"ee0701"}}`,
+	},
+	{
+		tool: "edit_repo_label", name: "missing id",
+		args:    args(map[string]any{"name": "defect"}),
+		wantErr: "label ID is required",
+	},
+	{
+		tool: "delete_repo_label", name: "ok",
+		args: args(map[string]any{"id": 8}),
+		want: `{"result":"Label deleted successfully"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if labels := demo(fake).Labels(); len(labels) != 1 {
+				t.Errorf("labels = %+v", labels)
+			}
+		},
+	},
+	{
+		tool: "delete_repo_label", name: "not found",
+		args:    args(map[string]any{"id": 99}),
+		wantErr: "delete test/demo/label/99 err: The target couldn't be found.",
+	},
+	{
+		tool: "add_issue_labels", name: "ok",
+		args: args(map[string]any{"index": 1, "labels": []any{8}}),
+		want: `{"result":[{"name":"bug"},{"name":"enhancement"}]}`,
+	},
+	{
+		tool: "add_issue_labels", name: "unknown label",
+		args:    args(map[string]any{"index": 1, "labels": []any{99}}),
+		wantErr: "add labels to test/demo/issue/1 err: label does not exist [label_id: 99]",
+	},
+	{
+		tool: "add_issue_labels", name: "invalid label",
+		args:    args(map[string]any{"index": 1, "labels": []any{"bug"}}),
+		wantErr: "invalid label ID in labels array",
+	},
+	{
+		tool: "add_issue_labels", name: "missing labels",
+		args:    args(map[string]any{"index": 1}),
+		wantErr: "labels (array of IDs) is required",
+	},
+	{
+		tool: "replace_issue_labels", name: "ok",
+		args: args(map[string]any{"index": 1, "labels": []any{8}}),
+		want: `{"result":[{"name":"enhancement"}]}`,
+	},
+	{
+		tool: "replace_issue_labels", name: "missing index",
+		args:    args(map[string]any{"labels": []any{8}}),
+		wantErr: "issue index is required",
+	},
+	{
+		tool: "clear_issue_labels", name: "ok",
+		args: args(map[string]any{"index": 1}),
+		want: `{"result":"Labels cleared successfully"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if labels := demo(fake).Issue(1).Labels; len(labels) != 0 {
+				t.Errorf("labels = %+v", labels)
+			}
+		},
+	},
+	{
+		tool: "clear_issue_labels", name: "no issue",
+		args:    args(map[string]any{"index": 42}),
+		wantErr: "clear labels on test/demo/issue/42 err: The target couldn't be found.",
+	},
+	{
+		tool: "remove_issue_label", name: "ok",
+		args: args(map[string]any{"index": 1, "label_id": 7}),
+		want: `{"result":"Label removed successfully"}`,
+		check: func(t *testing.T, fake *giteatest.Server) {
+			if labels := demo(fake).Issue(1).Labels; len(labels) != 0 {
+				t.Errorf("labels = %+v", labels)
+			}
+		},
+	},
+	{
+		tool: "remove_issue_label", name: "missing label",
+		args:    args(map[string]any{"index": 1}),
+		wantErr: "label ID is required",
+	},
+
+	// Pull requests
+	{
+		tool: "get_pull_request_by_index", name: "ok",
+		args: args(map[string]any{"index": 2}),
+		want: `{"result":{"number":2,"title":"Add feature","state":"open","head":{"ref":"feature"},"base":{"ref":"main"}}}`,
+	},
+	{
+		tool: "get_pull_request_by_index", name: "issue",
+		args:    args(map[string]any{"index": 1}),
+		wantErr: "get test/demo/pr/1 err: The target couldn't be found.",
+	},
+	{
+		tool: "list_repo_pull_requests", name: "ok",
+		args: args(map[string]any{"state": "all"}),
+		want: `{"result":[{"number":2,"title":"Add feature"}]}`,
+	},
+	{
+		tool: "list_repo_pull_requests", name: "closed",
+		args: args(map[string]any{"state": "closed"}),
+		want: `{"result":[]}`,
+	},
+	{
+		tool: "create_pull_request", name: "ok",
+		setup: func(fake *giteatest.Server) {
+			demo(fake).Commit("docs", "Expand guide", map[string]string{"docs/guide.md": "# User guide\n"})
+		},
+		args: args(map[string]any{"title": "Expand guide", "body": "More docs.", "head": "docs", "base": "main"}),
+		want: `{"result":{"number":3,"title":"Expand guide","body":"More docs.","head":{"ref":"docs"},"base":{"ref":"main"}}}`,
+	},
+	{
+		tool: "create_pull_request", name: "no changes",
+		args:    args(map[string]any{"title": "t", "body": "b", "head": "main", "base": "main"}),
+		wantErr: "create test/demo/pull_request err: There are no changes between the head and the base",
+	},
+	{
+		tool: "create_pull_request", name: "missing base",
+		args:    args(map[string]any{"title": "t", "body": "b", "head": "feature"}),
+		wantErr: "base is required",
+	},
+
+	// Version
+	{
+		tool: "get_gitea_mcp_server_version", name: "ok",
+		want: `{"result":"Gitea MCP Server version: dev"}`,
+	},
+	{
+		tool: "get_gitea_server_version", name: "ok",
+		want: `{"result":"Gitea server version: ` + giteatest.Version + `"}`,
+	},
+
+	// Instances
+	{
+		tool: "list_gitea_instances", name: "ok",
+		want: `{"result":[{"name":"default","primary":true,"read_only":false}]}`,
+	},
+}
+
+func TestTools(t *testing.T) {
+	for _, tc := range toolTests {
+		t.Run(tc.tool+"/"+tc.name, func(t *testing.T) {
+			fake := giteatest.NewServer(t)
+			fake.Token = testToken
+			seed(fake)
+			if tc.setup != nil {
+				tc.setup(fake)
+			}
+			s := newTestServer(t, fake, false)
+
+			got, err := callTool(t, s, tc.tool, tc.args)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var want, v any
+			if err := json.Unmarshal([]byte(tc.want), &want); err != nil {
+				t.Fatalf("want is not JSON: %s", tc.want)
+			}
+			if err := json.Unmarshal([]byte(got), &v); err != nil {
+				t.Fatalf("result is not JSON: %s", got)
+			}
+			if !matchJSON(v, want) {
+				t.Errorf("result does not match\n got: %s\nwant: %s", got, tc.want)
+			}
+			schema := outputSchema(t, s, tc.tool)
+			defs, _ := schema["$defs"].(map[string]any)
+			if err := checkSchema(schema, defs, v, "structuredContent"); err != nil {
+				t.Errorf("result does not conform to the output schema: %v", err)
+			}
+			if tc.check != nil {
+				tc.check(t, fake)
+			}
+		})
+	}
+}
+
+func TestEveryToolIsTested(t *testing.T) {
+	tested := make(map[string]bool)
+	for _, tc := range toolTests {
+		tested[tc.tool] = true
+	}
+	for _, info := range tool.Registered() {
+		if !tested[info.Name] {
+			t.Errorf("%s has no test case", info.Name)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package user
+
+import (
+	"context"
+	"fmt"
+
+	"gitea.com/gitea/gitea-mcp/pkg/flag"
+	"gitea.com/gitea/gitea-mcp/pkg/gitea"
+	"gitea.com/gitea/gitea-mcp/pkg/log"
+	"gitea.com/gitea/gitea-mcp/pkg/to"
+	"gitea.com/gitea/gitea-mcp/pkg/tool"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// GetTokenCapabilitiesToolName is the unique tool name used for MCP registration and lookup of the get_token_capabilities command.
+const GetTokenCapabilitiesToolName = "get_token_capabilities"
+
+// GetTokenCapabilitiesTool is the MCP tool explaining what the current credential is allowed to do.
+var GetTokenCapabilitiesTool = mcp.NewTool(
+	GetTokenCapabilitiesToolName,
+	mcp.WithDescription("Get the scopes of the current Gitea token and the tools it cannot use"),
+	to.OutputSchema[tokenCapabilities](),
+)
+
+// tokenCapabilities is the result of get_token_capabilities: the detected
+// scopes plus every tool that is unavailable and why.
+type tokenCapabilities struct {
+	*gitea.Capabilities
+	ReadOnly         bool              `json:"read_only"`
+	UnavailableTools []unavailableTool `json:"unavailable_tools"`
+}
+
+type unavailableTool struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// GetTokenCapabilitiesFn is the handler for "get_token_capabilities" MCP tool requests.
+// It detects the token scopes of the selected instance and lists the tools that are
+// refused because of a missing scope or read-only mode.
+func GetTokenCapabilitiesFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("[User] Called GetTokenCapabilitiesFn")
+	caps, err := gitea.CapabilitiesFromContext(ctx)
+	if err != nil {
+		return to.ErrorResult(fmt.Errorf("get token capabilities err: %v", err))
+	}
+	inst, err := gitea.LookupInstance(gitea.InstanceFromContext(ctx))
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+
+	result := tokenCapabilities{
+		Capabilities:     caps,
+		ReadOnly:         flag.ReadOnly || inst.ReadOnly,
+		UnavailableTools: []unavailableTool{},
+	}
+	for _, info := range tool.Registered() {
+		switch {
+		case info.Write && result.ReadOnly:
+			result.UnavailableTools = append(result.UnavailableTools, unavailableTool{info.Name, "read-only mode"})
+		case !caps.Allows(info.Scope, info.Write):
+			result.UnavailableTools = append(result.UnavailableTools, unavailableTool{info.Name, "token lacks the " + info.RequiredScope() + " scope"})
+		}
+	}
+	return to.Result(result)
+}
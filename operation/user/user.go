@@ -35,15 +35,17 @@ var (
 	GetMyUserInfoTool = mcp.NewTool(
 		GetMyUserInfoToolName,
 		mcp.WithDescription("Get my user info"),
+		to.OutputSchema[*gitea_sdk.User](),
 	)
 
 	// GetUserOrgsTool is the MCP tool for listing organizations for the authenticated user.
-	// It supports pagination via "page" and "pageSize" arguments with default values specified above.
+	// It supports pagination via "page" and "page_size" arguments with default values specified above.
 	GetUserOrgsTool = mcp.NewTool(
 		GetUserOrgsToolName,
 		mcp.WithDescription("Get organizations associated with the authenticated user"),
 		mcp.WithNumber("page", mcp.Description("page number"), mcp.DefaultNumber(defaultPage)),
-		mcp.WithNumber("pageSize", mcp.Description("page size"), mcp.DefaultNumber(defaultPageSize)),
+		mcp.WithNumber("page_size", mcp.Description("page size"), mcp.DefaultNumber(defaultPageSize)),
+		to.OutputSchema[[]*gitea_sdk.Organization](),
 	)
 )
 
@@ -53,16 +55,12 @@ func init() {
 	registerTools()
 }
 
-// registerTools registers all local MCP tool definitions and their handler functions.
-// To add new functionality, append your tool/handler pair to the tools slice below.
+// registerTools registers all local MCP tool definitions and their handler functions,
+// each with the token scope it requires.
 func registerTools() {
-	tools := []server.ServerTool{
-		{Tool: GetMyUserInfoTool, Handler: GetUserInfoFn},
-		{Tool: GetUserOrgsTool, Handler: GetUserOrgsFn},
-	}
-	for _, t := range tools {
-		Tool.RegisterRead(t)
-	}
+	Tool.RegisterRead(server.ServerTool{Tool: GetMyUserInfoTool, Handler: GetUserInfoFn}, tool.Scope(gitea.ScopeUser))
+	Tool.RegisterRead(server.ServerTool{Tool: GetUserOrgsTool, Handler: GetUserOrgsFn}, tool.Scope(gitea.ScopeOrganization), tool.Alias("pageSize", "page_size"))
+	Tool.RegisterRead(server.ServerTool{Tool: GetTokenCapabilitiesTool, Handler: GetTokenCapabilitiesFn})
 }
 
 // getIntArg parses an integer argument from the MCP request arguments map.
@@ -79,11 +77,11 @@ func getIntArg(req mcp.CallToolRequest, name string, def int) int {
 // Logs invocation, fetches current user info from gitea, wraps result for MCP.
 func GetUserInfoFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	log.Debugf("[User] Called GetUserInfoFn")
-	user, _, err := gitea.Client().GetMyUserInfo()
+	user, _, err := gitea.ClientFromContext(ctx).GetMyUserInfo()
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("get user info err: %v", err))
 	}
-	return to.TextResult(user)
+	return to.Result(user)
 }
 
 // GetUserOrgsFn is the handler for "get_user_orgs" MCP tool requests.
@@ -92,7 +90,7 @@ func GetUserInfoFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 func GetUserOrgsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	log.Debugf("[User] Called GetUserOrgsFn")
 	page := getIntArg(req, "page", defaultPage)
-	pageSize := getIntArg(req, "pageSize", defaultPageSize)
+	pageSize := getIntArg(req, "page_size", defaultPageSize)
 
 	opt := gitea_sdk.ListOrgsOptions{
 		ListOptions: gitea_sdk.ListOptions{
@@ -100,9 +98,9 @@ func GetUserOrgsFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolR
 			PageSize: pageSize,
 		},
 	}
-	orgs, _, err := gitea.Client().ListMyOrgs(opt)
+	orgs, _, err := gitea.ClientFromContext(ctx).ListMyOrgs(opt)
 	if err != nil {
 		return to.ErrorResult(fmt.Errorf("get user orgs err: %v", err))
 	}
-	return to.TextResult(orgs)
+	return to.Result(orgs)
 }
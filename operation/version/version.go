@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"gitea.com/gitea/gitea-mcp/pkg/flag"
+	"gitea.com/gitea/gitea-mcp/pkg/gitea"
 	"gitea.com/gitea/gitea-mcp/pkg/log"
 	"gitea.com/gitea/gitea-mcp/pkg/to"
 	"gitea.com/gitea/gitea-mcp/pkg/tool"
@@ -17,11 +18,21 @@ var Tool = tool.New()
 
 const (
 	GetGiteaMCPServerVersion = "get_gitea_mcp_server_version"
+	GetGiteaServerVersion    = "get_gitea_server_version"
 )
 
-var GetGiteaMCPServerVersionTool = mcp.NewTool(
-	GetGiteaMCPServerVersion,
-	mcp.WithDescription("Get Gitea MCP Server Version"),
+var (
+	GetGiteaMCPServerVersionTool = mcp.NewTool(
+		GetGiteaMCPServerVersion,
+		mcp.WithDescription("Get Gitea MCP Server Version"),
+		to.OutputSchema[string](),
+	)
+
+	GetGiteaServerVersionTool = mcp.NewTool(
+		GetGiteaServerVersion,
+		mcp.WithDescription("Get the version of the Gitea server"),
+		to.OutputSchema[string](),
+	)
 )
 
 func init() {
@@ -29,6 +40,10 @@ func init() {
 		Tool:    GetGiteaMCPServerVersionTool,
 		Handler: GetGiteaMCPServerVersionFn,
 	})
+	Tool.RegisterRead(server.ServerTool{
+		Tool:    GetGiteaServerVersionTool,
+		Handler: GetGiteaServerVersionFn,
+	})
 }
 
 func GetGiteaMCPServerVersionFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -37,5 +52,14 @@ func GetGiteaMCPServerVersionFn(ctx context.Context, req mcp.CallToolRequest) (*
 	if version == "" {
 		version = "dev"
 	}
-	return to.TextResult(fmt.Sprintf("Gitea MCP Server version: %v", version))
+	return to.Result(fmt.Sprintf("Gitea MCP Server version: %v", version))
+}
+
+func GetGiteaServerVersionFn(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	log.Debugf("Called GetGiteaServerVersionFn")
+	version, err := gitea.ServerVersion(ctx)
+	if err != nil {
+		return to.ErrorResult(err)
+	}
+	return to.Result(fmt.Sprintf("Gitea server version: %v", version))
 }
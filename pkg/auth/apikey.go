@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// APIKeyHeader is the header clients send a static API key in.
+const APIKeyHeader = "X-API-Key"
+
+// LoadAPIKeys collects API keys from a comma separated list and from a file
+// holding one key per line. Blank lines and lines starting with # are ignored.
+func LoadAPIKeys(list, path string) ([]string, error) {
+	var keys []string
+	for _, key := range strings.Split(list, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if path == "" {
+		return keys, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open api keys file err: %v", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key := strings.TrimSpace(scanner.Text())
+		if key == "" || strings.HasPrefix(key, "#") {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read api keys file err: %v", err)
+	}
+	return keys, nil
+}
+
+// APIKeys returns a middleware rejecting requests that do not present one of
+// keys in the X-API-Key header: with 401 if no key is presented and 403 if
+// the key is wrong. If acceptBearer is set the key may also be sent as a
+// bearer token, which is not possible when OAuth2 is enabled.
+func APIKeys(keys []string, acceptBearer bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			presented := r.Header.Get(APIKeyHeader)
+			if presented == "" && acceptBearer {
+				presented, _ = bearerToken(r)
+			}
+			if presented == "" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="gitea-mcp"`)
+				http.Error(w, "missing API key", http.StatusUnauthorized)
+				return
+			}
+			if !validKey(keys, presented) {
+				http.Error(w, "invalid API key", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func validKey(keys []string, presented string) bool {
+	valid := 0
+	for _, key := range keys {
+		// Compare against every key so timing does not reveal which matched.
+		valid |= subtle.ConstantTimeCompare([]byte(key), []byte(presented))
+	}
+	return valid == 1
+}
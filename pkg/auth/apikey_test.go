@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAPIKeys(t *testing.T) {
+	tests := []struct {
+		name         string
+		header       string
+		bearer       string
+		acceptBearer bool
+		want         int
+	}{
+		{name: "valid key", header: "key-2", want: http.StatusOK},
+		{name: "missing key", want: http.StatusUnauthorized},
+		{name: "wrong key", header: "nope", want: http.StatusForbidden},
+		{name: "valid bearer", bearer: "key-1", acceptBearer: true, want: http.StatusOK},
+		{name: "wrong bearer", bearer: "nope", acceptBearer: true, want: http.StatusForbidden},
+		{name: "bearer not accepted", bearer: "key-1", want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := APIKeys([]string{"key-1", "key-2"}, tt.acceptBearer)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			if tt.header != "" {
+				req.Header.Set(APIKeyHeader, tt.header)
+			}
+			if tt.bearer != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.bearer)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
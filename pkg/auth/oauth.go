@@ -0,0 +1,314 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gitea.com/gitea/gitea-mcp/pkg/gitea"
+	"gitea.com/gitea/gitea-mcp/pkg/log"
+)
+
+const (
+	protectedResourcePath   = "/.well-known/oauth-protected-resource"
+	authorizationServerPath = "/.well-known/oauth-authorization-server"
+	openIDConfigurationPath = "/.well-known/openid-configuration"
+
+	// tokenCacheTTL is how long a validated access token is trusted before it
+	// is checked against the authorization server again.
+	tokenCacheTTL = 2 * time.Minute
+)
+
+var (
+	errInvalidToken  = errors.New("invalid access token")
+	errWrongAudience = fmt.Errorf("%w, it was issued to another application", errInvalidToken)
+)
+
+// OAuthOptions configures an OAuth guard.
+type OAuthOptions struct {
+	// Issuer is the URL of the authorization server, a Gitea instance.
+	Issuer string
+	// Instance names the Gitea instance of the issuer. Tokens are only used
+	// for calls to it.
+	Instance string
+	// ClientID and ClientSecret are the credentials of the OAuth2 application
+	// MCP clients authorize with. They authenticate the introspection of
+	// tokens, and tokens issued to other applications are rejected.
+	ClientID     string
+	ClientSecret string
+	// ResourceURL is the URL of the MCP endpoint advertised to clients. If
+	// empty it is derived from the incoming requests.
+	ResourceURL string
+	// TLSConfig is used to connect to the issuer, nil for the defaults.
+	TLSConfig *tls.Config
+}
+
+// OAuth protects the streamable HTTP endpoint with OAuth 2.1 bearer tokens
+// issued by Gitea's OAuth2 provider, as described by the MCP authorization
+// specification. Validated tokens are attached to the request context so
+// Gitea calls to the issuer's instance are made on behalf of the caller.
+type OAuth struct {
+	issuer       string
+	instance     string
+	clientID     string
+	clientSecret string
+	resourceURL  string
+	httpClient   *http.Client
+	now          func() time.Time
+
+	mu       sync.Mutex
+	metadata map[string]any
+	tokens   map[string]time.Time
+}
+
+// NewOAuth returns an OAuth guard configured by opts.
+func NewOAuth(opts OAuthOptions) (*OAuth, error) {
+	if opts.ClientID == "" || opts.ClientSecret == "" {
+		return nil, fmt.Errorf("oauth requires the client ID and secret of the OAuth2 application to validate tokens")
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = opts.TLSConfig
+	return &OAuth{
+		issuer:       strings.TrimSuffix(opts.Issuer, "/"),
+		instance:     opts.Instance,
+		clientID:     opts.ClientID,
+		clientSecret: opts.ClientSecret,
+		resourceURL:  strings.TrimSuffix(opts.ResourceURL, "/"),
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   10 * time.Second,
+		},
+		now:    time.Now,
+		tokens: make(map[string]time.Time),
+	}, nil
+}
+
+// RegisterMetadata mounts the discovery documents of the MCP endpoint at
+// endpointPath on mux. Clients fetch them before they hold any credentials.
+func (o *OAuth) RegisterMetadata(mux *http.ServeMux, endpointPath string) {
+	mux.HandleFunc(protectedResourcePath, o.handleProtectedResource(endpointPath))
+	mux.HandleFunc(protectedResourcePath+endpointPath, o.handleProtectedResource(endpointPath))
+	mux.HandleFunc(authorizationServerPath, o.handleAuthorizationServer)
+}
+
+// Middleware rejects requests without a valid bearer token and passes the
+// token on to the Gitea client of the request.
+func (o *OAuth) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			o.unauthorized(w, r, "")
+			return
+		}
+		if err := o.validate(r.Context(), token); err != nil {
+			if errors.Is(err, errInvalidToken) {
+				o.unauthorized(w, r, err.Error())
+				return
+			}
+			log.Errorf("validate access token err: %v", err)
+			http.Error(w, "authorization server unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(gitea.WithToken(r.Context(), o.instance, token)))
+	})
+}
+
+func (o *OAuth) handleProtectedResource(endpointPath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"resource":                 o.resource(r, endpointPath),
+			"resource_name":            "Gitea MCP Server",
+			"authorization_servers":    []string{o.issuer},
+			"bearer_methods_supported": []string{"header"},
+		})
+	}
+}
+
+// handleAuthorizationServer republishes the authorization server metadata for
+// clients implementing the 2025-03-26 revision of the specification, which
+// look it up on the MCP server itself.
+func (o *OAuth) handleAuthorizationServer(w http.ResponseWriter, r *http.Request) {
+	metadata, err := o.discover(r.Context())
+	if err != nil {
+		log.Errorf("discover authorization server err: %v", err)
+		http.Error(w, "authorization server unavailable", http.StatusBadGateway)
+		return
+	}
+	writeJSON(w, metadata)
+}
+
+func (o *OAuth) unauthorized(w http.ResponseWriter, r *http.Request, description string) {
+	challenge := fmt.Sprintf(`Bearer resource_metadata="%s"`, o.baseURL(r)+protectedResourcePath)
+	if description != "" {
+		challenge = fmt.Sprintf(`Bearer error="invalid_token", error_description="%s", resource_metadata="%s"`,
+			description, o.baseURL(r)+protectedResourcePath)
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	http.Error(w, "unauthorized", http.StatusUnauthorized)
+}
+
+// introspection is the part of an RFC 7662 token introspection response the
+// guard looks at.
+type introspection struct {
+	Active   bool     `json:"active"`
+	Audience audience `json:"aud"`
+	Expiry   int64    `json:"exp"`
+}
+
+// audience is the "aud" claim, a single string or a list of them.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(a))
+}
+
+// validate checks token with the introspection endpoint of the authorization
+// server: it must be active and issued to the configured application.
+// Successful checks are cached for tokenCacheTTL, or until the token expires
+// if that is sooner.
+func (o *OAuth) validate(ctx context.Context, token string) error {
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:])
+
+	o.mu.Lock()
+	expiry, ok := o.tokens[key]
+	o.mu.Unlock()
+	if ok && o.now().Before(expiry) {
+		return nil
+	}
+
+	endpoint := o.issuer + "/login/oauth/introspect"
+	if metadata, err := o.discover(ctx); err == nil {
+		if introspect, ok := metadata["introspection_endpoint"].(string); ok && introspect != "" {
+			endpoint = introspect
+		}
+	}
+
+	form := url.Values{"token": {token}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(o.clientID, o.clientSecret)
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request introspection err: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		// A 401 means the client credentials were rejected, which is not
+		// the caller's fault.
+		return fmt.Errorf("introspection endpoint returned %s", resp.Status)
+	}
+	var result introspection
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decode introspection response err: %v", err)
+	}
+	if !result.Active {
+		return errInvalidToken
+	}
+	if !slices.Contains(result.Audience, o.clientID) {
+		return errWrongAudience
+	}
+
+	now := o.now()
+	expiry = now.Add(tokenCacheTTL)
+	if result.Expiry != 0 && time.Unix(result.Expiry, 0).Before(expiry) {
+		expiry = time.Unix(result.Expiry, 0)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for k, exp := range o.tokens {
+		if now.After(exp) {
+			delete(o.tokens, k)
+		}
+	}
+	o.tokens[key] = expiry
+	return nil
+}
+
+// discover fetches the OpenID Connect discovery document of the issuer. Only
+// successful lookups are cached.
+func (o *OAuth) discover(ctx context.Context) (map[string]any, error) {
+	o.mu.Lock()
+	metadata := o.metadata
+	o.mu.Unlock()
+	if metadata != nil {
+		return metadata, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.issuer+openIDConfigurationPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", openIDConfigurationPath, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("decode %s err: %v", openIDConfigurationPath, err)
+	}
+
+	o.mu.Lock()
+	o.metadata = metadata
+	o.mu.Unlock()
+	return metadata, nil
+}
+
+func (o *OAuth) resource(r *http.Request, endpointPath string) string {
+	if o.resourceURL != "" {
+		return o.resourceURL
+	}
+	return o.baseURL(r) + endpointPath
+}
+
+func (o *OAuth) baseURL(r *http.Request) string {
+	if u, err := url.Parse(o.resourceURL); err == nil && o.resourceURL != "" {
+		return u.Scheme + "://" + u.Host
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Errorf("write json response err: %v", err)
+	}
+}
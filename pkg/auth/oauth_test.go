@@ -0,0 +1,232 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gitea.com/gitea/gitea-mcp/pkg/gitea"
+)
+
+const (
+	testClientID     = "mcp-client"
+	testClientSecret = "mcp-secret"
+)
+
+// stubIssuer is an OAuth2 authorization server answering introspection
+// requests from a fixed table of tokens.
+type stubIssuer struct {
+	*httptest.Server
+
+	mu             sync.Mutex
+	introspections int
+	// tokens maps access tokens to the client they were issued to.
+	tokens map[string]string
+}
+
+func newStubIssuer(t *testing.T) *stubIssuer {
+	s := &stubIssuer{tokens: map[string]string{
+		"good":  testClientID,
+		"other": "another-client",
+	}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+openIDConfigurationPath, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{
+			"issuer":                 s.URL,
+			"authorization_endpoint": s.URL + "/login/oauth/authorize",
+			"token_endpoint":         s.URL + "/login/oauth/access_token",
+			"introspection_endpoint": s.URL + "/login/oauth/introspect",
+		})
+	})
+	mux.HandleFunc("POST /login/oauth/introspect", func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, ok := r.BasicAuth(); !ok || id != testClientID || secret != testClientSecret {
+			http.Error(w, "invalid client", http.StatusUnauthorized)
+			return
+		}
+		s.mu.Lock()
+		s.introspections++
+		client, ok := s.tokens[r.PostFormValue("token")]
+		s.mu.Unlock()
+		if !ok {
+			writeJSON(w, map[string]any{"active": false})
+			return
+		}
+		writeJSON(w, map[string]any{
+			"active": true,
+			"aud":    []string{client},
+			"exp":    time.Now().Add(time.Hour).Unix(),
+		})
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *stubIssuer) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.introspections
+}
+
+func newTestOAuth(t *testing.T, issuer *stubIssuer) *OAuth {
+	o, err := NewOAuth(OAuthOptions{
+		Issuer:       issuer.URL,
+		Instance:     "default",
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		ResourceURL:  "https://mcp.example.com/mcp",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
+
+// serve sends a request with the bearer token through the OAuth middleware
+// and returns the response and the token the protected handler received.
+func serve(o *OAuth, token string) (*httptest.ResponseRecorder, string) {
+	var received string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = gitea.TokenFromContext(gitea.WithInstance(r.Context(), "default"))
+	})
+	req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	o.Middleware(next).ServeHTTP(rec, req)
+	return rec, received
+}
+
+func TestOAuthMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		token     string
+		wantCode  int
+		wantError string
+	}{
+		{name: "valid token", token: "good", wantCode: http.StatusOK},
+		{name: "missing token", wantCode: http.StatusUnauthorized},
+		{name: "invalid token", token: "bad", wantCode: http.StatusUnauthorized, wantError: `error="invalid_token"`},
+		{name: "other audience", token: "other", wantCode: http.StatusUnauthorized, wantError: "issued to another application"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOAuth(t, newStubIssuer(t))
+			rec, received := serve(o, tt.token)
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+			if tt.wantCode == http.StatusOK {
+				if received != tt.token {
+					t.Errorf("handler received token %q, want %q", received, tt.token)
+				}
+				return
+			}
+			challenge := rec.Header().Get("WWW-Authenticate")
+			if !strings.Contains(challenge, `resource_metadata="https://mcp.example.com/.well-known/oauth-protected-resource"`) {
+				t.Errorf("WWW-Authenticate = %q, want the resource metadata URL", challenge)
+			}
+			if !strings.Contains(challenge, tt.wantError) {
+				t.Errorf("WWW-Authenticate = %q, want it to contain %q", challenge, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestOAuthWrongClientCredentials(t *testing.T) {
+	issuer := newStubIssuer(t)
+	o, err := NewOAuth(OAuthOptions{
+		Issuer:       issuer.URL,
+		Instance:     "default",
+		ClientID:     testClientID,
+		ClientSecret: "wrong",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The caller is not at fault, so it is not told its token is invalid.
+	if rec, _ := serve(o, "good"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestOAuthTokenCache(t *testing.T) {
+	issuer := newStubIssuer(t)
+	o := newTestOAuth(t, issuer)
+	now := time.Now()
+	o.now = func() time.Time { return now }
+
+	for range 3 {
+		if rec, _ := serve(o, "good"); rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+	}
+	if got := issuer.count(); got != 1 {
+		t.Errorf("introspections within the cache TTL = %d, want 1", got)
+	}
+
+	now = now.Add(tokenCacheTTL + time.Second)
+	if rec, _ := serve(o, "good"); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := issuer.count(); got != 2 {
+		t.Errorf("introspections after the cache TTL = %d, want 2", got)
+	}
+
+	// A revoked token is noticed once the cached check expires.
+	issuer.mu.Lock()
+	delete(issuer.tokens, "good")
+	issuer.mu.Unlock()
+	now = now.Add(tokenCacheTTL + time.Second)
+	if rec, _ := serve(o, "good"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("status after revocation = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestOAuthMetadata(t *testing.T) {
+	issuer := newStubIssuer(t)
+	o := newTestOAuth(t, issuer)
+	mux := http.NewServeMux()
+	o.RegisterMetadata(mux, "/mcp")
+
+	get := func(path string) map[string]any {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s status = %d, want %d", path, rec.Code, http.StatusOK)
+		}
+		var doc map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		return doc
+	}
+
+	for _, path := range []string{protectedResourcePath, protectedResourcePath + "/mcp"} {
+		doc := get(path)
+		if doc["resource"] != "https://mcp.example.com/mcp" {
+			t.Errorf("GET %s resource = %v", path, doc["resource"])
+		}
+		servers, _ := doc["authorization_servers"].([]any)
+		if len(servers) != 1 || servers[0] != issuer.URL {
+			t.Errorf("GET %s authorization_servers = %v, want [%s]", path, servers, issuer.URL)
+		}
+	}
+
+	doc := get(authorizationServerPath)
+	if doc["token_endpoint"] != issuer.URL+"/login/oauth/access_token" {
+		t.Errorf("authorization server metadata token_endpoint = %v", doc["token_endpoint"])
+	}
+}
+
+func TestNewOAuthRequiresClientCredentials(t *testing.T) {
+	if _, err := NewOAuth(OAuthOptions{Issuer: "https://gitea.example.com", ClientID: testClientID}); err == nil {
+		t.Error("NewOAuth without client secret succeeded")
+	}
+}
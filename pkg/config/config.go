@@ -0,0 +1,302 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// EnvPath is the environment variable selecting the configuration file.
+	EnvPath = "GITEA_MCP_CONFIG"
+
+	// SecretFile is where Docker mounts a secret named gitea_access_token. It
+	// is used when no other token is configured.
+	SecretFile = "/run/secrets/gitea_access_token"
+)
+
+const redacted = "<redacted>"
+
+// Config is the effective configuration of the server. It is assembled from,
+// by increasing priority, defaults, the configuration file, the environment
+// and command-line flags.
+type Config struct {
+	Host          string `yaml:"host"`
+	Token         string `yaml:"token,omitempty"`
+	TokenFile     string `yaml:"token_file,omitempty"`
+	TokenCommand  string `yaml:"token_command,omitempty"`
+	GitCredential bool   `yaml:"git_credential,omitempty"`
+	Insecure      bool   `yaml:"insecure"`
+	CAFile        string `yaml:"ca_file,omitempty"`
+	Instances     string `yaml:"instances,omitempty"`
+
+	Transport string `yaml:"transport"`
+	Port      int    `yaml:"port"`
+
+	OAuth   OAuth   `yaml:"oauth"`
+	TLS     TLS     `yaml:"tls"`
+	APIKeys APIKeys `yaml:"api_keys"`
+
+	Logging  Logging  `yaml:"logging"`
+	Policy   Policy   `yaml:"policy"`
+	Timeouts Timeouts `yaml:"timeouts"`
+}
+
+type OAuth struct {
+	Enabled      bool   `yaml:"enabled"`
+	Issuer       string `yaml:"issuer,omitempty"`
+	ClientID     string `yaml:"client_id,omitempty"`
+	ClientSecret string `yaml:"client_secret,omitempty"`
+	PublicURL    string `yaml:"public_url,omitempty"`
+}
+
+type TLS struct {
+	Cert     string `yaml:"cert,omitempty"`
+	Key      string `yaml:"key,omitempty"`
+	ClientCA string `yaml:"client_ca,omitempty"`
+}
+
+type APIKeys struct {
+	Keys string `yaml:"keys,omitempty"`
+	File string `yaml:"file,omitempty"`
+}
+
+type Logging struct {
+	Debug bool   `yaml:"debug"`
+	Dir   string `yaml:"dir,omitempty"`
+}
+
+type Policy struct {
+	ReadOnly bool `yaml:"read_only"`
+}
+
+type Timeouts struct {
+	// Request bounds every request to Gitea, zero means no limit.
+	Request  Duration `yaml:"request"`
+	Shutdown Duration `yaml:"shutdown"`
+}
+
+// Duration is a time.Duration written as a string such as "30s".
+type Duration time.Duration
+
+func (d Duration) MarshalYAML() (any, error) {
+	return time.Duration(d).String(), nil
+}
+
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	v, err := time.ParseDuration(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %v", node.Line, err)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Default returns the built-in defaults.
+func Default() *Config {
+	return &Config{
+		Host:      "https://gitea.com",
+		Transport: "stdio",
+		Port:      8080,
+		Timeouts: Timeouts{
+			Shutdown: Duration(30 * time.Second),
+		},
+	}
+}
+
+// DefaultPath returns the configuration file used when none is given:
+// $GITEA_MCP_CONFIG, or ~/.gitea-mcp/config.yaml.
+func DefaultPath() string {
+	if path := os.Getenv(EnvPath); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".gitea-mcp", "config.yaml")
+}
+
+// Load reads the file at path over the defaults. A missing file is only an
+// error if it was asked for explicitly.
+func Load(path string, explicit bool) (*Config, error) {
+	cfg := Default()
+	if path == "" {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("read config file err: %v", err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse config file %s err: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// ApplyEnv overrides the configuration with the environment variables that
+// are set.
+func (c *Config) ApplyEnv() error {
+	if err := c.applyTokenEnv(); err != nil {
+		return err
+	}
+
+	strs := map[string]*string{
+		"GITEA_HOST":                &c.Host,
+		"GITEA_CA_FILE":             &c.CAFile,
+		"GITEA_INSTANCES":           &c.Instances,
+		"MCP_MODE":                  &c.Transport,
+		"GITEA_OAUTH_ISSUER":        &c.OAuth.Issuer,
+		"GITEA_OAUTH_CLIENT_ID":     &c.OAuth.ClientID,
+		"GITEA_OAUTH_CLIENT_SECRET": &c.OAuth.ClientSecret,
+		"GITEA_MCP_PUBLIC_URL":      &c.OAuth.PublicURL,
+		"GITEA_MCP_TLS_CERT":        &c.TLS.Cert,
+		"GITEA_MCP_TLS_KEY":         &c.TLS.Key,
+		"GITEA_MCP_TLS_CLIENT_CA":   &c.TLS.ClientCA,
+		"GITEA_MCP_API_KEYS":        &c.APIKeys.Keys,
+		"GITEA_MCP_API_KEYS_FILE":   &c.APIKeys.File,
+		"GITEA_MCP_LOG_DIR":         &c.Logging.Dir,
+	}
+	for key, field := range strs {
+		if v := os.Getenv(key); v != "" {
+			*field = v
+		}
+	}
+
+	bools := map[string]*bool{
+		"GITEA_INSECURE": &c.Insecure,
+		"GITEA_READONLY": &c.Policy.ReadOnly,
+		"GITEA_DEBUG":    &c.Logging.Debug,
+		"GITEA_OAUTH":    &c.OAuth.Enabled,
+	}
+	for key, field := range bools {
+		if v := os.Getenv(key); v != "" {
+			b, err := parseBool(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %v", key, err)
+			}
+			*field = b
+		}
+	}
+
+	if v := os.Getenv("GITEA_MCP_PORT"); v != "" {
+		port, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid GITEA_MCP_PORT: %v", err)
+		}
+		c.Port = port
+	}
+
+	durations := map[string]*Duration{
+		"GITEA_MCP_REQUEST_TIMEOUT":  &c.Timeouts.Request,
+		"GITEA_MCP_SHUTDOWN_TIMEOUT": &c.Timeouts.Shutdown,
+	}
+	for key, field := range durations {
+		if v := os.Getenv(key); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %v", key, err)
+			}
+			*field = Duration(d)
+		}
+	}
+	return nil
+}
+
+func (c *Config) applyTokenEnv() error {
+	token := os.Getenv("GITEA_ACCESS_TOKEN")
+	file := os.Getenv("GITEA_ACCESS_TOKEN_FILE")
+	command := os.Getenv("GITEA_TOKEN_COMMAND")
+	git := os.Getenv("GITEA_GIT_CREDENTIAL")
+	if token == "" && file == "" && command == "" && git == "" {
+		return nil
+	}
+	c.ClearToken()
+	c.Token = token
+	c.TokenFile = file
+	c.TokenCommand = command
+	if git != "" {
+		b, err := parseBool(git)
+		if err != nil {
+			return fmt.Errorf("invalid GITEA_GIT_CREDENTIAL: %v", err)
+		}
+		c.GitCredential = b
+	}
+	return nil
+}
+
+// HasToken reports whether any token option is set.
+func (c *Config) HasToken() bool {
+	return c.Token != "" || c.TokenFile != "" || c.TokenCommand != "" || c.GitCredential
+}
+
+// ClearToken unsets every token option, so the token configured by a higher
+// layer is not overridden by a lower one of another kind.
+func (c *Config) ClearToken() {
+	c.Token = ""
+	c.TokenFile = ""
+	c.TokenCommand = ""
+	c.GitCredential = false
+}
+
+// UseSecretFile points TokenFile at the Docker secret of the token if no
+// token is configured and the secret exists.
+func (c *Config) UseSecretFile() {
+	if c.HasToken() {
+		return
+	}
+	if _, err := os.Stat(SecretFile); err == nil {
+		c.TokenFile = SecretFile
+	}
+}
+
+// parseBool parses a boolean environment variable. Besides the values of
+// strconv.ParseBool it accepts yes/no and on/off, which were set before the
+// variables were validated.
+func parseBool(v string) (bool, error) {
+	switch strings.ToLower(v) {
+	case "yes", "y", "on":
+		return true, nil
+	case "no", "n", "off":
+		return false, nil
+	}
+	return strconv.ParseBool(v)
+}
+
+// Redacted returns a copy of the configuration with secrets masked, suitable
+// for printing.
+func (c *Config) Redacted() *Config {
+	r := *c
+	if r.Token != "" {
+		r.Token = redacted
+	}
+	if r.TokenCommand != "" {
+		r.TokenCommand = redacted
+	}
+	if r.APIKeys.Keys != "" {
+		r.APIKeys.Keys = redacted
+	}
+	if r.OAuth.ClientSecret != "" {
+		r.OAuth.ClientSecret = redacted
+	}
+	return &r
+}
+
+// String renders the configuration as YAML.
+func (c *Config) String() string {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
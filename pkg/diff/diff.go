@@ -0,0 +1,307 @@
+// Package diff creates and applies unified diffs of text files.
+package diff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxLCSCells bounds the table of the line matching. Changed regions larger
+// than that are diffed as a removal followed by an addition.
+const maxLCSCells = 1 << 21
+
+const noNewline = "\\ No newline at end of file\n"
+
+type op struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// Unified returns the unified diff turning a into b with context lines around
+// each change, or "" if they are equal. The headers name the file p.
+func Unified(p, a, b string, context int) string {
+	ops := lineOps(splitLines(a), splitLines(b))
+	var sb strings.Builder
+	oldLine, newLine := 0, 0
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+		if sb.Len() == 0 {
+			fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", p, p)
+		}
+		// A hunk starts context lines before the change and runs until a
+		// run of more than two contexts of unchanged lines.
+		start := max(i-context, 0)
+		for j := start; j < i; j++ {
+			oldLine--
+			newLine--
+		}
+		end, unchanged := i, 0
+		for j := i; j < len(ops) && unchanged <= 2*context; j++ {
+			if ops[j].kind == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+				end = j + 1
+			}
+		}
+		end = min(end+context, len(ops))
+		oldCount, newCount := 0, 0
+		for _, o := range ops[start:end] {
+			if o.kind != '+' {
+				oldCount++
+			}
+			if o.kind != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(oldLine, oldCount), hunkRange(newLine, newCount))
+		for _, o := range ops[start:end] {
+			sb.WriteByte(o.kind)
+			sb.WriteString(o.line)
+			if !strings.HasSuffix(o.line, "\n") {
+				sb.WriteString("\n" + noNewline)
+			}
+		}
+		oldLine += oldCount
+		newLine += newCount
+		i = end
+	}
+	return sb.String()
+}
+
+// Matches returns for each line of a the index of the line of b it is kept
+// as, or -1 if b does not keep it. A missing newline at the end is ignored.
+func Matches(a, b string) []int {
+	trim := func(lines []string) []string {
+		for i, l := range lines {
+			lines[i] = strings.TrimSuffix(l, "\n")
+		}
+		return lines
+	}
+	m := make([]int, 0, strings.Count(a, "\n")+1)
+	j := 0
+	for _, o := range lineOps(trim(splitLines(a)), trim(splitLines(b))) {
+		switch o.kind {
+		case ' ':
+			m = append(m, j)
+			j++
+		case '-':
+			m = append(m, -1)
+		case '+':
+			j++
+		}
+	}
+	return m
+}
+
+// hunkRange formats the range of a hunk header the way git does: the line
+// before the hunk if it is empty, and no count if it is one line.
+func hunkRange(before, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", before)
+	case 1:
+		return strconv.Itoa(before + 1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+// lineOps matches the lines of a and b, keeping the longest common
+// subsequence of the region between their common prefix and suffix.
+func lineOps(a, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	ops := make([]op, 0, len(a)+len(b))
+	for _, l := range a[:prefix] {
+		ops = append(ops, op{' ', l})
+	}
+	am, bm := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(am)*len(bm) > maxLCSCells {
+		for _, l := range am {
+			ops = append(ops, op{'-', l})
+		}
+		for _, l := range bm {
+			ops = append(ops, op{'+', l})
+		}
+	} else {
+		ops = append(ops, lcsOps(am, bm)...)
+	}
+	for _, l := range a[len(a)-suffix:] {
+		ops = append(ops, op{' ', l})
+	}
+	return ops
+}
+
+func lcsOps(a, b []string) []op {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	w := len(b) + 1
+	lcs := make([]int32, (len(a)+1)*w)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i*w+j] = lcs[(i+1)*w+j+1] + 1
+			} else {
+				lcs[i*w+j] = max(lcs[(i+1)*w+j], lcs[i*w+j+1])
+			}
+		}
+	}
+	var ops []op
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, op{' ', a[i]})
+			i++
+			j++
+		case j == len(b) || i < len(a) && lcs[(i+1)*w+j] >= lcs[i*w+j+1]:
+			ops = append(ops, op{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, op{'+', b[j]})
+			j++
+		}
+	}
+	return ops
+}
+
+// splitLines splits s after each newline. The last line has none if s does
+// not end with one.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+type hunk struct {
+	header   string
+	oldStart int
+	old, new []string
+}
+
+// Apply applies the unified diff patch of a single file to content. The line
+// counts of hunk headers are not checked, and a hunk whose lines moved is
+// applied at the nearest place they are found, like patch does.
+func Apply(content, patch string) (string, error) {
+	hunks, err := parse(patch)
+	if err != nil {
+		return "", err
+	}
+	lines := splitLines(content)
+	// offset is how much the hunks applied so far moved the lines below.
+	offset, next := 0, 0
+	for n, h := range hunks {
+		// The header of a hunk without old lines names the line before it.
+		hint := h.oldStart - 1
+		if len(h.old) == 0 {
+			hint = h.oldStart
+		}
+		at := find(lines, h.old, max(hint+offset, next), next)
+		if at < 0 {
+			return "", fmt.Errorf("hunk %d (%s) does not apply: its lines were not found", n+1, h.header)
+		}
+		lines = append(lines[:at], append(append([]string{}, h.new...), lines[at+len(h.old):]...)...)
+		offset += len(h.new) - len(h.old)
+		next = at + len(h.new)
+	}
+	return strings.Join(lines, ""), nil
+}
+
+// find returns the position at or after from where lines contain old that is
+// nearest to hint, or -1.
+func find(lines, old []string, hint, from int) int {
+	hint = min(hint, len(lines))
+	matches := func(at int) bool {
+		if at < from || at+len(old) > len(lines) {
+			return false
+		}
+		for i, l := range old {
+			if lines[at+i] != l {
+				return false
+			}
+		}
+		return true
+	}
+	for d := 0; hint-d >= from || hint+d <= len(lines); d++ {
+		if matches(hint - d) {
+			return hint - d
+		}
+		if matches(hint + d) {
+			return hint + d
+		}
+	}
+	return -1
+}
+
+func parse(patch string) ([]hunk, error) {
+	var hunks []hunk
+	lines := splitLines(patch)
+	// last is the hunk line a "\ No newline" marker refers to.
+	var last []*string
+	for i := 0; i < len(lines); i++ {
+		l := lines[i]
+		switch {
+		case strings.HasPrefix(l, "@@"):
+			h := hunk{header: strings.TrimSpace(l)}
+			if _, err := fmt.Sscanf(l, "@@ -%d", &h.oldStart); err != nil {
+				return nil, fmt.Errorf("invalid hunk header %q", h.header)
+			}
+			hunks = append(hunks, h)
+			last = nil
+		case strings.HasPrefix(l, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			if len(hunks) > 0 {
+				return nil, fmt.Errorf("the diff changes more than one file")
+			}
+			i++
+		case len(hunks) == 0:
+			// diff and index lines before the file headers
+		case l == noNewline:
+			for _, s := range last {
+				*s = strings.TrimSuffix(*s, "\n")
+			}
+		default:
+			h := &hunks[len(hunks)-1]
+			if !strings.HasSuffix(l, "\n") {
+				l += "\n"
+			}
+			kind, text := l[0], l[1:]
+			if l == "\n" {
+				// an empty context line whose space was stripped
+				kind, text = ' ', "\n"
+			}
+			last = nil
+			switch kind {
+			case ' ':
+				h.old = append(h.old, text)
+				h.new = append(h.new, text)
+				last = []*string{&h.old[len(h.old)-1], &h.new[len(h.new)-1]}
+			case '-':
+				h.old = append(h.old, text)
+				last = []*string{&h.old[len(h.old)-1]}
+			case '+':
+				h.new = append(h.new, text)
+				last = []*string{&h.new[len(h.new)-1]}
+			default:
+				return nil, fmt.Errorf("invalid line in hunk %s: %q", h.header, strings.TrimSuffix(l, "\n"))
+			}
+		}
+	}
+	if len(hunks) == 0 {
+		return nil, fmt.Errorf("the diff has no hunks")
+	}
+	return hunks, nil
+}
@@ -0,0 +1,148 @@
+package diff
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name, a, b, want string
+	}{
+		{"equal", "a\nb\n", "a\nb\n", ""},
+		{
+			"change",
+			"1\n2\n3\n4\n5\n",
+			"1\n2\nthree\n4\n5\n",
+			"--- a/f.txt\n+++ b/f.txt\n@@ -2,3 +2,3 @@\n 2\n-3\n+three\n 4\n",
+		},
+		{
+			"insert at start",
+			"b\n",
+			"a\nb\n",
+			"--- a/f.txt\n+++ b/f.txt\n@@ -1 +1,2 @@\n+a\n b\n",
+		},
+		{
+			"new file",
+			"",
+			"a\n",
+			"--- a/f.txt\n+++ b/f.txt\n@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			"no newline at end",
+			"a\nb",
+			"a\nc",
+			"--- a/f.txt\n+++ b/f.txt\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+		{
+			"separate hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			"one\n2\n3\n4\n5\n6\n7\n8\nnine\n",
+			"--- a/f.txt\n+++ b/f.txt\n@@ -1,2 +1,2 @@\n-1\n+one\n 2\n@@ -8,2 +8,2 @@\n 8\n-9\n+nine\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			context := 1
+			if got := Unified("f.txt", tt.a, tt.b, context); got != tt.want {
+				t.Errorf("Unified\n got: %q\nwant: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	got := Matches("a\nb\nc\nd", "a\nx\nc\nd\ne\n")
+	want := []int{0, -1, 2, 3}
+	if !slices.Equal(got, want) {
+		t.Errorf("Matches = %v, want %v", got, want)
+	}
+	if got := Matches("", "a\n"); len(got) != 0 {
+		t.Errorf("Matches of an empty file = %v", got)
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name, content, patch, want, wantErr string
+	}{
+		{
+			name:    "change",
+			content: "1\n2\n3\n4\n5\n",
+			patch:   "--- a/f.txt\n+++ b/f.txt\n@@ -2,3 +2,3 @@\n 2\n-3\n+three\n 4\n",
+			want:    "1\n2\nthree\n4\n5\n",
+		},
+		{
+			name:    "moved lines and wrong counts",
+			content: "0\n0\n1\n2\n3\n",
+			patch:   "@@ -1,1 +1,1 @@\n 2\n-3\n+three\n",
+			want:    "0\n0\n1\n2\nthree\n",
+		},
+		{
+			name:    "insert after line",
+			content: "a\nc\n",
+			patch:   "@@ -1,0 +2 @@\n+b\n",
+			want:    "a\nb\nc\n",
+		},
+		{
+			name:    "stripped empty context line",
+			content: "a\n\nb\n",
+			patch:   "@@ -1,3 +1,3 @@\n a\n\n-b\n+c\n",
+			want:    "a\n\nc\n",
+		},
+		{
+			name:    "no newline at end",
+			content: "a\nb",
+			patch:   "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+			want:    "a\nb\n",
+		},
+		{
+			name:    "missing lines",
+			content: "a\nb\n",
+			patch:   "@@ -1 +1 @@\n-c\n+d\n",
+			wantErr: "hunk 1 (@@ -1 +1 @@) does not apply: its lines were not found",
+		},
+		{
+			name:    "two files",
+			content: "a\n",
+			patch:   "--- a/x\n+++ b/x\n@@ -1 +1 @@\n-a\n+b\n--- a/y\n+++ b/y\n@@ -1 +1 @@\n-a\n+b\n",
+			wantErr: "the diff changes more than one file",
+		},
+		{
+			name:    "no hunks",
+			content: "a\n",
+			patch:   "a\n",
+			wantErr: "the diff has no hunks",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply(tt.content, tt.patch)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyUnified(t *testing.T) {
+	a := strings.Repeat("line\n", 20) + "x\ny\n" + strings.Repeat("line\n", 20)
+	b := "first\n" + strings.Repeat("line\n", 20) + "y\nz\n" + strings.Repeat("line\n", 19) + "last"
+	got, err := Apply(a, Unified("f", a, b, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != b {
+		t.Errorf("got %q, want %q", got, b)
+	}
+}
@@ -1,5 +1,7 @@
 package flag
 
+import "time"
+
 var (
 	Host    string
 	Port    int
@@ -7,7 +9,30 @@ var (
 	Version string
 	Mode    string
 
+	TokenFile     string
+	TokenCommand  string
+	GitCredential bool
+
+	InstancesFile string
+
+	OAuth             bool
+	OAuthIssuer       string
+	OAuthClientID     string
+	OAuthClientSecret string
+	PublicURL         string
+
+	TLSCert     string
+	TLSKey      string
+	TLSClientCA string
+	APIKeys     string
+	APIKeysFile string
+
+	RequestTimeout  time.Duration
+	ShutdownTimeout time.Duration
+	LogDir          string
+
 	Insecure bool
+	CAFile   string
 	ReadOnly bool
 	Debug    bool
 )
@@ -1,10 +1,12 @@
 package gitea
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"net/http"
 	"sync"
+	"time"
 
 	"gitea.com/gitea/gitea-mcp/pkg/flag"
 	"gitea.com/gitea/gitea-mcp/pkg/log"
@@ -12,41 +14,260 @@ import (
 	"code.gitea.io/sdk/gitea"
 )
 
+// tokenClientIdleTimeout is how long a client created for a per-request
+// token is kept after its last use.
+const tokenClientIdleTimeout = 30 * time.Minute
+
 var (
-	client     *gitea.Client
-	clientOnce sync.Once
+	clientsMu sync.Mutex
+	clients   = map[string]*gitea.Client{}
+
+	tokenClientsMu sync.Mutex
+	tokenClients   = map[string]*tokenClient{}
 )
 
+type tokenClient struct {
+	client   *gitea.Client
+	lastUsed time.Time
+}
+
+type (
+	instanceKey struct{}
+	tokenKey    struct{}
+)
+
+// Client returns the client of the primary instance.
 func Client() *gitea.Client {
-	clientOnce.Do(func() {
-		var err error
-		if client != nil {
-			return
-		}
+	return ClientFromContext(context.Background())
+}
 
-		httpClient := &http.Client{
-			Transport: http.DefaultTransport,
-		}
+// ClientFor returns the client of the named instance, creating it on first use.
+func ClientFor(name string) (*gitea.Client, error) {
+	inst, err := LookupInstance(name)
+	if err != nil {
+		return nil, err
+	}
 
-		opts := []gitea.ClientOption{
-			gitea.SetToken(flag.Token),
-		}
-		if flag.Insecure {
-			httpClient.Transport.(*http.Transport).TLSClientConfig = &tls.Config{
-				InsecureSkipVerify: true,
-			}
-		}
-		opts = append(opts, gitea.SetHTTPClient(httpClient))
-		if flag.Debug {
-			opts = append(opts, gitea.SetDebugMode())
+	clientsMu.Lock()
+	client, ok := clients[inst.Name]
+	clientsMu.Unlock()
+	if ok {
+		return client, nil
+	}
+
+	// Creating a client queries the server version, so do it without holding
+	// the lock to keep an unreachable instance from blocking the others.
+	client, err = newClient(inst, false)
+	if err != nil {
+		return nil, fmt.Errorf("create gitea client for instance %s err: %v", inst.Name, err)
+	}
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	if existing, ok := clients[inst.Name]; ok {
+		return existing, nil
+	}
+	clients[inst.Name] = client
+	return client, nil
+}
+
+// WithInstance returns a copy of ctx selecting the named instance for
+// ClientFromContext.
+func WithInstance(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, instanceKey{}, name)
+}
+
+// InstanceFromContext returns the instance name stored by WithInstance, or
+// the primary instance if there is none.
+func InstanceFromContext(ctx context.Context) string {
+	if name, ok := ctx.Value(instanceKey{}).(string); ok && name != "" {
+		return name
+	}
+	return PrimaryInstance()
+}
+
+// callerToken is a token replacing the configured one of a single instance.
+type callerToken struct {
+	instance string
+	token    string
+}
+
+// WithToken returns a copy of ctx carrying a token that replaces the
+// configured one of the named instance, such as the OAuth2 access token of
+// the HTTP caller. The token is never sent to other instances.
+func WithToken(ctx context.Context, instance, token string) context.Context {
+	return context.WithValue(ctx, tokenKey{}, callerToken{instance: instance, token: token})
+}
+
+// TokenFromContext returns the token stored by WithToken, or an empty string
+// if there is none. It fails if the token belongs to another instance than
+// the one selected by ctx.
+func TokenFromContext(ctx context.Context) (string, error) {
+	ct, ok := ctx.Value(tokenKey{}).(callerToken)
+	if !ok {
+		return "", nil
+	}
+	if name := InstanceFromContext(ctx); name != ct.instance {
+		return "", fmt.Errorf("the caller's token was issued by gitea instance %s and cannot be used with instance %s", ct.instance, name)
+	}
+	return ct.token, nil
+}
+
+// ClientFromContext returns the client of the instance selected for the
+// current tool call, authenticated with the caller's token if there is one.
+// If there is no usable client, the requests of the returned one fail with
+// the reason.
+func ClientFromContext(ctx context.Context) *gitea.Client {
+	name := InstanceFromContext(ctx)
+	token, err := TokenFromContext(ctx)
+	if err != nil {
+		return failingClient(err)
+	}
+	var client *gitea.Client
+	if token != "" {
+		client, err = clientForToken(name, token)
+	} else {
+		client, err = ClientFor(name)
+	}
+	if err == nil {
+		return client
+	}
+	log.Errorf("%v", err)
+
+	// The server could not be reached to detect its version. Hand out an
+	// uncached client so the tool call reports the actual request error and
+	// the next call tries again.
+	inst, err := LookupInstance(name)
+	if err != nil {
+		return failingClient(err)
+	}
+	if token != "" {
+		inst = inst.withToken(token)
+	}
+	client, _ = newClient(inst, true)
+	return client
+}
+
+// failingClient returns a client whose requests fail with err without
+// reaching any server.
+func failingClient(err error) *gitea.Client {
+	client, _ := gitea.NewClient("http://gitea.invalid",
+		gitea.SetGiteaVersion(""),
+		gitea.SetHTTPClient(&http.Client{Transport: errTransport{err}}),
+	)
+	return client
+}
+
+type errTransport struct {
+	err error
+}
+
+func (t errTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
+
+// clientForToken returns a client of the named instance that authenticates
+// with token instead of the configured one.
+func clientForToken(name, token string) (*gitea.Client, error) {
+	inst, err := LookupInstance(name)
+	if err != nil {
+		return nil, err
+	}
+	key := inst.Name + "\x00" + token
+
+	now := time.Now()
+	tokenClientsMu.Lock()
+	if tc, ok := tokenClients[key]; ok {
+		tc.lastUsed = now
+		tokenClientsMu.Unlock()
+		return tc.client, nil
+	}
+	tokenClientsMu.Unlock()
+
+	client, err := newClient(inst.withToken(token), false)
+	if err != nil {
+		return nil, fmt.Errorf("create gitea client for instance %s err: %v", inst.Name, err)
+	}
+
+	tokenClientsMu.Lock()
+	defer tokenClientsMu.Unlock()
+	for k, tc := range tokenClients {
+		if now.Sub(tc.lastUsed) > tokenClientIdleTimeout {
+			delete(tokenClients, k)
 		}
-		client, err = gitea.NewClient(flag.Host, opts...)
+	}
+	tokenClients[key] = &tokenClient{client: client, lastUsed: now}
+	return client, nil
+}
+
+func newClient(inst *Instance, skipVersion bool) (*gitea.Client, error) {
+	opts := []gitea.ClientOption{
+		gitea.SetToken(inst.Token),
+		gitea.SetHTTPClient(newHTTPClient(inst)),
+	}
+	if flag.Debug {
+		opts = append(opts, gitea.SetDebugMode())
+	}
+	if skipVersion {
+		opts = append(opts, gitea.SetGiteaVersion(""))
+	}
+	client, err := gitea.NewClient(inst.Host, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set user agent for the client
+	client.SetUserAgent(fmt.Sprintf("gitea-mcp-server/%s", flag.Version))
+	return client, nil
+}
+
+func newHTTPClient(inst *Instance) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = inst.TLSConfig()
+	httpClient := &http.Client{
+		Transport: transport,
+		Timeout:   flag.RequestTimeout,
+	}
+	if inst.source != nil {
+		httpClient.Transport = &authTransport{base: transport, source: inst.source}
+	}
+	return httpClient
+}
+
+// TLSConfig returns the TLS configuration to connect to the instance with, or
+// nil for the defaults.
+func (inst *Instance) TLSConfig() *tls.Config {
+	rootCAs := inst.rootCAs
+	if rootCAs == nil && inst.CAFile != "" {
+		// The instance did not come from LoadInstances, which checks the
+		// file up front.
+		pool, err := loadCAFile(inst.CAFile)
 		if err != nil {
-			log.Fatalf("create gitea client err: %v", err)
+			log.Errorf("instance %s: %v", inst.Name, err)
 		}
+		rootCAs = pool
+	}
+	if !inst.Insecure && rootCAs == nil {
+		return nil
+	}
+	return &tls.Config{
+		InsecureSkipVerify: inst.Insecure,
+		RootCAs:            rootCAs,
+	}
+}
 
-		// Set user agent for the client
-		client.SetUserAgent(fmt.Sprintf("gitea-mcp-server/%s", flag.Version))
-	})
-	return client
+func resetClients() {
+	clientsMu.Lock()
+	clients = map[string]*gitea.Client{}
+	clientsMu.Unlock()
+
+	tokenClientsMu.Lock()
+	tokenClients = map[string]*tokenClient{}
+	tokenClientsMu.Unlock()
+
+	httpClientsMu.Lock()
+	httpClients = map[string]*http.Client{}
+	httpClientsMu.Unlock()
+
+	resetCapabilities()
+	resetServerVersions()
 }
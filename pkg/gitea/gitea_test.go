@@ -0,0 +1,69 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCallerTokenBoundToInstance(t *testing.T) {
+	var mu sync.Mutex
+	seen := map[string][]string{}
+	newServer := func(name string) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			seen[name] = append(seen[name], r.Header.Get("Authorization"))
+			mu.Unlock()
+			if r.URL.Path == "/api/v1/version" {
+				fmt.Fprint(w, `{"version":"1.23.0"}`)
+				return
+			}
+			fmt.Fprint(w, `{"login":"caller"}`)
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+	issuer, other := newServer("issuer"), newServer("other")
+	SetInstances("issuer", map[string]*Instance{
+		"issuer": {Name: "issuer", Host: issuer.URL},
+		"other":  {Name: "other", Host: other.URL, Token: "configured"},
+	})
+	defer SetInstances("", nil)
+
+	ctx := WithToken(context.Background(), "issuer", "caller-token")
+	if _, _, err := ClientFromContext(ctx).GetMyUserInfo(); err != nil {
+		t.Fatalf("call to the issuer's instance: %v", err)
+	}
+	_, _, err := ClientFromContext(WithInstance(ctx, "other")).GetMyUserInfo()
+	if err == nil || !strings.Contains(err.Error(), "issued by gitea instance issuer") {
+		t.Errorf("call to another instance: err = %v, want the token to be refused", err)
+	}
+	if err := Do(WithInstance(ctx, "other"), http.MethodGet, "/user", nil, nil); err == nil {
+		t.Error("Do with another instance succeeded")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got := seen["issuer"]; len(got) == 0 || got[len(got)-1] != "token caller-token" {
+		t.Errorf("issuer received Authorization %q, want the caller's token", got)
+	}
+	if len(seen["other"]) != 0 {
+		t.Errorf("other instance received requests %q", seen["other"])
+	}
+}
+
+func TestClientFromContextUnknownInstance(t *testing.T) {
+	SetInstances("default", map[string]*Instance{
+		"default": {Name: "default", Host: "http://127.0.0.1:0"},
+	})
+	defer SetInstances("", nil)
+
+	_, _, err := ClientFromContext(WithInstance(context.Background(), "missing")).GetMyUserInfo()
+	if err == nil || !strings.Contains(err.Error(), "unknown gitea instance: missing") {
+		t.Errorf("err = %v, want an unknown instance error", err)
+	}
+}
@@ -0,0 +1,227 @@
+package gitea
+
+import (
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"gitea.com/gitea/gitea-mcp/pkg/flag"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultInstanceName is the name of the instance configured through the
+// --host/--token flags and their environment variables.
+const DefaultInstanceName = "default"
+
+// Instance describes a single Gitea server the MCP server can talk to.
+// The token is either given directly or loaded from a file, a command or git's
+// credential helpers, in that order of preference. CAFile adds the
+// certificates of a private CA, such as that of a self-signed instance, to the
+// trusted roots.
+type Instance struct {
+	Name          string `yaml:"name" json:"name"`
+	Host          string `yaml:"host" json:"host"`
+	Token         string `yaml:"token" json:"-"`
+	TokenFile     string `yaml:"token_file" json:"-"`
+	TokenCommand  string `yaml:"token_command" json:"-"`
+	GitCredential bool   `yaml:"git_credential" json:"-"`
+	Insecure      bool   `yaml:"insecure" json:"insecure"`
+	CAFile        string `yaml:"ca_file" json:"ca_file,omitempty"`
+	ReadOnly      bool   `yaml:"read_only" json:"read_only"`
+
+	source  *tokenSource
+	rootCAs *x509.CertPool
+}
+
+// HasToken reports whether requests to the instance are authenticated.
+func (inst *Instance) HasToken() bool {
+	return inst.Token != "" || inst.source != nil
+}
+
+// withToken returns a copy of inst authenticating with token.
+func (inst *Instance) withToken(token string) *Instance {
+	c := *inst
+	c.Token = token
+	c.TokenFile = ""
+	c.TokenCommand = ""
+	c.GitCredential = false
+	c.source = nil
+	return &c
+}
+
+// instancesFile is the on-disk layout of the --instances file. YAML is used so
+// that plain JSON files are accepted as well.
+type instancesFile struct {
+	Primary   string      `yaml:"primary"`
+	Instances []*Instance `yaml:"instances"`
+}
+
+var (
+	instancesMu sync.RWMutex
+	instances   map[string]*Instance
+	primary     string
+)
+
+// LoadInstances registers the instance configured by flags as "default" and,
+// if path is not empty, every instance declared in the given file. An instance
+// named "default" in the file replaces the one built from flags.
+func LoadInstances(path string) error {
+	set := map[string]*Instance{
+		DefaultInstanceName: flagInstance(),
+	}
+	primaryName := DefaultInstanceName
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read instances file err: %v", err)
+		}
+		var file instancesFile
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("parse instances file %s err: %v", path, err)
+		}
+		seen := make(map[string]bool, len(file.Instances))
+		for _, inst := range file.Instances {
+			if inst.Name == "" {
+				return fmt.Errorf("instance without name in %s", path)
+			}
+			if inst.Host == "" {
+				return fmt.Errorf("instance %s has no host", inst.Name)
+			}
+			if seen[inst.Name] {
+				return fmt.Errorf("duplicate instance name %s", inst.Name)
+			}
+			seen[inst.Name] = true
+			inst.source = newTokenSource(inst)
+			set[inst.Name] = inst
+		}
+		if file.Primary != "" {
+			if _, ok := set[file.Primary]; !ok {
+				return fmt.Errorf("primary instance %s is not configured", file.Primary)
+			}
+			primaryName = file.Primary
+		}
+	}
+
+	for _, inst := range set {
+		if inst.CAFile == "" {
+			continue
+		}
+		pool, err := loadCAFile(inst.CAFile)
+		if err != nil {
+			return fmt.Errorf("instance %s: %v", inst.Name, err)
+		}
+		inst.rootCAs = pool
+	}
+
+	SetInstances(primaryName, set)
+	return nil
+}
+
+// loadCAFile returns the system roots extended by the PEM certificates in
+// path.
+func loadCAFile(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read CA file err: %v", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in CA file %s", path)
+	}
+	return pool, nil
+}
+
+// SetInstances replaces the registered instances and drops cached clients.
+func SetInstances(primaryName string, set map[string]*Instance) {
+	instancesMu.Lock()
+	instances = set
+	primary = primaryName
+	instancesMu.Unlock()
+
+	resetClients()
+}
+
+// Instances returns the registered instances sorted by name.
+func Instances() []*Instance {
+	instancesMu.RLock()
+	defer instancesMu.RUnlock()
+	list := make([]*Instance, 0, len(instances))
+	for _, inst := range instances {
+		list = append(list, inst)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// PrimaryInstance returns the name of the instance used when a tool call
+// does not select one explicitly.
+func PrimaryInstance() string {
+	instancesMu.RLock()
+	defer instancesMu.RUnlock()
+	if primary == "" {
+		return DefaultInstanceName
+	}
+	return primary
+}
+
+// LookupInstance returns the instance registered under name. An empty name
+// selects the primary instance.
+func LookupInstance(name string) (*Instance, error) {
+	if name == "" {
+		name = PrimaryInstance()
+	}
+	instancesMu.RLock()
+	defer instancesMu.RUnlock()
+	if instances == nil && name == DefaultInstanceName {
+		// Nothing was loaded, fall back to the flag configuration.
+		return flagInstance(), nil
+	}
+	inst, ok := instances[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown gitea instance: %s", name)
+	}
+	return inst, nil
+}
+
+// InstanceByHost returns the instance serving the Gitea at url, such as the
+// issuer of OAuth2 tokens.
+func InstanceByHost(url string) (*Instance, error) {
+	want := normalizeHost(url)
+	for _, inst := range Instances() {
+		if normalizeHost(inst.Host) == want {
+			return inst, nil
+		}
+	}
+	return nil, fmt.Errorf("no gitea instance is configured for %s", url)
+}
+
+func normalizeHost(url string) string {
+	return strings.ToLower(strings.TrimSuffix(url, "/"))
+}
+
+// flagInstance returns the instance configured by flags.
+func flagInstance() *Instance {
+	inst := &Instance{
+		Name:          DefaultInstanceName,
+		Host:          flag.Host,
+		Token:         flag.Token,
+		TokenFile:     flag.TokenFile,
+		TokenCommand:  flag.TokenCommand,
+		GitCredential: flag.GitCredential,
+		Insecure:      flag.Insecure,
+		CAFile:        flag.CAFile,
+		ReadOnly:      flag.ReadOnly,
+	}
+	inst.source = newTokenSource(inst)
+	return inst
+}
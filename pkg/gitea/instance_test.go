@@ -0,0 +1,55 @@
+package gitea
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadInstancesCAFile(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"version":"1.23.0"}`)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, cert, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	instancesFile := filepath.Join(dir, "instances.yaml")
+	data := fmt.Sprintf(`instances:
+  - name: trusted
+    host: %[1]s
+    ca_file: %[2]s
+  - name: untrusted
+    host: %[1]s
+`, srv.URL, caFile)
+	if err := os.WriteFile(instancesFile, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	defer SetInstances("", nil)
+
+	if err := LoadInstances(instancesFile); err != nil {
+		t.Fatalf("LoadInstances: %v", err)
+	}
+	if _, err := ClientFor("trusted"); err != nil {
+		t.Errorf("client with ca_file: %v", err)
+	}
+	if _, err := ClientFor("untrusted"); err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Errorf("client without ca_file: err = %v, want a certificate error", err)
+	}
+
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadInstances(instancesFile); err == nil || !strings.Contains(err.Error(), "no certificates") {
+		t.Errorf("LoadInstances with invalid ca_file: err = %v", err)
+	}
+}
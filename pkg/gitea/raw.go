@@ -0,0 +1,124 @@
+package gitea
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	httpClientsMu sync.Mutex
+	httpClients   = map[string]*http.Client{}
+)
+
+// APIError is an unsuccessful response of the Gitea API.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return http.StatusText(e.StatusCode)
+	}
+	return e.Message
+}
+
+// Do calls an API endpoint the SDK does not cover on the instance selected by
+// ctx, authenticated like ClientFromContext. path is relative to /api/v1. body
+// is sent as JSON if not nil, and the response is decoded into v if not nil.
+func Do(ctx context.Context, method, path string, body, v any) error {
+	_, err := do(ctx, method, path, body, v)
+	return err
+}
+
+// Count returns the total count Gitea reports for the list endpoint at path,
+// which should ask for a single entry, without decoding the list.
+func Count(ctx context.Context, path string) (int, error) {
+	header, err := do(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	total, err := strconv.Atoi(header.Get("X-Total-Count"))
+	if err != nil {
+		return 0, fmt.Errorf("gitea reported no total count for %s", path)
+	}
+	return total, nil
+}
+
+func do(ctx context.Context, method, path string, body, v any) (http.Header, error) {
+	inst, err := LookupInstance(InstanceFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	token, err := TokenFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("marshal request body err: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	url := strings.TrimSuffix(inst.Host, "/") + "/api/v1" + path
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "token "+token)
+	} else if inst.Token != "" {
+		req.Header.Set("Authorization", "token "+inst.Token)
+	}
+
+	resp, err := httpClientFor(inst).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		var msg struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &msg) == nil {
+			apiErr.Message = msg.Message
+		}
+		return nil, apiErr
+	}
+	if v != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, v); err != nil {
+			return nil, fmt.Errorf("decode response err: %v", err)
+		}
+	}
+	return resp.Header, nil
+}
+
+func httpClientFor(inst *Instance) *http.Client {
+	httpClientsMu.Lock()
+	defer httpClientsMu.Unlock()
+	client, ok := httpClients[inst.Name]
+	if !ok {
+		client = newHTTPClient(inst)
+		httpClients[inst.Name] = client
+	}
+	return client
+}
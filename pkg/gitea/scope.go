@@ -0,0 +1,262 @@
+package gitea
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Token scope categories of Gitea access tokens. A token is granted read or
+// write access per category.
+const (
+	ScopeUser         = "user"
+	ScopeRepository   = "repository"
+	ScopeIssue        = "issue"
+	ScopeOrganization = "organization"
+	ScopeNotification = "notification"
+	ScopePackage      = "package"
+)
+
+// probeName names the owner of the package probe. It is not expected to
+// exist, so a permitted probe fails with 404.
+const probeName = "gitea-mcp-scope-probe"
+
+// scopeProbe is a GET request that Gitea rejects with a "required scope" 403
+// if the token lacks read access to the scope category. Gitea checks the
+// scope of these routes before it looks up anything they name, and none of
+// them needs a second scope category.
+type scopeProbe struct {
+	scope string
+	path  string
+}
+
+// Write access is not probed: Gitea requires it for POST, PUT, PATCH and
+// DELETE requests only, and probing with those risks changing data.
+var scopeProbes = []scopeProbe{
+	{ScopeUser, "/user"},
+	{ScopeRepository, "/repos/search?limit=1"},
+	{ScopeIssue, "/repos/issues/search?limit=1"},
+	{ScopeOrganization, "/orgs?limit=1"},
+	{ScopeNotification, "/notifications?limit=1"},
+	{ScopePackage, "/packages/" + probeName + "?limit=1"},
+}
+
+// Access is what a token may do within a scope category. Write access cannot
+// be probed safely, so it is assumed wherever the token can read until Gitea
+// rejects a write for the missing scope, and WriteAssumed is set meanwhile.
+type Access struct {
+	Read         bool `json:"read"`
+	Write        bool `json:"write"`
+	WriteAssumed bool `json:"write_assumed,omitempty"`
+}
+
+// Capabilities describes what the token used for an instance may do.
+type Capabilities struct {
+	Instance      string            `json:"instance"`
+	Authenticated bool              `json:"authenticated"`
+	Scopes        map[string]Access `json:"scopes,omitempty"`
+}
+
+// Allows reports whether the token may read, or write if write is set, in the
+// scope category. Without a token nothing is known and everything is allowed,
+// Gitea decides per request.
+func (c *Capabilities) Allows(scope string, write bool) bool {
+	if !c.Authenticated || scope == "" {
+		return true
+	}
+	access, ok := c.Scopes[scope]
+	if !ok {
+		return true
+	}
+	if write {
+		return access.Write
+	}
+	return access.Read
+}
+
+type capabilitiesEntry struct {
+	once sync.Once
+	caps *Capabilities
+	err  error
+}
+
+var (
+	capabilitiesMu sync.Mutex
+	capabilities   = map[string]*capabilitiesEntry{}
+)
+
+// CapabilitiesFromContext returns the capabilities of the token used by
+// ClientFromContext. They are detected once per instance and token by
+// probing the API.
+func CapabilitiesFromContext(ctx context.Context) (*Capabilities, error) {
+	inst, err := LookupInstance(InstanceFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	token, err := TokenFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		token = inst.Token
+	}
+	if token == "" && inst.source != nil {
+		if token, err = inst.source.Token(); err != nil {
+			return nil, err
+		}
+	}
+	if token == "" {
+		return &Capabilities{Instance: inst.Name}, nil
+	}
+
+	key := capabilitiesKey(inst.Name, token)
+	capabilitiesMu.Lock()
+	entry, ok := capabilities[key]
+	if !ok {
+		entry = &capabilitiesEntry{}
+		capabilities[key] = entry
+	}
+	capabilitiesMu.Unlock()
+
+	entry.once.Do(func() {
+		// The result is shared, so do not let the caller cancel the probes.
+		entry.caps, entry.err = probeScopes(WithInstance(context.WithoutCancel(ctx), inst.Name), inst.Name)
+	})
+	capabilitiesMu.Lock()
+	defer capabilitiesMu.Unlock()
+	if entry.err != nil {
+		// Forget the failure so the next call probes again.
+		if capabilities[key] == entry {
+			delete(capabilities, key)
+		}
+	}
+	return entry.caps, entry.err
+}
+
+// RecordWriteAccess updates the capabilities of the token used by
+// ClientFromContext with the outcome of a write request in the scope
+// category: allowed is false if Gitea rejected it for the missing scope.
+func RecordWriteAccess(ctx context.Context, scope string, allowed bool) {
+	inst, err := LookupInstance(InstanceFromContext(ctx))
+	if err != nil {
+		return
+	}
+	token, err := TokenFromContext(ctx)
+	if err != nil {
+		return
+	}
+	if token == "" {
+		token = inst.Token
+	}
+	if token == "" && inst.source != nil {
+		if token, err = inst.source.Token(); err != nil {
+			return
+		}
+	}
+	if token == "" {
+		return
+	}
+
+	capabilitiesMu.Lock()
+	defer capabilitiesMu.Unlock()
+	entry, ok := capabilities[capabilitiesKey(inst.Name, token)]
+	if !ok || entry.caps == nil {
+		return
+	}
+	access, ok := entry.caps.Scopes[scope]
+	if !ok || !access.WriteAssumed {
+		return
+	}
+	// The capabilities are shared with concurrent readers, so replace them
+	// instead of changing them in place.
+	caps := *entry.caps
+	caps.Scopes = maps.Clone(caps.Scopes)
+	caps.Scopes[scope] = Access{Read: access.Read, Write: allowed}
+	entry.caps = &caps
+}
+
+// IsMissingScope reports whether err is Gitea's rejection of a token lacking
+// a required scope.
+func IsMissingScope(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "required scope")
+}
+
+func capabilitiesKey(instance, token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return instance + "\x00" + hex.EncodeToString(sum[:])
+}
+
+func probeScopes(ctx context.Context, instance string) (*Capabilities, error) {
+	type result struct {
+		probe   scopeProbe
+		allowed bool
+		err     error
+	}
+	results := make(chan result, len(scopeProbes))
+	for _, p := range scopeProbes {
+		go func() {
+			err := Do(ctx, http.MethodGet, p.path, nil, nil)
+			allowed, err := scopeAllowed(err)
+			results <- result{p, allowed, err}
+		}()
+	}
+
+	caps := &Capabilities{
+		Instance:      instance,
+		Authenticated: true,
+		Scopes:        make(map[string]Access),
+	}
+	var errs []error
+	for range scopeProbes {
+		r := <-results
+		if r.err != nil {
+			errs = append(errs, r.err)
+			continue
+		}
+		// Write access implies read access, so it is only possible if the
+		// token can read.
+		caps.Scopes[r.probe.scope] = Access{Read: r.allowed, Write: r.allowed, WriteAssumed: r.allowed}
+	}
+	for _, err := range errs {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized {
+			return nil, fmt.Errorf("token rejected by instance %s: %v", instance, err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("detect token scopes err: %v", errs[0])
+	}
+	return caps, nil
+}
+
+// scopeAllowed interprets the outcome of a probe. Any answer except a missing
+// scope means the token passed the scope check.
+func scopeAllowed(err error) (bool, error) {
+	if err == nil {
+		return true, nil
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false, err
+	}
+	switch {
+	case apiErr.StatusCode == http.StatusUnauthorized:
+		return false, err
+	case apiErr.StatusCode == http.StatusForbidden && IsMissingScope(apiErr):
+		return false, nil
+	default:
+		return true, nil
+	}
+}
+
+func resetCapabilities() {
+	capabilitiesMu.Lock()
+	capabilities = map[string]*capabilitiesEntry{}
+	capabilitiesMu.Unlock()
+}
@@ -0,0 +1,209 @@
+package gitea
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestScopeAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		want    bool
+		wantErr bool
+	}{
+		{name: "success", err: nil, want: true},
+		{name: "missing scope", err: &APIError{StatusCode: http.StatusForbidden, Message: "token does not have at least one of required scope(s): [read:issue]"}, want: false},
+		{name: "forbidden otherwise", err: &APIError{StatusCode: http.StatusForbidden, Message: "Forbidden"}, want: true},
+		{name: "not found", err: &APIError{StatusCode: http.StatusNotFound}, want: true},
+		{name: "method not allowed", err: &APIError{StatusCode: http.StatusMethodNotAllowed}, want: true},
+		{name: "bad token", err: &APIError{StatusCode: http.StatusUnauthorized}, wantErr: true},
+		{name: "network", err: errors.New("connection refused"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scopeAllowed(tt.err)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCapabilitiesAllows(t *testing.T) {
+	caps := &Capabilities{
+		Authenticated: true,
+		Scopes: map[string]Access{
+			ScopeRepository: {Read: true, Write: true},
+			ScopeIssue:      {Read: true},
+			ScopeUser:       {},
+		},
+	}
+	tests := []struct {
+		scope string
+		write bool
+		want  bool
+	}{
+		{ScopeRepository, true, true},
+		{ScopeIssue, false, true},
+		{ScopeIssue, true, false},
+		{ScopeUser, false, false},
+		{ScopeOrganization, true, true},
+		{"", true, true},
+	}
+	for _, tt := range tests {
+		if got := caps.Allows(tt.scope, tt.write); got != tt.want {
+			t.Errorf("Allows(%q, %v) = %v, want %v", tt.scope, tt.write, got, tt.want)
+		}
+	}
+	if !(&Capabilities{}).Allows(ScopeUser, true) {
+		t.Error("an unauthenticated client should be allowed everything")
+	}
+}
+
+// giteaScopeStub answers the probe routes the way Gitea's router does: the
+// token scope middleware of a route group runs before the route looks up
+// the owner or repository it names, and the required level follows from the
+// method.
+type giteaScopeStub struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	scopes  []string
+	methods []string
+}
+
+func newGiteaScopeStub(t *testing.T) *giteaScopeStub {
+	s := &giteaScopeStub{}
+	// Each route lists its middleware in Gitea's order.
+	routes := map[string][]func(http.ResponseWriter, *http.Request) bool{
+		"/api/v1/user":                 {s.requireScopes("user")},
+		"/api/v1/repos/search":         {s.requireScopes("repository")},
+		"/api/v1/repos/issues/search":  {s.requireScopes("issue")},
+		"/api/v1/orgs":                 {s.requireScopes("organization")},
+		"/api/v1/notifications":        {s.requireScopes("notification")},
+		"/api/v1/packages/{owner}":     {s.requireScopes("package"), notFound},
+		"/api/v1/user/repos":           {s.requireScopes("user"), s.requireScopes("repository")},
+		"/api/v1/repos/{owner}/{repo}": {notFound, s.requireScopes("repository")},
+	}
+	mux := http.NewServeMux()
+	for pattern, chain := range routes {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			s.mu.Lock()
+			s.methods = append(s.methods, r.Method)
+			s.mu.Unlock()
+			for _, next := range chain {
+				if !next(w, r) {
+					return
+				}
+			}
+			fmt.Fprint(w, "[]")
+		})
+	}
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// requireScopes mirrors Gitea's tokenRequiresScopes: writes need the write
+// scope of every category, reads at least the read scope.
+func (s *giteaScopeStub) requireScopes(categories ...string) func(http.ResponseWriter, *http.Request) bool {
+	return func(w http.ResponseWriter, r *http.Request) bool {
+		level := "read"
+		if r.Method != http.MethodGet {
+			level = "write"
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		var required []string
+		for _, category := range categories {
+			required = append(required, level+":"+category)
+			if !slices.Contains(s.scopes, "write:"+category) && !slices.Contains(s.scopes, level+":"+category) {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprintf(w, `{"message":"token does not have at least one of required scope(s): %v"}`, required)
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func notFound(w http.ResponseWriter, r *http.Request) bool {
+	w.WriteHeader(http.StatusNotFound)
+	fmt.Fprint(w, `{"message":"The target couldn't be found."}`)
+	return false
+}
+
+func TestProbeScopes(t *testing.T) {
+	stub := newGiteaScopeStub(t)
+	SetInstances("default", map[string]*Instance{
+		"default": {Name: "default", Host: stub.URL, Token: "probe-token"},
+	})
+	defer SetInstances("", nil)
+
+	stub.scopes = []string{"read:user", "write:issue", "read:package"}
+	caps, err := CapabilitiesFromContext(context.Background())
+	if err != nil {
+		t.Fatalf("CapabilitiesFromContext: %v", err)
+	}
+	want := map[string]Access{
+		ScopeUser:         {Read: true, Write: true, WriteAssumed: true},
+		ScopeRepository:   {},
+		ScopeIssue:        {Read: true, Write: true, WriteAssumed: true},
+		ScopeOrganization: {},
+		ScopeNotification: {},
+		ScopePackage:      {Read: true, Write: true, WriteAssumed: true},
+	}
+	if !reflect.DeepEqual(caps.Scopes, want) {
+		t.Errorf("scopes\n got: %v\nwant: %v", caps.Scopes, want)
+	}
+	for _, method := range stub.methods {
+		if method != http.MethodGet {
+			t.Errorf("probe sent a %s request, want only GET", method)
+		}
+	}
+
+	ctx := context.Background()
+	RecordWriteAccess(ctx, ScopeUser, false)
+	RecordWriteAccess(ctx, ScopeIssue, true)
+	RecordWriteAccess(ctx, ScopeIssue, false)
+	caps, err = CapabilitiesFromContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := caps.Scopes[ScopeUser]; got != (Access{Read: true}) {
+		t.Errorf("user access after a rejected write = %+v", got)
+	}
+	// The first outcome settles the assumption.
+	if got := caps.Scopes[ScopeIssue]; got != (Access{Read: true, Write: true}) {
+		t.Errorf("issue access after a successful write = %+v", got)
+	}
+}
+
+func TestProbeRoutesCheckScopeFirst(t *testing.T) {
+	stub := newGiteaScopeStub(t)
+	SetInstances("default", map[string]*Instance{
+		"default": {Name: "default", Host: stub.URL, Token: "probe-token"},
+	})
+	defer SetInstances("", nil)
+
+	// Without any scope every probe must be answered by the scope check,
+	// not by a lookup of what the route names or by another category.
+	for _, p := range scopeProbes {
+		err := Do(context.Background(), http.MethodGet, p.path, nil, nil)
+		if !IsMissingScope(err) || !strings.Contains(err.Error(), "read:"+p.scope) {
+			t.Errorf("probe %s for %s: err = %v, want a missing read:%s scope", p.path, p.scope, err, p.scope)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package gitea
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"gitea.com/gitea/gitea-mcp/pkg/log"
+)
+
+// tokenCommandTimeout bounds a single run of a token command or of the git
+// credential helper.
+const tokenCommandTimeout = 30 * time.Second
+
+// tokenSource loads the token of an instance from a file, a command or the
+// git credential helper, and loads it again when Gitea rejects it.
+type tokenSource struct {
+	name  string
+	fetch func() (string, error)
+
+	mu    sync.Mutex
+	token string
+}
+
+// newTokenSource returns the token source of inst, or nil if it uses a static
+// token.
+func newTokenSource(inst *Instance) *tokenSource {
+	var name string
+	var fetch func() (string, error)
+	switch {
+	case inst.Token != "":
+		return nil
+	case inst.TokenFile != "":
+		name = "token file " + inst.TokenFile
+		fetch = func() (string, error) {
+			return readTokenFile(inst.TokenFile)
+		}
+	case inst.TokenCommand != "":
+		name = "token command"
+		fetch = func() (string, error) {
+			return runTokenCommand(inst.TokenCommand)
+		}
+	case inst.GitCredential:
+		name = "git credential helper"
+		fetch = func() (string, error) {
+			return gitCredential(inst.Host)
+		}
+	default:
+		return nil
+	}
+	return &tokenSource{name: name, fetch: fetch}
+}
+
+// Token returns the current token, loading it on first use.
+func (s *tokenSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" {
+		return s.token, nil
+	}
+	token, err := s.fetch()
+	if err != nil {
+		return "", fmt.Errorf("get token from %s err: %v", s.name, err)
+	}
+	s.token = token
+	return token, nil
+}
+
+// Refresh loads the token again after stale was rejected. If another request
+// already replaced stale, the newer token is returned as is.
+func (s *tokenSource) Refresh(stale string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && s.token != stale {
+		return s.token, nil
+	}
+	log.Infof("Gitea rejected the token, reloading it from %s", s.name)
+	token, err := s.fetch()
+	if err != nil {
+		return "", fmt.Errorf("refresh token from %s err: %v", s.name, err)
+	}
+	s.token = token
+	return token, nil
+}
+
+// authTransport authenticates requests with the token of a tokenSource and
+// retries a request once with a refreshed token if it is answered with 401.
+type authTransport struct {
+	base   http.RoundTripper
+	source *tokenSource
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") != "" {
+		// Authenticated by the caller, such as with an OAuth2 access token.
+		return t.base.RoundTrip(req)
+	}
+	token, err := t.source.Token()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(withAuthorization(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		// The body was consumed and cannot be sent again.
+		return resp, nil
+	}
+
+	fresh, err := t.source.Refresh(token)
+	if err != nil {
+		log.Errorf("%v", err)
+		return resp, nil
+	}
+	if fresh == token {
+		return resp, nil
+	}
+	retry := withAuthorization(req, fresh)
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+	resp.Body.Close()
+	return t.base.RoundTrip(retry)
+}
+
+func withAuthorization(req *http.Request, token string) *http.Request {
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", "token "+token)
+	return r
+}
+
+func readTokenFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	return token, nil
+}
+
+func runTokenCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), tokenCommandTimeout)
+	defer cancel()
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", fmt.Errorf("command printed no token")
+	}
+	return token, nil
+}
+
+// gitCredential asks git's credential helpers for the password stored for
+// host, which for Gitea is usually a personal access token.
+func gitCredential(host string) (string, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return "", fmt.Errorf("parse host err: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), tokenCommandTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", "credential", "fill")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("protocol=%s\nhost=%s\n\n", u.Scheme, u.Host))
+	// Never prompt, the server has no terminal to prompt on.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git credential fill err: %v", err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if password, ok := strings.CutPrefix(line, "password="); ok && password != "" {
+			return password, nil
+		}
+	}
+	return "", fmt.Errorf("no credential stored for %s", u.Host)
+}
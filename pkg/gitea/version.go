@@ -0,0 +1,76 @@
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-version"
+)
+
+var (
+	serverVersionsMu sync.Mutex
+	serverVersions   = map[string]string{}
+)
+
+// ServerVersion returns the version of the instance selected by ctx. It is
+// fetched once per instance.
+func ServerVersion(ctx context.Context) (string, error) {
+	name := InstanceFromContext(ctx)
+	serverVersionsMu.Lock()
+	v, ok := serverVersions[name]
+	serverVersionsMu.Unlock()
+	if ok {
+		return v, nil
+	}
+
+	v, _, err := ClientFromContext(ctx).ServerVersion()
+	if err != nil {
+		return "", fmt.Errorf("get gitea version of instance %s err: %v", name, err)
+	}
+	serverVersionsMu.Lock()
+	serverVersions[name] = v
+	serverVersionsMu.Unlock()
+	return v, nil
+}
+
+// RequireVersion returns an error if the instance selected by ctx is older
+// than minVersion. If the version cannot be determined the check passes and
+// Gitea answers the request itself.
+func RequireVersion(ctx context.Context, minVersion string) error {
+	have, err := ServerVersion(ctx)
+	if err != nil {
+		return nil
+	}
+	ok, err := versionAtLeast(have, minVersion)
+	if err != nil || ok {
+		return nil
+	}
+	return fmt.Errorf("requires Gitea >= %s, instance %s runs %s", minVersion, InstanceFromContext(ctx), have)
+}
+
+// versionAtLeast compares Gitea versions. Forgejo reports the Gitea version
+// it is compatible with as build metadata, such as 7.0.5+gitea-1.21.11, which
+// is used instead.
+func versionAtLeast(have, minVersion string) (bool, error) {
+	if _, compat, ok := strings.Cut(have, "+gitea-"); ok {
+		have = compat
+	}
+	h, err := version.NewVersion(have)
+	if err != nil {
+		return false, err
+	}
+	m, err := version.NewVersion(minVersion)
+	if err != nil {
+		return false, err
+	}
+	// Development builds of the next release are treated as that release.
+	return h.Core().GreaterThanOrEqual(m.Core()), nil
+}
+
+func resetServerVersions() {
+	serverVersionsMu.Lock()
+	serverVersions = map[string]string{}
+	serverVersionsMu.Unlock()
+}
@@ -0,0 +1,30 @@
+package gitea
+
+import "testing"
+
+func TestVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		have, min string
+		want      bool
+		wantErr   bool
+	}{
+		{have: "1.22.0", min: "1.22", want: true},
+		{have: "1.21.11", min: "1.22", want: false},
+		{have: "1.23.1", min: "1.13", want: true},
+		{have: "1.22.0+dev-123-gabcdef", min: "1.22", want: true},
+		{have: "1.22.0-rc1", min: "1.22", want: true},
+		{have: "7.0.5+gitea-1.21.11", min: "1.22", want: false},
+		{have: "9.0.0+gitea-1.22.0", min: "1.22", want: true},
+		{have: "development", min: "1.22", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := versionAtLeast(tt.have, tt.min)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("versionAtLeast(%q, %q) error = %v, wantErr %v", tt.have, tt.min, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("versionAtLeast(%q, %q) = %v, want %v", tt.have, tt.min, got, tt.want)
+		}
+	}
+}
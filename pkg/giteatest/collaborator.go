@@ -0,0 +1,114 @@
+package giteatest
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// accessModes orders the access modes from least to most access.
+var accessModes = []gitea.AccessMode{
+	gitea.AccessModeNone,
+	gitea.AccessModeRead,
+	gitea.AccessModeWrite,
+	gitea.AccessModeAdmin,
+	gitea.AccessModeOwner,
+}
+
+// AddCollaborator adds the user login as a collaborator with the given
+// access.
+func (r *Repo) AddCollaborator(login string, mode gitea.AccessMode) {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+	r.addCollaborator(login, mode)
+}
+
+// Collaborators returns the access of each collaborator by login.
+func (r *Repo) Collaborators() map[string]gitea.AccessMode {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+	return maps.Clone(r.collaborators)
+}
+
+func (r *Repo) addCollaborator(login string, mode gitea.AccessMode) {
+	if r.collaborators == nil {
+		r.collaborators = make(map[string]gitea.AccessMode)
+	}
+	r.collaborators[login] = mode
+}
+
+// permission returns the access login has to the repository: as its owner,
+// as a collaborator, through the teams of the organization owning it, or
+// else read access if it is public.
+func (r *Repo) permission(login string) gitea.AccessMode {
+	if r.Owner.UserName == login {
+		return gitea.AccessModeOwner
+	}
+	best := slices.Index(accessModes, gitea.AccessModeNone)
+	if !r.Private {
+		best = slices.Index(accessModes, gitea.AccessModeRead)
+	}
+	if mode, ok := r.collaborators[login]; ok {
+		best = max(best, slices.Index(accessModes, mode))
+	}
+	for _, team := range r.server.teams[r.Owner.UserName] {
+		if slices.Contains(r.server.members[team.ID], login) && slices.Contains(r.server.teamRepos[team.ID], r) {
+			best = max(best, slices.Index(accessModes, team.Permission))
+		}
+	}
+	return accessModes[best]
+}
+
+func (s *Server) collaboratorRoutes() {
+	s.handleRepo("GET /collaborators", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		users := []*gitea.User{}
+		for _, login := range sortedKeys(repo.collaborators) {
+			users = append(users, s.users[login])
+		}
+		writeJSON(w, http.StatusOK, paginate(r, users))
+	})
+
+	s.handleRepo("PUT /collaborators/{collaborator}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		var opt gitea.AddCollaboratorOption
+		if !decode(w, r, &opt) {
+			return
+		}
+		login := r.PathValue("collaborator")
+		if _, ok := s.users[login]; !ok {
+			writeError(w, http.StatusUnprocessableEntity, "user does not exist [uid: 0, name: %s]", login)
+			return
+		}
+		mode := gitea.AccessModeWrite
+		if opt.Permission != nil {
+			mode = *opt.Permission
+		}
+		repo.addCollaborator(login, mode)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	s.handleRepo("DELETE /collaborators/{collaborator}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		login := r.PathValue("collaborator")
+		if _, ok := s.users[login]; !ok {
+			writeError(w, http.StatusUnprocessableEntity, "user does not exist [uid: 0, name: %s]", login)
+			return
+		}
+		delete(repo.collaborators, login)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	s.handleRepo("GET /collaborators/{collaborator}/permission", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		user, ok := s.users[r.PathValue("collaborator")]
+		if !ok {
+			writeNotFound(w)
+			return
+		}
+		mode := repo.permission(user.UserName)
+		writeJSON(w, http.StatusOK, gitea.CollaboratorPermissionResult{
+			Permission: mode,
+			Role:       string(mode),
+			User:       user,
+		})
+	})
+}
@@ -0,0 +1,504 @@
+package giteatest
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// AddIssue opens an issue.
+func (r *Repo) AddIssue(title, body string) *gitea.Issue {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+	return r.addIssue(title, body)
+}
+
+// Issue returns the issue or pull request with the given index, or nil.
+func (r *Repo) Issue(index int64) *gitea.Issue {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+	return r.issue(index)
+}
+
+// AddComment comments on an issue.
+func (r *Repo) AddComment(index int64, body string) *gitea.Comment {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+	return r.addComment(r.issue(index), body)
+}
+
+// LabelIssue adds labels to an issue.
+func (r *Repo) LabelIssue(index int64, labels ...*gitea.Label) {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+	issue := r.issue(index)
+	issue.Labels = append(issue.Labels, labels...)
+}
+
+// AddLabel creates a label.
+func (r *Repo) AddLabel(name, color string) *gitea.Label {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+	return r.addLabel(gitea.CreateLabelOption{Name: name, Color: color})
+}
+
+// Labels returns the labels of the repository.
+func (r *Repo) Labels() []*gitea.Label {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+	return append([]*gitea.Label(nil), r.labels...)
+}
+
+// AddPull opens a pull request merging head into base.
+func (r *Repo) AddPull(title, head, base string) *gitea.PullRequest {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+	return r.addPull(gitea.CreatePullRequestOption{Title: title, Head: head, Base: base})
+}
+
+func (r *Repo) issue(index int64) *gitea.Issue {
+	for _, issue := range r.issues {
+		if issue.Index == index {
+			return issue
+		}
+	}
+	return nil
+}
+
+func (r *Repo) label(id int64) *gitea.Label {
+	for _, label := range r.labels {
+		if label.ID == id {
+			return label
+		}
+	}
+	return nil
+}
+
+func (r *Repo) addIssue(title, body string) *gitea.Issue {
+	r.nextIndex++
+	issue := &gitea.Issue{
+		ID:        r.server.id(),
+		URL:       fmt.Sprintf("%s/api/v1/repos/%s/issues/%d", r.server.URL, r.FullName, r.nextIndex),
+		HTMLURL:   fmt.Sprintf("%s/issues/%d", r.HTMLURL, r.nextIndex),
+		Index:     r.nextIndex,
+		Poster:    r.server.user,
+		Title:     title,
+		Body:      body,
+		Labels:    []*gitea.Label{},
+		Assignees: []*gitea.User{},
+		State:     gitea.StateOpen,
+		Created:   Time,
+		Updated:   Time,
+		Repository: &gitea.RepositoryMeta{
+			ID:       r.ID,
+			Name:     r.Name,
+			Owner:    r.Owner.UserName,
+			FullName: r.FullName,
+		},
+	}
+	r.issues = append(r.issues, issue)
+	r.OpenIssues++
+	return issue
+}
+
+func (r *Repo) addComment(issue *gitea.Issue, body string) *gitea.Comment {
+	id := r.server.id()
+	comment := &gitea.Comment{
+		ID:       id,
+		HTMLURL:  fmt.Sprintf("%s#issuecomment-%d", issue.HTMLURL, id),
+		IssueURL: issue.HTMLURL,
+		Poster:   r.server.user,
+		Body:     body,
+		Created:  Time,
+		Updated:  Time,
+	}
+	r.comments[issue.Index] = append(r.comments[issue.Index], comment)
+	issue.Comments++
+	return comment
+}
+
+func (r *Repo) addLabel(opt gitea.CreateLabelOption) *gitea.Label {
+	id := r.server.id()
+	label := &gitea.Label{
+		ID:          id,
+		Name:        opt.Name,
+		Color:       strings.TrimPrefix(opt.Color, "#"),
+		Description: opt.Description,
+		URL:         fmt.Sprintf("%s/api/v1/repos/%s/labels/%d", r.server.URL, r.FullName, id),
+	}
+	r.labels = append(r.labels, label)
+	return label
+}
+
+func (r *Repo) addPull(opt gitea.CreatePullRequestOption) *gitea.PullRequest {
+	issue := r.addIssue(opt.Title, opt.Body)
+	issue.PullRequest = &gitea.PullRequestMeta{}
+	issue.HTMLURL = fmt.Sprintf("%s/pulls/%d", r.HTMLURL, issue.Index)
+	r.OpenIssues--
+	r.OpenPulls++
+	pr := &gitea.PullRequest{
+		ID:        issue.ID,
+		URL:       issue.URL,
+		Index:     issue.Index,
+		Poster:    issue.Poster,
+		Title:     issue.Title,
+		Body:      issue.Body,
+		Labels:    issue.Labels,
+		Assignees: issue.Assignees,
+		State:     gitea.StateOpen,
+		HTMLURL:   issue.HTMLURL,
+		DiffURL:   issue.HTMLURL + ".diff",
+		PatchURL:  issue.HTMLURL + ".patch",
+		Mergeable: true,
+		Base:      r.branchInfo(opt.Base),
+		Head:      r.branchInfo(opt.Head),
+		Created:   &Time,
+		Updated:   &Time,
+	}
+	r.pulls = append(r.pulls, pr)
+	return pr
+}
+
+func (r *Repo) branchInfo(branch string) *gitea.PRBranchInfo {
+	return &gitea.PRBranchInfo{
+		Name:       branch,
+		Ref:        branch,
+		Sha:        r.branches[branch],
+		RepoID:     r.ID,
+		Repository: r.Repository,
+	}
+}
+
+// setLabels changes the labels of an issue to ids, answering 422 if one of
+// them does not exist.
+func (r *Repo) setLabels(w http.ResponseWriter, issue *gitea.Issue, ids []int64) bool {
+	labels := make([]*gitea.Label, 0, len(ids))
+	for _, id := range ids {
+		label := r.label(id)
+		if label == nil {
+			writeError(w, http.StatusUnprocessableEntity, "label does not exist [label_id: %d]", id)
+			return false
+		}
+		labels = append(labels, label)
+	}
+	issue.Labels = labels
+	return true
+}
+
+func (s *Server) issueRoutes() {
+	s.handleRepo("GET /issues", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		query := r.URL.Query()
+		state := query.Get("state")
+		if state == "" {
+			state = string(gitea.StateOpen)
+		}
+		issues := []*gitea.Issue{}
+		for i := len(repo.issues) - 1; i >= 0; i-- {
+			issue := repo.issues[i]
+			isPull := issue.PullRequest != nil
+			switch {
+			case state != string(gitea.StateAll) && state != string(issue.State):
+			case query.Get("type") == "issues" && isPull:
+			case query.Get("type") == "pulls" && !isPull:
+			default:
+				issues = append(issues, issue)
+			}
+		}
+		writeJSON(w, http.StatusOK, paginate(r, issues))
+	})
+
+	s.handleRepo("POST /issues", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		var opt gitea.CreateIssueOption
+		if !decode(w, r, &opt) {
+			return
+		}
+		if opt.Title == "" {
+			writeError(w, http.StatusUnprocessableEntity, "[Title]: Required")
+			return
+		}
+		writeJSON(w, http.StatusCreated, repo.addIssue(opt.Title, opt.Body))
+	})
+
+	s.handleRepo("GET /issues/{index}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		if issue := repo.pathIssue(w, r); issue != nil {
+			writeJSON(w, http.StatusOK, issue)
+		}
+	})
+
+	s.handleRepo("PATCH /issues/{index}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		issue := repo.pathIssue(w, r)
+		if issue == nil {
+			return
+		}
+		var opt gitea.EditIssueOption
+		if !decode(w, r, &opt) {
+			return
+		}
+		if opt.Title != "" {
+			issue.Title = opt.Title
+		}
+		if opt.Body != nil {
+			issue.Body = *opt.Body
+		}
+		if opt.Assignees != nil {
+			assignees := make([]*gitea.User, 0, len(opt.Assignees))
+			for _, login := range opt.Assignees {
+				u, ok := s.users[login]
+				if !ok {
+					writeError(w, http.StatusUnprocessableEntity, "user does not exist [uid: 0, name: %s]", login)
+					return
+				}
+				assignees = append(assignees, u)
+			}
+			issue.Assignees = assignees
+		}
+		if opt.State != nil {
+			switch *opt.State {
+			case gitea.StateOpen:
+				issue.Closed = nil
+			case gitea.StateClosed:
+				issue.Closed = &Time
+			default:
+				writeError(w, http.StatusUnprocessableEntity, "unknown state %s", *opt.State)
+				return
+			}
+			issue.State = *opt.State
+		}
+		writeJSON(w, http.StatusCreated, issue)
+	})
+
+	s.handleRepo("GET /issues/{index}/comments", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		if issue := repo.pathIssue(w, r); issue != nil {
+			comments := append([]*gitea.Comment{}, repo.comments[issue.Index]...)
+			writeJSON(w, http.StatusOK, comments)
+		}
+	})
+
+	s.handleRepo("POST /issues/{index}/comments", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		issue := repo.pathIssue(w, r)
+		if issue == nil {
+			return
+		}
+		var opt gitea.CreateIssueCommentOption
+		if !decode(w, r, &opt) {
+			return
+		}
+		writeJSON(w, http.StatusCreated, repo.addComment(issue, opt.Body))
+	})
+
+	s.handleRepo("PATCH /issues/comments/{id}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		id, ok := pathID(w, r, "id")
+		if !ok {
+			return
+		}
+		var opt gitea.EditIssueCommentOption
+		if !decode(w, r, &opt) {
+			return
+		}
+		for _, comments := range repo.comments {
+			for _, comment := range comments {
+				if comment.ID == id {
+					comment.Body = opt.Body
+					writeJSON(w, http.StatusOK, comment)
+					return
+				}
+			}
+		}
+		writeNotFound(w)
+	})
+
+	s.handleRepo("POST /issues/{index}/labels", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		issue := repo.pathIssue(w, r)
+		if issue == nil {
+			return
+		}
+		var opt gitea.IssueLabelsOption
+		if !decode(w, r, &opt) {
+			return
+		}
+		ids := make([]int64, 0, len(issue.Labels)+len(opt.Labels))
+		for _, label := range issue.Labels {
+			ids = append(ids, label.ID)
+		}
+		for _, id := range opt.Labels {
+			if !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+		if repo.setLabels(w, issue, ids) {
+			writeJSON(w, http.StatusOK, issue.Labels)
+		}
+	})
+
+	s.handleRepo("PUT /issues/{index}/labels", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		issue := repo.pathIssue(w, r)
+		if issue == nil {
+			return
+		}
+		var opt gitea.IssueLabelsOption
+		if !decode(w, r, &opt) {
+			return
+		}
+		if repo.setLabels(w, issue, opt.Labels) {
+			writeJSON(w, http.StatusOK, issue.Labels)
+		}
+	})
+
+	s.handleRepo("DELETE /issues/{index}/labels", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		if issue := repo.pathIssue(w, r); issue != nil {
+			issue.Labels = []*gitea.Label{}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	s.handleRepo("DELETE /issues/{index}/labels/{id}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		issue := repo.pathIssue(w, r)
+		if issue == nil {
+			return
+		}
+		id, ok := pathID(w, r, "id")
+		if !ok {
+			return
+		}
+		if repo.label(id) == nil {
+			writeError(w, http.StatusUnprocessableEntity, "label does not exist [label_id: %d]", id)
+			return
+		}
+		issue.Labels = slices.DeleteFunc(issue.Labels, func(label *gitea.Label) bool {
+			return label.ID == id
+		})
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	s.handleRepo("GET /labels", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		writeJSON(w, http.StatusOK, paginate(r, append([]*gitea.Label{}, repo.labels...)))
+	})
+
+	s.handleRepo("POST /labels", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		var opt gitea.CreateLabelOption
+		if !decode(w, r, &opt) {
+			return
+		}
+		if opt.Name == "" {
+			writeError(w, http.StatusUnprocessableEntity, "[Name]: Required")
+			return
+		}
+		writeJSON(w, http.StatusCreated, repo.addLabel(opt))
+	})
+
+	s.handleRepo("GET /labels/{id}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		if label := repo.pathLabel(w, r); label != nil {
+			writeJSON(w, http.StatusOK, label)
+		}
+	})
+
+	s.handleRepo("PATCH /labels/{id}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		label := repo.pathLabel(w, r)
+		if label == nil {
+			return
+		}
+		editLabel(w, r, label)
+	})
+
+	s.handleRepo("DELETE /labels/{id}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		label := repo.pathLabel(w, r)
+		if label == nil {
+			return
+		}
+		repo.labels = slices.DeleteFunc(repo.labels, func(l *gitea.Label) bool {
+			return l == label
+		})
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	s.handleRepo("GET /pulls", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		state := r.URL.Query().Get("state")
+		if state == "" {
+			state = string(gitea.StateOpen)
+		}
+		pulls := []*gitea.PullRequest{}
+		for i := len(repo.pulls) - 1; i >= 0; i-- {
+			if pr := repo.pulls[i]; state == string(gitea.StateAll) || state == string(pr.State) {
+				pulls = append(pulls, pr)
+			}
+		}
+		writeJSON(w, http.StatusOK, paginate(r, pulls))
+	})
+
+	s.handleRepo("POST /pulls", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		var opt gitea.CreatePullRequestOption
+		if !decode(w, r, &opt) {
+			return
+		}
+		for _, branch := range []string{opt.Head, opt.Base} {
+			if _, ok := repo.branches[branch]; !ok {
+				writeError(w, http.StatusNotFound, "branch does not exist [name: %s]", branch)
+				return
+			}
+		}
+		if repo.branches[opt.Head] == repo.branches[opt.Base] {
+			writeError(w, http.StatusUnprocessableEntity, "There are no changes between the head and the base")
+			return
+		}
+		writeJSON(w, http.StatusCreated, repo.addPull(opt))
+	})
+
+	s.handleRepo("GET /pulls/{index}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		index, ok := pathID(w, r, "index")
+		if !ok {
+			return
+		}
+		for _, pr := range repo.pulls {
+			if pr.Index == index {
+				writeJSON(w, http.StatusOK, pr)
+				return
+			}
+		}
+		writeNotFound(w)
+	})
+}
+
+func (r *Repo) pathIssue(w http.ResponseWriter, req *http.Request) *gitea.Issue {
+	index, ok := pathID(w, req, "index")
+	if !ok {
+		return nil
+	}
+	issue := r.issue(index)
+	if issue == nil {
+		writeNotFound(w)
+	}
+	return issue
+}
+
+// editLabel applies the EditLabelOption in the body of r to label.
+func editLabel(w http.ResponseWriter, r *http.Request, label *gitea.Label) {
+	var opt gitea.EditLabelOption
+	if !decode(w, r, &opt) {
+		return
+	}
+	if opt.Name != nil {
+		label.Name = *opt.Name
+	}
+	if opt.Color != nil {
+		label.Color = strings.TrimPrefix(*opt.Color, "#")
+	}
+	if opt.Description != nil {
+		label.Description = *opt.Description
+	}
+	writeJSON(w, http.StatusOK, label)
+}
+
+func (r *Repo) pathLabel(w http.ResponseWriter, req *http.Request) *gitea.Label {
+	id, ok := pathID(w, req, "id")
+	if !ok {
+		return nil
+	}
+	label := r.label(id)
+	if label == nil {
+		writeNotFound(w)
+	}
+	return label
+}
@@ -0,0 +1,205 @@
+package giteatest
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// Org returns the named organization, or nil if it does not exist.
+func (s *Server) Org(name string) *gitea.Organization {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.orgs[name]
+}
+
+// PublicizeMember makes the membership of login in org public.
+func (s *Server) PublicizeMember(org, login string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !slices.Contains(s.public[org], login) {
+		s.public[org] = append(s.public[org], login)
+		slices.Sort(s.public[org])
+	}
+}
+
+// AddOrgLabel creates a label of org.
+func (s *Server) AddOrgLabel(org, name, color string) *gitea.Label {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addOrgLabel(org, gitea.CreateLabelOption{Name: name, Color: color})
+}
+
+// OrgLabels returns the labels of org.
+func (s *Server) OrgLabels(org string) []*gitea.Label {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*gitea.Label(nil), s.orgLabels[org]...)
+}
+
+func (s *Server) addOrgLabel(org string, opt gitea.CreateLabelOption) *gitea.Label {
+	id := s.id()
+	label := &gitea.Label{
+		ID:          id,
+		Name:        opt.Name,
+		Color:       strings.TrimPrefix(opt.Color, "#"),
+		Description: opt.Description,
+		URL:         fmt.Sprintf("%s/api/v1/orgs/%s/labels/%d", s.URL, org, id),
+	}
+	s.orgLabels[org] = append(s.orgLabels[org], label)
+	return label
+}
+
+// orgMembers returns the logins of the members of org, sorted: the
+// authenticated user, who owns every organization, and the members of its
+// teams.
+func (s *Server) orgMembers(org string) []string {
+	logins := []string{s.user.UserName}
+	for _, team := range s.teams[org] {
+		logins = append(logins, s.members[team.ID]...)
+	}
+	slices.Sort(logins)
+	return slices.Compact(logins)
+}
+
+func (s *Server) userList(logins []string) []*gitea.User {
+	users := make([]*gitea.User, 0, len(logins))
+	for _, login := range logins {
+		users = append(users, s.users[login])
+	}
+	return users
+}
+
+func (s *Server) orgRoutes() {
+	// handleOrg registers h for a path below /orgs/{org}, answering 404 for
+	// organizations that do not exist.
+	handleOrg := func(pattern string, h func(http.ResponseWriter, *http.Request, *gitea.Organization)) {
+		method, path, _ := strings.Cut(pattern, " ")
+		s.handle(method+" /orgs/{org}"+path, func(w http.ResponseWriter, r *http.Request) {
+			org, ok := s.orgs[r.PathValue("org")]
+			if !ok {
+				writeNotFound(w)
+				return
+			}
+			h(w, r, org)
+		})
+	}
+
+	s.handle("POST /orgs", func(w http.ResponseWriter, r *http.Request) {
+		var opt gitea.CreateOrgOption
+		if !decode(w, r, &opt) {
+			return
+		}
+		if opt.Name == "" {
+			writeError(w, http.StatusUnprocessableEntity, "[UserName]: Required")
+			return
+		}
+		if s.owner(opt.Name) != nil {
+			writeError(w, http.StatusUnprocessableEntity, "user already exists [name: %s]", opt.Name)
+			return
+		}
+		writeJSON(w, http.StatusCreated, s.addOrg(opt))
+	})
+
+	handleOrg("GET ", func(w http.ResponseWriter, r *http.Request, org *gitea.Organization) {
+		writeJSON(w, http.StatusOK, org)
+	})
+
+	// Like Gitea, edits replace every text field.
+	handleOrg("PATCH ", func(w http.ResponseWriter, r *http.Request, org *gitea.Organization) {
+		var opt gitea.EditOrgOption
+		if !decode(w, r, &opt) {
+			return
+		}
+		org.FullName = opt.FullName
+		org.Description = opt.Description
+		org.Website = opt.Website
+		org.Location = opt.Location
+		if opt.Visibility != "" {
+			org.Visibility = string(opt.Visibility)
+		}
+		writeJSON(w, http.StatusOK, org)
+	})
+
+	handleOrg("GET /members", func(w http.ResponseWriter, r *http.Request, org *gitea.Organization) {
+		writeJSON(w, http.StatusOK, paginate(r, s.userList(s.orgMembers(org.UserName))))
+	})
+
+	handleOrg("GET /public_members", func(w http.ResponseWriter, r *http.Request, org *gitea.Organization) {
+		writeJSON(w, http.StatusOK, paginate(r, s.userList(s.public[org.UserName])))
+	})
+
+	handleOrg("GET /members/{username}", func(w http.ResponseWriter, r *http.Request, org *gitea.Organization) {
+		if !slices.Contains(s.orgMembers(org.UserName), r.PathValue("username")) {
+			writeNotFound(w)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	handleOrg("GET /public_members/{username}", func(w http.ResponseWriter, r *http.Request, org *gitea.Organization) {
+		if !slices.Contains(s.public[org.UserName], r.PathValue("username")) {
+			writeNotFound(w)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	handleOrg("GET /labels", func(w http.ResponseWriter, r *http.Request, org *gitea.Organization) {
+		writeJSON(w, http.StatusOK, paginate(r, append([]*gitea.Label{}, s.orgLabels[org.UserName]...)))
+	})
+
+	handleOrg("POST /labels", func(w http.ResponseWriter, r *http.Request, org *gitea.Organization) {
+		var opt gitea.CreateLabelOption
+		if !decode(w, r, &opt) {
+			return
+		}
+		if opt.Name == "" {
+			writeError(w, http.StatusUnprocessableEntity, "[Name]: Required")
+			return
+		}
+		writeJSON(w, http.StatusCreated, s.addOrgLabel(org.UserName, opt))
+	})
+
+	// pathLabel returns the label of org the id path value names, answering
+	// 404 if there is none.
+	pathLabel := func(w http.ResponseWriter, r *http.Request, org *gitea.Organization) *gitea.Label {
+		id, ok := pathID(w, r, "id")
+		if !ok {
+			return nil
+		}
+		for _, label := range s.orgLabels[org.UserName] {
+			if label.ID == id {
+				return label
+			}
+		}
+		writeNotFound(w)
+		return nil
+	}
+
+	handleOrg("GET /labels/{id}", func(w http.ResponseWriter, r *http.Request, org *gitea.Organization) {
+		if label := pathLabel(w, r, org); label != nil {
+			writeJSON(w, http.StatusOK, label)
+		}
+	})
+
+	handleOrg("PATCH /labels/{id}", func(w http.ResponseWriter, r *http.Request, org *gitea.Organization) {
+		if label := pathLabel(w, r, org); label != nil {
+			editLabel(w, r, label)
+		}
+	})
+
+	handleOrg("DELETE /labels/{id}", func(w http.ResponseWriter, r *http.Request, org *gitea.Organization) {
+		label := pathLabel(w, r, org)
+		if label == nil {
+			return
+		}
+		s.orgLabels[org.UserName] = slices.DeleteFunc(s.orgLabels[org.UserName], func(l *gitea.Label) bool {
+			return l == label
+		})
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
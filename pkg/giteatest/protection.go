@@ -0,0 +1,167 @@
+package giteatest
+
+import (
+	"net/http"
+	"path"
+	"slices"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// Protect adds a branch protection rule for the branches matching rule that
+// forbids pushing.
+func (r *Repo) Protect(rule string) *gitea.BranchProtection {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+	return r.addProtection(gitea.CreateBranchProtectionOption{RuleName: rule})
+}
+
+func (r *Repo) addProtection(opt gitea.CreateBranchProtectionOption) *gitea.BranchProtection {
+	p := &gitea.BranchProtection{
+		BranchName:                    opt.RuleName,
+		RuleName:                      opt.RuleName,
+		EnablePush:                    opt.EnablePush,
+		EnablePushWhitelist:           opt.EnablePushWhitelist,
+		PushWhitelistUsernames:        nonNil(opt.PushWhitelistUsernames),
+		PushWhitelistTeams:            nonNil(opt.PushWhitelistTeams),
+		PushWhitelistDeployKeys:       opt.PushWhitelistDeployKeys,
+		EnableMergeWhitelist:          opt.EnableMergeWhitelist,
+		MergeWhitelistUsernames:       nonNil(opt.MergeWhitelistUsernames),
+		MergeWhitelistTeams:           nonNil(opt.MergeWhitelistTeams),
+		EnableStatusCheck:             opt.EnableStatusCheck,
+		StatusCheckContexts:           nonNil(opt.StatusCheckContexts),
+		RequiredApprovals:             opt.RequiredApprovals,
+		EnableApprovalsWhitelist:      opt.EnableApprovalsWhitelist,
+		ApprovalsWhitelistUsernames:   nonNil(opt.ApprovalsWhitelistUsernames),
+		ApprovalsWhitelistTeams:       nonNil(opt.ApprovalsWhitelistTeams),
+		BlockOnRejectedReviews:        opt.BlockOnRejectedReviews,
+		BlockOnOfficialReviewRequests: opt.BlockOnOfficialReviewRequests,
+		BlockOnOutdatedBranch:         opt.BlockOnOutdatedBranch,
+		DismissStaleApprovals:         opt.DismissStaleApprovals,
+		RequireSignedCommits:          opt.RequireSignedCommits,
+		ProtectedFilePatterns:         opt.ProtectedFilePatterns,
+		UnprotectedFilePatterns:       opt.UnprotectedFilePatterns,
+		Created:                       Time,
+		Updated:                       Time,
+	}
+	r.protections = append(r.protections, p)
+	return p
+}
+
+// protection returns the rule protecting branch: the rule named after it, or
+// else the first rule whose glob matches it. It returns nil if the branch is
+// not protected.
+func (r *Repo) protection(branch string) *gitea.BranchProtection {
+	for _, p := range r.protections {
+		if p.RuleName == branch {
+			return p
+		}
+	}
+	for _, p := range r.protections {
+		if ok, _ := path.Match(p.RuleName, branch); ok {
+			return p
+		}
+	}
+	return nil
+}
+
+func (r *Repo) protectionIndex(w http.ResponseWriter, req *http.Request) int {
+	name := req.PathValue("name")
+	i := slices.IndexFunc(r.protections, func(p *gitea.BranchProtection) bool { return p.RuleName == name })
+	if i < 0 {
+		writeNotFound(w)
+	}
+	return i
+}
+
+func (s *Server) protectionRoutes() {
+	s.handleRepo("GET /branch_protections", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		writeJSON(w, http.StatusOK, append([]*gitea.BranchProtection{}, repo.protections...))
+	})
+
+	s.handleRepo("POST /branch_protections", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		var opt gitea.CreateBranchProtectionOption
+		if !decode(w, r, &opt) {
+			return
+		}
+		name := opt.RuleName
+		if name == "" {
+			name = opt.BranchName
+		}
+		if slices.ContainsFunc(repo.protections, func(p *gitea.BranchProtection) bool { return p.RuleName == name }) {
+			writeError(w, http.StatusForbidden, "Branch protection already exist")
+			return
+		}
+		opt.RuleName = name
+		writeJSON(w, http.StatusCreated, repo.addProtection(opt))
+	})
+
+	s.handleRepo("GET /branch_protections/{name...}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		if i := repo.protectionIndex(w, r); i >= 0 {
+			writeJSON(w, http.StatusOK, repo.protections[i])
+		}
+	})
+
+	s.handleRepo("PATCH /branch_protections/{name...}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		i := repo.protectionIndex(w, r)
+		if i < 0 {
+			return
+		}
+		var opt gitea.EditBranchProtectionOption
+		if !decode(w, r, &opt) {
+			return
+		}
+		p := repo.protections[i]
+		set(&p.EnablePush, opt.EnablePush)
+		set(&p.EnablePushWhitelist, opt.EnablePushWhitelist)
+		setList(&p.PushWhitelistUsernames, opt.PushWhitelistUsernames)
+		setList(&p.PushWhitelistTeams, opt.PushWhitelistTeams)
+		set(&p.PushWhitelistDeployKeys, opt.PushWhitelistDeployKeys)
+		set(&p.EnableMergeWhitelist, opt.EnableMergeWhitelist)
+		setList(&p.MergeWhitelistUsernames, opt.MergeWhitelistUsernames)
+		setList(&p.MergeWhitelistTeams, opt.MergeWhitelistTeams)
+		set(&p.EnableStatusCheck, opt.EnableStatusCheck)
+		setList(&p.StatusCheckContexts, opt.StatusCheckContexts)
+		set(&p.RequiredApprovals, opt.RequiredApprovals)
+		set(&p.EnableApprovalsWhitelist, opt.EnableApprovalsWhitelist)
+		setList(&p.ApprovalsWhitelistUsernames, opt.ApprovalsWhitelistUsernames)
+		setList(&p.ApprovalsWhitelistTeams, opt.ApprovalsWhitelistTeams)
+		set(&p.BlockOnRejectedReviews, opt.BlockOnRejectedReviews)
+		set(&p.BlockOnOfficialReviewRequests, opt.BlockOnOfficialReviewRequests)
+		set(&p.BlockOnOutdatedBranch, opt.BlockOnOutdatedBranch)
+		set(&p.DismissStaleApprovals, opt.DismissStaleApprovals)
+		set(&p.RequireSignedCommits, opt.RequireSignedCommits)
+		set(&p.ProtectedFilePatterns, opt.ProtectedFilePatterns)
+		set(&p.UnprotectedFilePatterns, opt.UnprotectedFilePatterns)
+		writeJSON(w, http.StatusOK, p)
+	})
+
+	s.handleRepo("DELETE /branch_protections/{name...}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		if i := repo.protectionIndex(w, r); i >= 0 {
+			repo.protections = slices.Delete(repo.protections, i, i+1)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+}
+
+// set changes *field to *v unless v is nil, for options that leave unset
+// fields unchanged.
+func set[T any](field *T, v *T) {
+	if v != nil {
+		*field = *v
+	}
+}
+
+// setList changes *field to v unless v is nil.
+func setList(field *[]string, v []string) {
+	if v != nil {
+		*field = v
+	}
+}
+
+func nonNil(v []string) []string {
+	if v == nil {
+		return []string{}
+	}
+	return v
+}
@@ -0,0 +1,1256 @@
+package giteatest
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gitea.com/gitea/gitea-mcp/pkg/diff"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// Repo is a repository of the fake server. Its git history is kept as a
+// chain of commits, each holding a full snapshot of the files.
+type Repo struct {
+	*gitea.Repository
+
+	server    *Server
+	commits   map[string]*commit
+	branches  map[string]string
+	tags      map[string]*tag
+	issues    []*gitea.Issue
+	pulls     []*gitea.PullRequest
+	comments  map[int64][]*gitea.Comment
+	labels    []*gitea.Label
+	releases  []*gitea.Release
+	lfs       map[string]string
+	statuses  map[string][]*gitea.Status
+	nextIndex int64
+	seq       int
+	// protections are the branch protection rules in creation order.
+	protections []*gitea.BranchProtection
+	// topics are kept sorted, like Gitea lists them.
+	topics []string
+	// collaborators maps the logins of the collaborators to their access.
+	collaborators map[string]gitea.AccessMode
+	// unreadable are the paths whose content is answered with 404.
+	unreadable map[string]bool
+}
+
+type commit struct {
+	sha     string
+	message string
+	parent  *commit
+	files   map[string]string
+	changed []string
+}
+
+type tag struct {
+	name    string
+	sha     string
+	message string
+}
+
+// AddRepo creates a repository with a default branch "main" holding a
+// README.md, owned by the user or organization named owner.
+func (s *Server) AddRepo(owner, name string) *Repo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.owner(owner) == nil {
+		s.users[owner] = &gitea.User{ID: s.id(), UserName: owner, IsActive: true, Created: Time}
+	}
+	repo := s.newRepo(owner, gitea.CreateRepoOption{Name: name, DefaultBranch: "main"})
+	repo.commit("main", "Initial commit", map[string]*string{"README.md": ptr("# " + name + "\n")})
+	return repo
+}
+
+// Repo returns the named repository, or nil if it does not exist.
+func (s *Server) Repo(owner, name string) *Repo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.repos[owner+"/"+name]
+}
+
+func (s *Server) newRepo(owner string, opt gitea.CreateRepoOption) *Repo {
+	defaultBranch := opt.DefaultBranch
+	if defaultBranch == "" {
+		defaultBranch = "main"
+	}
+	fullName := owner + "/" + opt.Name
+	repo := &Repo{
+		Repository: &gitea.Repository{
+			ID:              s.id(),
+			Owner:           s.owner(owner),
+			Name:            opt.Name,
+			FullName:        fullName,
+			Description:     opt.Description,
+			Empty:           true,
+			Private:         opt.Private,
+			Template:        opt.Template,
+			HTMLURL:         s.URL + "/" + fullName,
+			CloneURL:        s.URL + "/" + fullName + ".git",
+			DefaultBranch:   defaultBranch,
+			Created:         Time,
+			Updated:         Time,
+			HasIssues:       true,
+			HasWiki:         true,
+			HasPullRequests: true,
+			HasProjects:     true,
+			AllowMerge:      true,
+			AllowRebase:     true,
+			AllowSquash:     true,
+		},
+		server:   s,
+		commits:  make(map[string]*commit),
+		branches: make(map[string]string),
+		tags:     make(map[string]*tag),
+		comments: make(map[int64][]*gitea.Comment),
+		lfs:      make(map[string]string),
+		statuses: make(map[string][]*gitea.Status),
+	}
+	s.repos[fullName] = repo
+	return repo
+}
+
+// Commit writes files to branch in a new commit and returns its SHA. A branch
+// that does not exist yet is created from the default branch.
+func (r *Repo) Commit(branch, message string, files map[string]string) string {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+	changes := make(map[string]*string, len(files))
+	for p, content := range files {
+		changes[p] = ptr(content)
+	}
+	return r.commit(branch, message, changes).sha
+}
+
+// CommitLFS stores content as a Git LFS object and commits its pointer to
+// branch as the file p. It returns the SHA of the commit.
+func (r *Repo) CommitLFS(branch, message, p, content string) string {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+	sum := sha256.Sum256([]byte(content))
+	oid := hex.EncodeToString(sum[:])
+	r.lfs[oid] = content
+	pointer := fmt.Sprintf("version https://git-lfs.github.com/spec/v1\noid sha256:%s\nsize %d\n", oid, len(content))
+	return r.commit(branch, message, map[string]*string{p: &pointer}).sha
+}
+
+// Unreadable makes reading the content of the file p fail with 404, as when
+// it is deleted between listing a tree and reading it. It is still listed.
+func (r *Repo) Unreadable(p string) {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+	if r.unreadable == nil {
+		r.unreadable = make(map[string]bool)
+	}
+	r.unreadable[p] = true
+}
+
+// File returns the content of a file at ref, which may be a branch, tag or
+// commit SHA, or the default branch if empty.
+func (r *Repo) File(ref, filePath string) (string, bool) {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+	c := r.resolve(ref)
+	if c == nil {
+		return "", false
+	}
+	content, ok := c.files[filePath]
+	return content, ok
+}
+
+// Branch returns the SHA of the commit a branch points to.
+func (r *Repo) Branch(name string) (string, bool) {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+	sha, ok := r.branches[name]
+	return sha, ok
+}
+
+// Tag returns the SHA of the commit a tag points to.
+func (r *Repo) Tag(name string) (string, bool) {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+	t, ok := r.tags[name]
+	if !ok {
+		return "", false
+	}
+	return t.sha, true
+}
+
+// AddTag tags the head of the default branch.
+func (r *Repo) AddTag(name, message string) {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+	r.tags[name] = &tag{name: name, sha: r.branches[r.DefaultBranch], message: message}
+}
+
+// AddRelease publishes a release of a new tag on the default branch.
+func (r *Repo) AddRelease(tagName, title string) *gitea.Release {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+	if _, ok := r.tags[tagName]; !ok {
+		r.tags[tagName] = &tag{name: tagName, sha: r.branches[r.DefaultBranch]}
+	}
+	return r.addRelease(gitea.CreateReleaseOption{TagName: tagName, Target: r.DefaultBranch, Title: title})
+}
+
+// Releases returns the releases, newest first.
+func (r *Repo) Releases() []*gitea.Release {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+	return append([]*gitea.Release(nil), r.releases...)
+}
+
+// commit applies changes to branch, deleting the files mapped to nil. The
+// caller holds the server lock.
+func (r *Repo) commit(branch, message string, changes map[string]*string) *commit {
+	parent, ok := r.commits[r.branches[branch]]
+	if !ok {
+		parent = r.commits[r.branches[r.DefaultBranch]]
+	}
+	files := make(map[string]string)
+	if parent != nil {
+		for p, content := range parent.files {
+			files[p] = content
+		}
+	}
+	changed := sortedKeys(changes)
+	for _, p := range changed {
+		if content := changes[p]; content != nil {
+			files[p] = *content
+		} else {
+			delete(files, p)
+		}
+	}
+
+	r.seq++
+	h := sha1.New()
+	fmt.Fprintf(h, "%s\x00%d\x00%s\x00%s", r.FullName, r.seq, message, strings.Join(changed, "\x00"))
+	if parent != nil {
+		h.Write([]byte(parent.sha))
+	}
+	c := &commit{
+		sha:     hex.EncodeToString(h.Sum(nil)),
+		message: message,
+		parent:  parent,
+		files:   files,
+		changed: changed,
+	}
+	r.commits[c.sha] = c
+	r.branches[branch] = c.sha
+	r.Empty = false
+	return c
+}
+
+// resolve returns the commit ref points to, or nil.
+func (r *Repo) resolve(ref string) *commit {
+	if ref == "" {
+		ref = r.DefaultBranch
+	}
+	ref = strings.TrimPrefix(strings.TrimPrefix(ref, "refs/heads/"), "refs/tags/")
+	if sha, ok := r.branches[ref]; ok {
+		return r.commits[sha]
+	}
+	if t, ok := r.tags[ref]; ok {
+		return r.commits[t.sha]
+	}
+	if c, ok := r.commits[ref]; ok {
+		return c
+	}
+	if len(ref) >= 7 {
+		for sha, c := range r.commits {
+			if strings.HasPrefix(sha, ref) {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// touches reports whether c changed the file or directory p.
+func (c *commit) touches(p string) bool {
+	for _, changed := range c.changed {
+		if changed == p || strings.HasPrefix(changed, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// lastCommit returns the newest commit reachable from c that changed p.
+func lastCommit(c *commit, p string) *commit {
+	for ; c != nil; c = c.parent {
+		if c.touches(p) {
+			return c
+		}
+	}
+	return nil
+}
+
+// commitFile is a changed file as Gitea lists it for a commit.
+type commitFile struct {
+	Filename string `json:"filename"`
+	Status   string `json:"status"`
+}
+
+// changes lists the files differing between the snapshots from and to and
+// counts the changed lines.
+func changes(from, to map[string]string) ([]commitFile, *gitea.CommitStats) {
+	files := []commitFile{}
+	stats := &gitea.CommitStats{}
+	for _, p := range sortedKeys(mergeKeys(from, to)) {
+		old, inFrom := from[p]
+		content, inTo := to[p]
+		switch {
+		case !inFrom:
+			files = append(files, commitFile{p, "added"})
+		case !inTo:
+			files = append(files, commitFile{p, "removed"})
+		case old != content:
+			files = append(files, commitFile{p, "modified"})
+		default:
+			continue
+		}
+		for _, line := range strings.Split(diff.Unified(p, old, content, 0), "\n") {
+			switch {
+			case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+			case strings.HasPrefix(line, "+"):
+				stats.Additions++
+			case strings.HasPrefix(line, "-"):
+				stats.Deletions++
+			}
+		}
+	}
+	stats.Total = stats.Additions + stats.Deletions
+	return files, stats
+}
+
+// gitDiff returns the diff between the snapshots from and to in the format
+// of git diff.
+func gitDiff(from, to map[string]string) string {
+	var sb strings.Builder
+	for _, p := range sortedKeys(mergeKeys(from, to)) {
+		if d := diff.Unified(p, from[p], to[p], 3); d != "" {
+			fmt.Fprintf(&sb, "diff --git a/%s b/%s\n%s", p, p, d)
+		}
+	}
+	return sb.String()
+}
+
+func mergeKeys(a, b map[string]string) map[string]bool {
+	keys := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	return keys
+}
+
+// BlobSHA returns the git object ID of a file with the given content, which
+// Gitea reports as the SHA of a file.
+func BlobSHA(content string) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("blob %d\x00%s", len(content), content)))
+	return hex.EncodeToString(sum[:])
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func (s *Server) repoRoutes() {
+	s.handle("GET /user/repos", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, paginate(r, s.repoList(func(*Repo) bool { return true })))
+	})
+
+	s.handle("POST /user/repos", func(w http.ResponseWriter, r *http.Request) {
+		s.createRepo(w, r, s.user.UserName)
+	})
+
+	s.handle("GET /orgs/{org}/repos", func(w http.ResponseWriter, r *http.Request) {
+		org, ok := s.orgs[r.PathValue("org")]
+		if !ok {
+			writeNotFound(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, paginate(r, s.repoList(func(repo *Repo) bool { return repo.Owner.ID == org.ID })))
+	})
+
+	s.handle("POST /org/{org}/repos", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := s.orgs[r.PathValue("org")]; !ok {
+			writeNotFound(w)
+			return
+		}
+		s.createRepo(w, r, r.PathValue("org"))
+	})
+
+	s.handle("GET /repos/search", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		q := strings.ToLower(query.Get("q"))
+		// Unless the search is exclusive, Gitea also lists the repositories
+		// the owner collaborates on.
+		var collaborator string
+		for login, u := range s.users {
+			if strconv.FormatInt(u.ID, 10) == query.Get("uid") && query.Get("exclusive") != "true" {
+				collaborator = login
+			}
+		}
+		for login, org := range s.orgs {
+			if strconv.FormatInt(org.ID, 10) == query.Get("uid") && query.Get("exclusive") != "true" {
+				collaborator = login
+			}
+		}
+		repos := s.repoList(func(repo *Repo) bool {
+			_, collaborates := repo.collaborators[collaborator]
+			switch {
+			case query.Get("uid") != "" && query.Get("uid") != strconv.FormatInt(repo.Owner.ID, 10) && !collaborates:
+				return false
+			case query.Get("is_private") != "" && query.Get("is_private") != strconv.FormatBool(repo.Private):
+				return false
+			case query.Get("archived") != "" && query.Get("archived") != strconv.FormatBool(repo.Archived):
+				return false
+			}
+			return strings.Contains(strings.ToLower(repo.Name), q) ||
+				query.Get("includeDesc") == "true" && strings.Contains(strings.ToLower(repo.Description), q)
+		})
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "data": paginate(r, repos)})
+	})
+
+	s.handleRepo("GET ", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		writeJSON(w, http.StatusOK, repo.Repository)
+	})
+
+	s.handleRepo("DELETE ", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		delete(s.repos, repo.FullName)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	s.handleRepo("PATCH ", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		var opt gitea.EditRepoOption
+		if !decode(w, r, &opt) {
+			return
+		}
+		if opt.DefaultBranch != nil {
+			if _, ok := repo.branches[*opt.DefaultBranch]; !ok {
+				writeError(w, http.StatusNotFound, "branch does not exist [name: %s]", *opt.DefaultBranch)
+				return
+			}
+		}
+		set(&repo.Description, opt.Description)
+		set(&repo.Website, opt.Website)
+		set(&repo.DefaultBranch, opt.DefaultBranch)
+		set(&repo.Private, opt.Private)
+		set(&repo.Template, opt.Template)
+		set(&repo.HasIssues, opt.HasIssues)
+		set(&repo.HasWiki, opt.HasWiki)
+		set(&repo.HasPullRequests, opt.HasPullRequests)
+		set(&repo.HasProjects, opt.HasProjects)
+		set(&repo.AllowMerge, opt.AllowMerge)
+		set(&repo.AllowRebase, opt.AllowRebase)
+		set(&repo.AllowRebaseMerge, opt.AllowRebaseMerge)
+		set(&repo.AllowSquash, opt.AllowSquash)
+		set(&repo.AllowFastForwardOnlyMerge, opt.AllowFastForwardOnlyMerge)
+		set(&repo.DefaultMergeStyle, opt.DefaultMergeStyle)
+		set(&repo.Archived, opt.Archived)
+		writeJSON(w, http.StatusOK, repo.Repository)
+	})
+
+	s.handleRepo("POST /transfer", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		var opt gitea.TransferRepoOption
+		if !decode(w, r, &opt) {
+			return
+		}
+		owner := s.owner(opt.NewOwner)
+		if owner == nil {
+			writeError(w, http.StatusNotFound, "The new owner does not exist or cannot be found")
+			return
+		}
+		if opt.TeamIDs != nil {
+			if _, ok := s.orgs[opt.NewOwner]; !ok {
+				writeError(w, http.StatusUnprocessableEntity, "Teams can only be added to organization-owned repositories")
+				return
+			}
+			for _, id := range *opt.TeamIDs {
+				if !slices.ContainsFunc(s.teams[opt.NewOwner], func(t *gitea.Team) bool { return t.ID == id }) {
+					writeError(w, http.StatusUnprocessableEntity, "team %d does not belong to %s", id, opt.NewOwner)
+					return
+				}
+			}
+		}
+		fullName := opt.NewOwner + "/" + repo.Name
+		if _, ok := s.repos[fullName]; ok {
+			writeError(w, http.StatusUnprocessableEntity, "repository already exists [uname: %s, name: %s]", opt.NewOwner, repo.Name)
+			return
+		}
+		delete(s.repos, repo.FullName)
+		repo.Owner = owner
+		repo.FullName = fullName
+		repo.HTMLURL = s.URL + "/" + fullName
+		repo.CloneURL = s.URL + "/" + fullName + ".git"
+		s.repos[fullName] = repo
+		if opt.TeamIDs != nil {
+			for _, id := range *opt.TeamIDs {
+				s.addTeamRepo(id, repo)
+			}
+		}
+		writeJSON(w, http.StatusAccepted, repo.Repository)
+	})
+
+	s.handleRepo("POST /forks", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		var opt gitea.CreateForkOption
+		if !decode(w, r, &opt) {
+			return
+		}
+		owner, name := s.user.UserName, repo.Name
+		if opt.Organization != nil {
+			if _, ok := s.orgs[*opt.Organization]; !ok {
+				writeError(w, http.StatusUnprocessableEntity, "organization %s does not exist", *opt.Organization)
+				return
+			}
+			owner = *opt.Organization
+		}
+		if opt.Name != nil {
+			name = *opt.Name
+		}
+		if _, ok := s.repos[owner+"/"+name]; ok {
+			writeError(w, http.StatusConflict, "The repository with the same name already exists.")
+			return
+		}
+		fork := s.newRepo(owner, gitea.CreateRepoOption{Name: name, Description: repo.Description, DefaultBranch: repo.DefaultBranch})
+		fork.Fork = true
+		fork.Parent = repo.Repository
+		fork.Empty = repo.Empty
+		for sha, c := range repo.commits {
+			fork.commits[sha] = c
+		}
+		for b, sha := range repo.branches {
+			fork.branches[b] = sha
+		}
+		for n, t := range repo.tags {
+			fork.tags[n] = t
+		}
+		repo.Forks++
+		writeJSON(w, http.StatusAccepted, fork.Repository)
+	})
+
+	s.handleRepo("GET /branches", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		branches := make([]*gitea.Branch, 0, len(repo.branches))
+		for _, name := range sortedKeys(repo.branches) {
+			branches = append(branches, repo.apiBranch(name))
+		}
+		writeJSON(w, http.StatusOK, paginate(r, branches))
+	})
+
+	s.handleRepo("POST /branches", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		var opt gitea.CreateBranchOption
+		if !decode(w, r, &opt) {
+			return
+		}
+		if _, ok := repo.branches[opt.BranchName]; ok {
+			writeError(w, http.StatusConflict, "The branch already exists.")
+			return
+		}
+		from := repo.resolve(opt.OldBranchName)
+		if from == nil {
+			writeError(w, http.StatusNotFound, "The old branch does not exist")
+			return
+		}
+		repo.branches[opt.BranchName] = from.sha
+		writeJSON(w, http.StatusCreated, repo.apiBranch(opt.BranchName))
+	})
+
+	s.handleRepo("DELETE /branches/{branch...}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		name := r.PathValue("branch")
+		if _, ok := repo.branches[name]; !ok {
+			writeError(w, http.StatusNotFound, "Branch doesn't exist.")
+			return
+		}
+		if name == repo.DefaultBranch {
+			writeError(w, http.StatusForbidden, "can not delete default branch")
+			return
+		}
+		if repo.protection(name) != nil {
+			writeError(w, http.StatusForbidden, "branch protected")
+			return
+		}
+		delete(repo.branches, name)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	s.handleRepo("GET /commits", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		if repo.Empty {
+			writeError(w, http.StatusConflict, "Git Repository is empty.")
+			return
+		}
+		head := repo.resolve(r.URL.Query().Get("sha"))
+		if head == nil {
+			writeError(w, http.StatusNotFound, "object does not exist [id: %s, rel_path: ]", r.URL.Query().Get("sha"))
+			return
+		}
+		excluded := map[*commit]bool{}
+		if not := r.URL.Query().Get("not"); not != "" {
+			for c := repo.resolve(not); c != nil; c = c.parent {
+				excluded[c] = true
+			}
+		}
+		filter := strings.Trim(r.URL.Query().Get("path"), "/")
+		commits := []*gitea.Commit{}
+		for c := head; c != nil && !excluded[c]; c = c.parent {
+			if filter == "" || c.touches(filter) {
+				commits = append(commits, repo.apiCommit(c))
+			}
+		}
+		w.Header().Set("X-Total-Count", strconv.Itoa(len(commits)))
+		writeJSON(w, http.StatusOK, paginate(r, commits))
+	})
+
+	s.handleRepo("GET /git/commits/{sha}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		ref, format, _ := strings.Cut(r.PathValue("sha"), ".")
+		c := repo.resolve(ref)
+		if c == nil {
+			writeError(w, http.StatusNotFound, "object does not exist [id: %s, rel_path: ]", ref)
+			return
+		}
+		var from map[string]string
+		if c.parent != nil {
+			from = c.parent.files
+		}
+		switch format {
+		case "diff":
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			fmt.Fprint(w, gitDiff(from, c.files))
+		case "":
+			files, stats := changes(from, c.files)
+			commit := repo.apiCommit(c)
+			commit.Stats = stats
+			writeJSON(w, http.StatusOK, struct {
+				*gitea.Commit
+				Files []commitFile `json:"files"`
+			}{commit, files})
+		default:
+			writeNotFound(w)
+		}
+	})
+
+	s.handleRepo("GET /compare/{basehead}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		baseRef, headRef, _ := strings.Cut(r.PathValue("basehead"), "...")
+		base, head := repo.resolve(baseRef), repo.resolve(headRef)
+		if base == nil || head == nil {
+			writeNotFound(w)
+			return
+		}
+		ancestors := make(map[*commit]bool)
+		for c := base; c != nil; c = c.parent {
+			ancestors[c] = true
+		}
+		commits := []*gitea.Commit{}
+		mergeBase := head
+		for ; mergeBase != nil && !ancestors[mergeBase]; mergeBase = mergeBase.parent {
+			commits = append(commits, repo.apiCommit(mergeBase))
+		}
+		var from map[string]string
+		if mergeBase != nil {
+			from = mergeBase.files
+		}
+		files, _ := changes(from, head.files)
+		writeJSON(w, http.StatusOK, struct {
+			TotalCommits int             `json:"total_commits"`
+			Commits      []*gitea.Commit `json:"commits"`
+			Files        []commitFile    `json:"files"`
+		}{len(commits), commits, files})
+	})
+
+	s.handleRepo("GET /contents/{path...}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		c := repo.resolve(r.URL.Query().Get("ref"))
+		if c == nil {
+			writeNotFound(w)
+			return
+		}
+		p := strings.Trim(r.PathValue("path"), "/")
+		if _, ok := c.files[p]; ok {
+			writeJSON(w, http.StatusOK, repo.apiContents(c, p, true))
+			return
+		}
+		entries := repo.dirEntries(c, p)
+		if len(entries) == 0 && p != "" {
+			writeError(w, http.StatusNotFound, "object does not exist [id: , rel_path: %s]", p)
+			return
+		}
+		writeJSON(w, http.StatusOK, entries)
+	})
+
+	s.handleRepo("GET /raw/{path...}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		repo.serveFile(w, r, false)
+	})
+
+	s.handleRepo("GET /media/{path...}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		repo.serveFile(w, r, true)
+	})
+
+	s.handleRepo("GET /git/trees/{sha}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		c, dir := repo.resolve(r.PathValue("sha")), ""
+		if c == nil {
+			c, dir = repo.resolveTree(r.PathValue("sha"))
+		}
+		if c == nil {
+			writeError(w, http.StatusBadRequest, "sha not provided")
+			return
+		}
+		writeJSON(w, http.StatusOK, repo.apiTree(c, dir, r))
+	})
+
+	s.handleRepo("POST /contents/{path...}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		var opt gitea.CreateFileOptions
+		if !decode(w, r, &opt) {
+			return
+		}
+		p := strings.Trim(r.PathValue("path"), "/")
+		branch, ok := repo.fileBranch(w, opt.FileOptions)
+		if !ok {
+			return
+		}
+		if _, exists := repo.commits[repo.branches[branch]].files[p]; exists {
+			writeError(w, http.StatusUnprocessableEntity, "repository file already exists [path: %s]", p)
+			return
+		}
+		repo.writeFile(w, http.StatusCreated, branch, opt.FileOptions, p, opt.Content, "Add "+p)
+	})
+
+	s.handleRepo("PUT /contents/{path...}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		var opt gitea.UpdateFileOptions
+		if !decode(w, r, &opt) {
+			return
+		}
+		p := strings.Trim(r.PathValue("path"), "/")
+		branch, ok := repo.fileBranch(w, opt.FileOptions)
+		if !ok {
+			return
+		}
+		content, exists := repo.commits[repo.branches[branch]].files[p]
+		if !exists {
+			writeError(w, http.StatusNotFound, "file does not exist [path: %s]", p)
+			return
+		}
+		if opt.SHA != BlobSHA(content) {
+			writeError(w, http.StatusConflict, "sha does not match [given: %s, expected: %s]", opt.SHA, BlobSHA(content))
+			return
+		}
+		repo.writeFile(w, http.StatusOK, branch, opt.FileOptions, p, opt.Content, "Update "+p)
+	})
+
+	s.handleRepo("DELETE /contents/{path...}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		var opt gitea.DeleteFileOptions
+		if !decode(w, r, &opt) {
+			return
+		}
+		p := strings.Trim(r.PathValue("path"), "/")
+		branch, ok := repo.fileBranch(w, opt.FileOptions)
+		if !ok {
+			return
+		}
+		content, exists := repo.commits[repo.branches[branch]].files[p]
+		if !exists {
+			writeError(w, http.StatusNotFound, "file does not exist [path: %s]", p)
+			return
+		}
+		if opt.SHA != BlobSHA(content) {
+			writeError(w, http.StatusConflict, "sha does not match [given: %s, expected: %s]", opt.SHA, BlobSHA(content))
+			return
+		}
+		c := repo.commit(branch, messageOr(opt.Message, "Delete "+p), map[string]*string{p: nil})
+		writeJSON(w, http.StatusOK, gitea.FileDeleteResponse{Commit: repo.apiFileCommit(c)})
+	})
+
+	s.handleRepo("GET /tags", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		tags := make([]*gitea.Tag, 0, len(repo.tags))
+		for _, name := range sortedKeys(repo.tags) {
+			tags = append(tags, repo.apiTag(repo.tags[name]))
+		}
+		writeJSON(w, http.StatusOK, paginate(r, tags))
+	})
+
+	s.handleRepo("POST /tags", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		var opt gitea.CreateTagOption
+		if !decode(w, r, &opt) {
+			return
+		}
+		if _, ok := repo.tags[opt.TagName]; ok {
+			writeError(w, http.StatusConflict, "tag %s already exists", opt.TagName)
+			return
+		}
+		c := repo.resolve(opt.Target)
+		if c == nil {
+			writeError(w, http.StatusNotFound, "target %s does not exist", opt.Target)
+			return
+		}
+		t := &tag{name: opt.TagName, sha: c.sha, message: opt.Message}
+		repo.tags[t.name] = t
+		writeJSON(w, http.StatusCreated, repo.apiTag(t))
+	})
+
+	s.handleRepo("GET /tags/{tag...}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		t, ok := repo.tags[r.PathValue("tag")]
+		if !ok {
+			writeNotFound(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, repo.apiTag(t))
+	})
+
+	s.handleRepo("DELETE /tags/{tag...}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		name := r.PathValue("tag")
+		if _, ok := repo.tags[name]; !ok {
+			writeNotFound(w)
+			return
+		}
+		for _, rel := range repo.releases {
+			if rel.TagName == name {
+				writeError(w, http.StatusConflict, "a tag attached to a release cannot be deleted directly")
+				return
+			}
+		}
+		delete(repo.tags, name)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	s.handleRepo("GET /releases", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		query := r.URL.Query()
+		releases := []*gitea.Release{}
+		for _, rel := range repo.releases {
+			if query.Get("draft") != "" && query.Get("draft") != strconv.FormatBool(rel.IsDraft) ||
+				query.Get("pre-release") != "" && query.Get("pre-release") != strconv.FormatBool(rel.IsPrerelease) {
+				continue
+			}
+			releases = append(releases, rel)
+		}
+		writeJSON(w, http.StatusOK, paginate(r, releases))
+	})
+
+	s.handleRepo("POST /releases", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		var opt gitea.CreateReleaseOption
+		if !decode(w, r, &opt) {
+			return
+		}
+		for _, rel := range repo.releases {
+			if rel.TagName == opt.TagName {
+				writeError(w, http.StatusConflict, "release tag already exist [tag_name: %s]", opt.TagName)
+				return
+			}
+		}
+		if _, ok := repo.tags[opt.TagName]; !ok {
+			c := repo.resolve(opt.Target)
+			if c == nil {
+				writeError(w, http.StatusNotFound, "target %s does not exist", opt.Target)
+				return
+			}
+			repo.tags[opt.TagName] = &tag{name: opt.TagName, sha: c.sha}
+		}
+		writeJSON(w, http.StatusCreated, repo.addRelease(opt))
+	})
+
+	s.handleRepo("GET /releases/latest", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		for _, rel := range repo.releases {
+			if !rel.IsDraft && !rel.IsPrerelease {
+				writeJSON(w, http.StatusOK, rel)
+				return
+			}
+		}
+		writeNotFound(w)
+	})
+
+	s.handleRepo("GET /releases/{id}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		if i := repo.releaseIndex(w, r); i >= 0 {
+			writeJSON(w, http.StatusOK, repo.releases[i])
+		}
+	})
+
+	s.handleRepo("DELETE /releases/{id}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		if i := repo.releaseIndex(w, r); i >= 0 {
+			repo.releases = append(repo.releases[:i], repo.releases[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+}
+
+func (s *Server) repoList(keep func(*Repo) bool) []*gitea.Repository {
+	repos := []*gitea.Repository{}
+	for _, name := range sortedKeys(s.repos) {
+		if repo := s.repos[name]; keep(repo) {
+			repos = append(repos, repo.Repository)
+		}
+	}
+	return repos
+}
+
+func (s *Server) createRepo(w http.ResponseWriter, r *http.Request, owner string) {
+	var opt gitea.CreateRepoOption
+	if !decode(w, r, &opt) {
+		return
+	}
+	if opt.Name == "" {
+		writeError(w, http.StatusUnprocessableEntity, "[Name]: Required")
+		return
+	}
+	if _, ok := s.repos[owner+"/"+opt.Name]; ok {
+		writeError(w, http.StatusConflict, "The repository with the same name already exists.")
+		return
+	}
+	repo := s.newRepo(owner, opt)
+	if opt.AutoInit {
+		readme := "# " + opt.Name + "\n"
+		if opt.Description != "" {
+			readme += "\n" + opt.Description + "\n"
+		}
+		repo.commit(repo.DefaultBranch, "Initial commit", map[string]*string{"README.md": &readme})
+	}
+	writeJSON(w, http.StatusCreated, repo.Repository)
+}
+
+// fileBranch returns the branch a file operation commits to, creating the new
+// branch it asks for.
+func (r *Repo) fileBranch(w http.ResponseWriter, opt gitea.FileOptions) (string, bool) {
+	branch := opt.BranchName
+	if branch == "" {
+		branch = r.DefaultBranch
+	}
+	if _, ok := r.branches[branch]; !ok {
+		writeError(w, http.StatusNotFound, "branch does not exist [name: %s]", branch)
+		return "", false
+	}
+	if opt.NewBranchName == "" {
+		return branch, true
+	}
+	if _, ok := r.branches[opt.NewBranchName]; ok {
+		writeError(w, http.StatusUnprocessableEntity, "branch already exists [name: %s]", opt.NewBranchName)
+		return "", false
+	}
+	r.branches[opt.NewBranchName] = r.branches[branch]
+	return opt.NewBranchName, true
+}
+
+func (r *Repo) writeFile(w http.ResponseWriter, status int, branch string, opt gitea.FileOptions, p, content, defaultMessage string) {
+	data, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "invalid base64 content: %v", err)
+		return
+	}
+	c := r.commit(branch, messageOr(opt.Message, defaultMessage), map[string]*string{p: ptr(string(data))})
+	writeJSON(w, status, gitea.FileResponse{
+		Content: r.apiContents(c, p, true),
+		Commit:  r.apiFileCommit(c),
+	})
+}
+
+func messageOr(message, def string) string {
+	if message == "" {
+		return def
+	}
+	return message
+}
+
+func (r *Repo) addRelease(opt gitea.CreateReleaseOption) *gitea.Release {
+	id := r.server.id()
+	rel := &gitea.Release{
+		ID:           id,
+		TagName:      opt.TagName,
+		Target:       opt.Target,
+		Title:        opt.Title,
+		Note:         opt.Note,
+		URL:          fmt.Sprintf("%s/api/v1/repos/%s/releases/%d", r.server.URL, r.FullName, id),
+		HTMLURL:      r.HTMLURL + "/releases/tag/" + opt.TagName,
+		IsDraft:      opt.IsDraft,
+		IsPrerelease: opt.IsPrerelease,
+		CreatedAt:    Time,
+		PublishedAt:  Time,
+		Publisher:    r.server.user,
+		Attachments:  []*gitea.Attachment{},
+	}
+	// Newest first, like Gitea lists them.
+	r.releases = append([]*gitea.Release{rel}, r.releases...)
+	r.Repository.Releases++
+	return rel
+}
+
+func (r *Repo) releaseIndex(w http.ResponseWriter, req *http.Request) int {
+	id, ok := pathID(w, req, "id")
+	if !ok {
+		return -1
+	}
+	for i, rel := range r.releases {
+		if rel.ID == id {
+			return i
+		}
+	}
+	writeNotFound(w)
+	return -1
+}
+
+func (r *Repo) apiBranch(name string) *gitea.Branch {
+	c := r.commits[r.branches[name]]
+	b := &gitea.Branch{
+		Name: name,
+		Commit: &gitea.PayloadCommit{
+			ID:        c.sha,
+			Message:   c.message,
+			URL:       r.HTMLURL + "/commit/" + c.sha,
+			Author:    r.payloadUser(),
+			Committer: r.payloadUser(),
+			Timestamp: Time,
+		},
+		UserCanPush:  true,
+		UserCanMerge: true,
+	}
+	if p := r.protection(name); p != nil {
+		b.Protected = true
+		b.EffectiveBranchProtectionName = p.RuleName
+		b.RequiredApprovals = p.RequiredApprovals
+		b.EnableStatusCheck = p.EnableStatusCheck
+		b.StatusCheckContexts = p.StatusCheckContexts
+		b.UserCanPush = p.EnablePush && (!p.EnablePushWhitelist || slices.Contains(p.PushWhitelistUsernames, r.server.user.UserName))
+	}
+	return b
+}
+
+func (r *Repo) payloadUser() *gitea.PayloadUser {
+	u := r.server.user
+	return &gitea.PayloadUser{Name: u.FullName, Email: u.Email, UserName: u.UserName}
+}
+
+func (r *Repo) commitUser() *gitea.CommitUser {
+	u := r.server.user
+	return &gitea.CommitUser{
+		Identity: gitea.Identity{Name: u.FullName, Email: u.Email},
+		Date:     Time.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+func (r *Repo) commitMeta(sha string) *gitea.CommitMeta {
+	return &gitea.CommitMeta{
+		URL:     fmt.Sprintf("%s/api/v1/repos/%s/git/commits/%s", r.server.URL, r.FullName, sha),
+		SHA:     sha,
+		Created: Time,
+	}
+}
+
+func (r *Repo) apiCommit(c *commit) *gitea.Commit {
+	parents := []*gitea.CommitMeta{}
+	if c.parent != nil {
+		parents = append(parents, r.commitMeta(c.parent.sha))
+	}
+	files := make([]*gitea.CommitAffectedFiles, 0, len(c.changed))
+	for _, p := range c.changed {
+		files = append(files, &gitea.CommitAffectedFiles{Filename: p})
+	}
+	return &gitea.Commit{
+		CommitMeta: r.commitMeta(c.sha),
+		HTMLURL:    r.HTMLURL + "/commit/" + c.sha,
+		RepoCommit: &gitea.RepoCommit{
+			URL:       fmt.Sprintf("%s/api/v1/repos/%s/git/commits/%s", r.server.URL, r.FullName, c.sha),
+			Author:    r.commitUser(),
+			Committer: r.commitUser(),
+			Message:   c.message,
+			Tree:      r.commitMeta(c.sha),
+		},
+		Author:    r.server.user,
+		Committer: r.server.user,
+		Parents:   parents,
+		Files:     files,
+	}
+}
+
+func (r *Repo) apiFileCommit(c *commit) *gitea.FileCommitResponse {
+	parents := []*gitea.CommitMeta{}
+	if c.parent != nil {
+		parents = append(parents, r.commitMeta(c.parent.sha))
+	}
+	return &gitea.FileCommitResponse{
+		CommitMeta: *r.commitMeta(c.sha),
+		HTMLURL:    r.HTMLURL + "/commit/" + c.sha,
+		Author:     r.commitUser(),
+		Committer:  r.commitUser(),
+		Parents:    parents,
+		Message:    c.message,
+		Tree:       r.commitMeta(c.sha),
+	}
+}
+
+func (r *Repo) apiTag(t *tag) *gitea.Tag {
+	return &gitea.Tag{
+		Name:       t.name,
+		Message:    t.message,
+		ID:         t.sha,
+		Commit:     r.commitMeta(t.sha),
+		ZipballURL: r.HTMLURL + "/archive/" + t.name + ".zip",
+		TarballURL: r.HTMLURL + "/archive/" + t.name + ".tar.gz",
+	}
+}
+
+// apiContents describes the file or directory p at commit c.
+func (r *Repo) apiContents(c *commit, p string, withContent bool) *gitea.ContentsResponse {
+	cr := &gitea.ContentsResponse{
+		Name: path.Base(p),
+		Path: p,
+		URL:  ptr(fmt.Sprintf("%s/api/v1/repos/%s/contents/%s", r.server.URL, r.FullName, p)),
+	}
+	if last := lastCommit(c, p); last != nil {
+		cr.LastCommitSha = last.sha
+	}
+	content, isFile := c.files[p]
+	if !isFile {
+		sum := sha1.Sum([]byte("tree\x00" + c.sha + "\x00" + p))
+		cr.Type = "dir"
+		cr.SHA = hex.EncodeToString(sum[:])
+		return cr
+	}
+	cr.Type = "file"
+	cr.SHA = BlobSHA(content)
+	cr.Size = int64(len(content))
+	cr.HTMLURL = ptr(r.HTMLURL + "/src/commit/" + c.sha + "/" + p)
+	cr.DownloadURL = ptr(r.HTMLURL + "/raw/commit/" + c.sha + "/" + p)
+	if withContent {
+		cr.Encoding = ptr("base64")
+		cr.Content = ptr(base64.StdEncoding.EncodeToString([]byte(content)))
+	}
+	return cr
+}
+
+// serveFile writes the file named by the path value at the ref query
+// parameter. With lfs, a Git LFS pointer is replaced by its object.
+func (r *Repo) serveFile(w http.ResponseWriter, req *http.Request, lfs bool) {
+	c := r.resolve(req.URL.Query().Get("ref"))
+	if c == nil {
+		writeNotFound(w)
+		return
+	}
+	p := strings.Trim(req.PathValue("path"), "/")
+	content, ok := c.files[p]
+	if !ok || r.unreadable[p] {
+		writeNotFound(w)
+		return
+	}
+	if lfs {
+		for _, line := range strings.Split(content, "\n") {
+			if oid, ok := strings.CutPrefix(line, "oid sha256:"); ok && strings.HasPrefix(content, "version https://git-lfs.github.com/spec/v1\n") {
+				content = r.lfs[oid]
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	_, _ = w.Write([]byte(content))
+}
+
+// resolveTree returns the commit and directory of the tree with the given SHA,
+// or nil.
+func (r *Repo) resolveTree(sha string) (*commit, string) {
+	for _, c := range r.commits {
+		for p := range treePaths(c, "") {
+			if _, isFile := c.files[p]; !isFile && r.apiContents(c, p, false).SHA == sha {
+				return c, p
+			}
+		}
+	}
+	return nil, ""
+}
+
+// treePaths returns the paths of the files and directories below dir.
+func treePaths(c *commit, dir string) map[string]bool {
+	paths := map[string]bool{}
+	for p := range c.files {
+		if dir != "" && !strings.HasPrefix(p, dir+"/") {
+			continue
+		}
+		for d := path.Dir(p); d != "." && d != dir; d = path.Dir(d) {
+			paths[d] = true
+		}
+		paths[p] = true
+	}
+	return paths
+}
+
+// apiTree lists the tree of directory dir at commit c, only its top level
+// unless the recursive query parameter is set. Paths are relative to dir. Like
+// Gitea it pages by per_page, defaulting to 1000 entries, and reports whether
+// entries were left out.
+func (r *Repo) apiTree(c *commit, dir string, req *http.Request) *gitea.GitTreeResponse {
+	recursive := req.URL.Query().Get("recursive") != ""
+	prefix := ""
+	if dir != "" {
+		prefix = dir + "/"
+	}
+	entries := []gitea.GitEntry{}
+	for _, p := range sortedKeys(treePaths(c, dir)) {
+		rel := strings.TrimPrefix(p, prefix)
+		if !recursive && strings.Contains(rel, "/") {
+			continue
+		}
+		cr := r.apiContents(c, p, false)
+		entry := gitea.GitEntry{Path: rel, Mode: "040000", Type: "tree", SHA: cr.SHA}
+		if cr.Type == "file" {
+			entry.Mode, entry.Type, entry.Size = "100644", "blob", cr.Size
+		}
+		entry.URL = fmt.Sprintf("%s/api/v1/repos/%s/git/%ss/%s", r.server.URL, r.FullName, entry.Type, entry.SHA)
+		entries = append(entries, entry)
+	}
+
+	page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	perPage, _ := strconv.Atoi(req.URL.Query().Get("per_page"))
+	if perPage < 1 {
+		perPage = 1000
+	}
+	start := min((page-1)*perPage, len(entries))
+	end := min(start+perPage, len(entries))
+	sha := c.sha
+	if dir != "" {
+		sha = r.apiContents(c, dir, false).SHA
+	}
+	return &gitea.GitTreeResponse{
+		SHA:        sha,
+		URL:        fmt.Sprintf("%s/api/v1/repos/%s/git/trees/%s", r.server.URL, r.FullName, sha),
+		Entries:    entries[start:end],
+		Truncated:  end < len(entries),
+		Page:       page,
+		TotalCount: len(entries),
+	}
+}
+
+// dirEntries lists the files and directories directly inside dir.
+func (r *Repo) dirEntries(c *commit, dir string) []*gitea.ContentsResponse {
+	prefix := dir
+	if prefix != "" {
+		prefix += "/"
+	}
+	seen := map[string]bool{}
+	for p := range c.files {
+		rest, ok := strings.CutPrefix(p, prefix)
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(rest, "/")
+		seen[prefix+name] = true
+	}
+	names := sortedKeys(seen)
+	entries := make([]*gitea.ContentsResponse, 0, len(names))
+	for _, p := range names {
+		entries = append(entries, r.apiContents(c, p, false))
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		// Directories first, like Gitea.
+		return entries[i].Type == "dir" && entries[j].Type != "dir"
+	})
+	return entries
+}
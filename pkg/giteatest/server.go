@@ -0,0 +1,276 @@
+// Package giteatest provides an in-memory fake of the Gitea API for tests.
+//
+// The fake implements the endpoints used by the tools in operation/* closely
+// enough for the SDK to work against it: responses use the SDK types, errors
+// carry a "message" like Gitea's do and lists honour page and limit. State is
+// seeded through the Add* methods and inspected afterwards to assert what a
+// tool changed.
+package giteatest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// Version is the Gitea version reported by default.
+const Version = "1.22.0"
+
+// Time is the timestamp of everything the fake creates, so that results are
+// reproducible.
+var Time = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// Server is a fake Gitea server.
+type Server struct {
+	*httptest.Server
+
+	// Token, if set, is the only token accepted. Requests with another or
+	// without one are answered with 401.
+	Token string
+	// Version is the version reported by /api/v1/version.
+	Version string
+
+	mux *http.ServeMux
+
+	mu     sync.Mutex
+	nextID int64
+	user   *gitea.User
+	users  map[string]*gitea.User
+	orgs   map[string]*gitea.Organization
+	teams  map[string][]*gitea.Team
+	repos  map[string]*Repo
+
+	// members and teamRepos are the logins and repositories of each team, by
+	// team ID.
+	members   map[int64][]string
+	teamRepos map[int64][]*Repo
+	// public and orgLabels are the logins of the public members and the
+	// labels of each organization, by name.
+	public    map[string][]string
+	orgLabels map[string][]*gitea.Label
+}
+
+// NewServer starts a fake Gitea server authenticated as the user "test" and
+// closes it when the test ends.
+func NewServer(t testing.TB) *Server {
+	s := &Server{
+		Version:   Version,
+		mux:       http.NewServeMux(),
+		users:     make(map[string]*gitea.User),
+		orgs:      make(map[string]*gitea.Organization),
+		teams:     make(map[string][]*gitea.Team),
+		repos:     make(map[string]*Repo),
+		members:   make(map[int64][]string),
+		teamRepos: make(map[int64][]*Repo),
+		public:    make(map[string][]string),
+		orgLabels: make(map[string][]*gitea.Label),
+	}
+	s.user = s.AddUser("test")
+	s.routes()
+	s.Server = httptest.NewServer(s)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/api/v1/version" && s.Token != "" && r.Header.Get("Authorization") != "token "+s.Token {
+		writeError(w, http.StatusUnauthorized, "invalid username, password or token")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// User returns the authenticated user.
+func (s *Server) User() *gitea.User {
+	return s.user
+}
+
+// AddUser registers a user.
+func (s *Server) AddUser(login string) *gitea.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := &gitea.User{
+		ID:       s.id(),
+		UserName: login,
+		FullName: login,
+		Email:    login + "@example.com",
+		IsActive: true,
+		Created:  Time,
+	}
+	s.users[login] = u
+	return u
+}
+
+// AddOrg registers an organization the authenticated user is a member of.
+func (s *Server) AddOrg(name string) *gitea.Organization {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addOrg(gitea.CreateOrgOption{Name: name})
+}
+
+func (s *Server) addOrg(opt gitea.CreateOrgOption) *gitea.Organization {
+	fullName := opt.FullName
+	if fullName == "" {
+		fullName = opt.Name
+	}
+	visibility := string(opt.Visibility)
+	if visibility == "" {
+		visibility = string(gitea.VisibleTypePublic)
+	}
+	org := &gitea.Organization{
+		ID:          s.id(),
+		UserName:    opt.Name,
+		FullName:    fullName,
+		Description: opt.Description,
+		Website:     opt.Website,
+		Location:    opt.Location,
+		Visibility:  visibility,
+	}
+	s.orgs[opt.Name] = org
+	return org
+}
+
+// AddTeam adds a team to an organization registered with AddOrg.
+func (s *Server) AddTeam(org, name, description string) *gitea.Team {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	team := &gitea.Team{
+		ID:           s.id(),
+		Name:         name,
+		Description:  description,
+		Organization: s.orgs[org],
+		Permission:   gitea.AccessModeRead,
+	}
+	s.teams[org] = append(s.teams[org], team)
+	return team
+}
+
+// owner returns the user or organization named login as a repository owner.
+func (s *Server) owner(login string) *gitea.User {
+	if u, ok := s.users[login]; ok {
+		return u
+	}
+	if org, ok := s.orgs[login]; ok {
+		return &gitea.User{ID: org.ID, UserName: org.UserName, FullName: org.FullName}
+	}
+	return nil
+}
+
+func (s *Server) id() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+// handle registers h under /api/v1 with the server locked while it runs.
+func (s *Server) handle(pattern string, h http.HandlerFunc) {
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok {
+		panic("giteatest: pattern without method: " + pattern)
+	}
+	s.mux.HandleFunc(method+" /api/v1"+path, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		h(w, r)
+	})
+}
+
+// handleRepo registers h for a path below /repos/{owner}/{repo}, answering 404
+// if the repository does not exist.
+func (s *Server) handleRepo(pattern string, h func(http.ResponseWriter, *http.Request, *Repo)) {
+	method, path, _ := strings.Cut(pattern, " ")
+	s.handle(method+" /repos/{owner}/{repo}"+path, func(w http.ResponseWriter, r *http.Request) {
+		repo, ok := s.repos[r.PathValue("owner")+"/"+r.PathValue("repo")]
+		if !ok {
+			writeNotFound(w)
+			return
+		}
+		h(w, r, repo)
+	})
+}
+
+func (s *Server) routes() {
+	s.handle("GET /version", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"version": s.Version})
+	})
+	s.userRoutes()
+	s.repoRoutes()
+	s.issueRoutes()
+	s.statusRoutes()
+	s.protectionRoutes()
+	s.topicRoutes()
+	s.teamRoutes()
+	s.collaboratorRoutes()
+	s.orgRoutes()
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, format string, args ...any) {
+	writeJSON(w, status, map[string]any{
+		"message": fmt.Sprintf(format, args...),
+		"url":     "https://gitea.example.com/api/swagger",
+	})
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	writeError(w, http.StatusNotFound, "The target couldn't be found.")
+}
+
+// decode reads the JSON body of r into v, answering 422 if it is malformed.
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "invalid request body: %v", err)
+		return false
+	}
+	return true
+}
+
+// pathID parses the numeric path value name, answering 404 if it is not one.
+func pathID(w http.ResponseWriter, r *http.Request, name string) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue(name), 10, 64)
+	if err != nil {
+		writeNotFound(w)
+		return 0, false
+	}
+	return id, true
+}
+
+// paginate returns the page of list selected by the page and limit query
+// parameters, which default to 1 and 30 like Gitea's.
+func paginate[T any](r *http.Request, list []T) []T {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit < 1 {
+		limit = 30
+	}
+	start := (page - 1) * limit
+	if start >= len(list) {
+		return []T{}
+	}
+	return list[start:min(start+limit, len(list))]
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,109 @@
+package giteatest
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// statePriority orders commit states from worst to best, the combined state
+// of a commit being the worst of its latest statuses.
+var statePriority = []gitea.StatusState{
+	gitea.StatusError,
+	gitea.StatusFailure,
+	gitea.StatusWarning,
+	gitea.StatusPending,
+	gitea.StatusSuccess,
+}
+
+// AddStatus reports a commit status for the commit ref points to.
+func (r *Repo) AddStatus(ref string, state gitea.StatusState, context string) *gitea.Status {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+	return r.addStatus(r.resolve(ref), gitea.CreateStatusOption{State: state, Context: context})
+}
+
+// Statuses returns the statuses of commit sha, newest first.
+func (r *Repo) Statuses(sha string) []*gitea.Status {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+	return append([]*gitea.Status(nil), r.statuses[sha]...)
+}
+
+func (r *Repo) addStatus(c *commit, opt gitea.CreateStatusOption) *gitea.Status {
+	id := r.server.id()
+	status := &gitea.Status{
+		ID:          id,
+		State:       opt.State,
+		TargetURL:   opt.TargetURL,
+		Description: opt.Description,
+		URL:         fmt.Sprintf("%s/api/v1/repos/%s/statuses/%s", r.server.URL, r.FullName, c.sha),
+		Context:     opt.Context,
+		Creator:     r.server.user,
+		Created:     Time,
+		Updated:     Time,
+	}
+	// Newest first, like Gitea lists them.
+	r.statuses[c.sha] = append([]*gitea.Status{status}, r.statuses[c.sha]...)
+	return status
+}
+
+func (s *Server) statusRoutes() {
+	s.handleRepo("POST /statuses/{sha}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		var opt gitea.CreateStatusOption
+		if !decode(w, r, &opt) {
+			return
+		}
+		if !slices.Contains(statePriority, opt.State) {
+			writeError(w, http.StatusUnprocessableEntity, "[State]: In")
+			return
+		}
+		c := repo.commits[r.PathValue("sha")]
+		if c == nil {
+			writeNotFound(w)
+			return
+		}
+		writeJSON(w, http.StatusCreated, repo.addStatus(c, opt))
+	})
+
+	s.handleRepo("GET /commits/{ref}/statuses", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		c := repo.resolve(r.PathValue("ref"))
+		if c == nil {
+			writeNotFound(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, paginate(r, append([]*gitea.Status{}, repo.statuses[c.sha]...)))
+	})
+
+	s.handleRepo("GET /commits/{ref}/status", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		c := repo.resolve(r.PathValue("ref"))
+		if c == nil {
+			writeNotFound(w)
+			return
+		}
+		combined := &gitea.CombinedStatus{
+			SHA:        c.sha,
+			Statuses:   []*gitea.Status{},
+			Repository: repo.Repository,
+			CommitURL:  fmt.Sprintf("%s/api/v1/repos/%s/commits/%s", s.URL, repo.FullName, c.sha),
+			URL:        fmt.Sprintf("%s/api/v1/repos/%s/commits/%s/status", s.URL, repo.FullName, c.sha),
+		}
+		seen := make(map[string]bool)
+		worst := len(statePriority)
+		for _, status := range repo.statuses[c.sha] {
+			if seen[status.Context] {
+				continue
+			}
+			seen[status.Context] = true
+			combined.Statuses = append(combined.Statuses, status)
+			worst = min(worst, slices.Index(statePriority, status.State))
+		}
+		if worst < len(statePriority) {
+			combined.State = statePriority[worst]
+		}
+		combined.TotalCount = len(combined.Statuses)
+		writeJSON(w, http.StatusOK, combined)
+	})
+}
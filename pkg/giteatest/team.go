@@ -0,0 +1,164 @@
+package giteatest
+
+import (
+	"net/http"
+	"slices"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// AddTeamMember adds the user login to team id.
+func (s *Server) AddTeamMember(id int64, login string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addTeamMember(id, login)
+}
+
+// TeamMembers returns the logins of the members of team id, sorted.
+func (s *Server) TeamMembers(id int64) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.members[id])
+}
+
+// AddTeamRepo gives team id access to repo.
+func (s *Server) AddTeamRepo(id int64, repo *Repo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addTeamRepo(id, repo)
+}
+
+// TeamRepos returns the full names of the repositories of team id.
+func (s *Server) TeamRepos(id int64) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := []string{}
+	for _, repo := range s.teamRepoList(id) {
+		names = append(names, repo.FullName)
+	}
+	return names
+}
+
+// team returns the team with the given ID and the name of its organization.
+func (s *Server) team(id int64) (*gitea.Team, string) {
+	for org, teams := range s.teams {
+		for _, team := range teams {
+			if team.ID == id {
+				return team, org
+			}
+		}
+	}
+	return nil, ""
+}
+
+func (s *Server) addTeamMember(id int64, login string) {
+	if !slices.Contains(s.members[id], login) {
+		s.members[id] = append(s.members[id], login)
+		slices.Sort(s.members[id])
+	}
+}
+
+func (s *Server) addTeamRepo(id int64, repo *Repo) {
+	if !slices.Contains(s.teamRepos[id], repo) {
+		s.teamRepos[id] = append(s.teamRepos[id], repo)
+	}
+}
+
+// teamRepoList returns the repositories of team id that still exist, in the
+// order they were added.
+func (s *Server) teamRepoList(id int64) []*Repo {
+	repos := []*Repo{}
+	for _, repo := range s.teamRepos[id] {
+		if s.repos[repo.FullName] == repo {
+			repos = append(repos, repo)
+		}
+	}
+	return repos
+}
+
+func (s *Server) teamRoutes() {
+	s.handle("GET /orgs/{org}/teams", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := s.orgs[r.PathValue("org")]; !ok {
+			writeNotFound(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, paginate(r, append([]*gitea.Team{}, s.teams[r.PathValue("org")]...)))
+	})
+
+	// handleTeam registers h for a path below /teams/{id}, answering 404
+	// for teams that do not exist.
+	handleTeam := func(pattern string, h func(http.ResponseWriter, *http.Request, *gitea.Team, string)) {
+		s.handle(pattern, func(w http.ResponseWriter, r *http.Request) {
+			id, ok := pathID(w, r, "id")
+			if !ok {
+				return
+			}
+			team, org := s.team(id)
+			if team == nil {
+				writeNotFound(w)
+				return
+			}
+			h(w, r, team, org)
+		})
+	}
+
+	handleTeam("GET /teams/{id}/members", func(w http.ResponseWriter, r *http.Request, team *gitea.Team, _ string) {
+		users := []*gitea.User{}
+		for _, login := range s.members[team.ID] {
+			users = append(users, s.users[login])
+		}
+		writeJSON(w, http.StatusOK, paginate(r, users))
+	})
+
+	handleTeam("PUT /teams/{id}/members/{username}", func(w http.ResponseWriter, r *http.Request, team *gitea.Team, _ string) {
+		if _, ok := s.users[r.PathValue("username")]; !ok {
+			writeNotFound(w)
+			return
+		}
+		s.addTeamMember(team.ID, r.PathValue("username"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	handleTeam("DELETE /teams/{id}/members/{username}", func(w http.ResponseWriter, r *http.Request, team *gitea.Team, _ string) {
+		if _, ok := s.users[r.PathValue("username")]; !ok {
+			writeNotFound(w)
+			return
+		}
+		s.members[team.ID] = slices.DeleteFunc(s.members[team.ID], func(login string) bool {
+			return login == r.PathValue("username")
+		})
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	handleTeam("GET /teams/{id}/repos", func(w http.ResponseWriter, r *http.Request, team *gitea.Team, _ string) {
+		repos := []*gitea.Repository{}
+		for _, repo := range s.teamRepoList(team.ID) {
+			repos = append(repos, repo.Repository)
+		}
+		writeJSON(w, http.StatusOK, paginate(r, repos))
+	})
+
+	handleTeam("PUT /teams/{id}/repos/{org}/{repo}", func(w http.ResponseWriter, r *http.Request, team *gitea.Team, org string) {
+		repo, ok := s.repos[r.PathValue("org")+"/"+r.PathValue("repo")]
+		if !ok {
+			writeNotFound(w)
+			return
+		}
+		if repo.Owner.UserName != org {
+			writeError(w, http.StatusForbidden, "repository does not belong to the team's organization")
+			return
+		}
+		s.addTeamRepo(team.ID, repo)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	handleTeam("DELETE /teams/{id}/repos/{org}/{repo}", func(w http.ResponseWriter, r *http.Request, team *gitea.Team, _ string) {
+		repo, ok := s.repos[r.PathValue("org")+"/"+r.PathValue("repo")]
+		if !ok {
+			writeNotFound(w)
+			return
+		}
+		s.teamRepos[team.ID] = slices.DeleteFunc(s.teamRepos[team.ID], func(r *Repo) bool { return r == repo })
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,95 @@
+package giteatest
+
+import (
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// maxTopics is the number of topics a repository can have at most.
+const maxTopics = 25
+
+var topicPattern = regexp.MustCompile(`^[a-z0-9][-.a-z0-9]{0,34}$`)
+
+// SetTopics replaces the topics of the repository.
+func (r *Repo) SetTopics(topics ...string) {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+	r.topics = slices.Sorted(slices.Values(topics))
+}
+
+// Topics returns the topics of the repository, sorted.
+func (r *Repo) Topics() []string {
+	r.server.mu.Lock()
+	defer r.server.mu.Unlock()
+	return slices.Clone(r.topics)
+}
+
+func (s *Server) topicRoutes() {
+	s.handleRepo("GET /topics", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		writeJSON(w, http.StatusOK, map[string][]string{"topics": paginate(r, nonNil(repo.topics))})
+	})
+
+	s.handleRepo("PUT /topics", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		var opt struct {
+			Topics []string `json:"topics"`
+		}
+		if !decode(w, r, &opt) {
+			return
+		}
+		topics := []string{}
+		invalid := []string{}
+		for _, topic := range opt.Topics {
+			topic = strings.ToLower(strings.TrimSpace(topic))
+			switch {
+			case topic == "" || slices.Contains(topics, topic):
+			case !topicPattern.MatchString(topic):
+				invalid = append(invalid, topic)
+			default:
+				topics = append(topics, topic)
+			}
+		}
+		if len(invalid) > 0 {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"invalidTopics": invalid, "message": "Topic names are invalid"})
+			return
+		}
+		if len(topics) > maxTopics {
+			writeError(w, http.StatusUnprocessableEntity, "Exceeding maximum number of topics per repo")
+			return
+		}
+		slices.Sort(topics)
+		repo.topics = topics
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	s.handleRepo("PUT /topics/{topic}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		topic := strings.ToLower(strings.TrimSpace(r.PathValue("topic")))
+		if !topicPattern.MatchString(topic) {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]any{"invalidTopics": []string{topic}, "message": "Topic name is invalid"})
+			return
+		}
+		if slices.Contains(repo.topics, topic) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if len(repo.topics) >= maxTopics {
+			writeError(w, http.StatusUnprocessableEntity, "Exceeding maximum allowed topics per repo.")
+			return
+		}
+		repo.topics = append(repo.topics, topic)
+		slices.Sort(repo.topics)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	s.handleRepo("DELETE /topics/{topic}", func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		topic := strings.ToLower(strings.TrimSpace(r.PathValue("topic")))
+		i := slices.Index(repo.topics, topic)
+		if i < 0 {
+			writeNotFound(w)
+			return
+		}
+		repo.topics = slices.Delete(repo.topics, i, i+1)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,50 @@
+package giteatest
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+func (s *Server) userRoutes() {
+	s.handle("GET /user", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.user)
+	})
+
+	s.handle("GET /user/orgs", func(w http.ResponseWriter, r *http.Request) {
+		orgs := make([]*gitea.Organization, 0, len(s.orgs))
+		for _, name := range sortedKeys(s.orgs) {
+			orgs = append(orgs, s.orgs[name])
+		}
+		writeJSON(w, http.StatusOK, paginate(r, orgs))
+	})
+
+	s.handle("GET /users/search", func(w http.ResponseWriter, r *http.Request) {
+		q := strings.ToLower(r.URL.Query().Get("q"))
+		users := []*gitea.User{}
+		for _, login := range sortedKeys(s.users) {
+			if strings.Contains(strings.ToLower(login), q) {
+				users = append(users, s.users[login])
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "data": paginate(r, users)})
+	})
+
+	s.handle("GET /orgs/{org}/teams/search", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := s.orgs[r.PathValue("org")]; !ok {
+			writeNotFound(w)
+			return
+		}
+		q := strings.ToLower(r.URL.Query().Get("q"))
+		inDesc := r.URL.Query().Get("include_desc") == "true"
+		teams := []*gitea.Team{}
+		for _, team := range s.teams[r.PathValue("org")] {
+			if strings.Contains(strings.ToLower(team.Name), q) ||
+				inDesc && strings.Contains(strings.ToLower(team.Description), q) {
+				teams = append(teams, team)
+			}
+		}
+		writeJSON(w, http.StatusOK, gitea.TeamSearchResults{OK: true, Data: paginate(r, teams)})
+	})
+}
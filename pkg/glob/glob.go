@@ -0,0 +1,51 @@
+// Package glob matches repository paths against the globs tools accept to
+// filter files.
+package glob
+
+import (
+	"path"
+	"strings"
+)
+
+// Match reports whether the slash-separated path p matches pattern. "**"
+// matches any number of directories, and a pattern without a slash matches
+// the file name so that "*.go" finds Go files at any depth.
+func Match(pattern, p string) bool {
+	pattern = strings.Trim(pattern, "/")
+	if !strings.Contains(pattern, "/") && pattern != "**" {
+		ok, _ := path.Match(pattern, path.Base(p))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(p, "/"))
+}
+
+// MatchAny reports whether p matches one of patterns.
+func MatchAny(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		if Match(pattern, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := len(parts); i >= 0; i-- {
+				if matchSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
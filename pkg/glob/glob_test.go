@@ -0,0 +1,41 @@
+package glob
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/tools/main.go", true},
+		{"*.go", "main.go.orig", false},
+		{"README*", "docs/README.md", true},
+		{"docs/*.md", "docs/guide.md", true},
+		{"docs/*.md", "docs/api/guide.md", false},
+		{"docs/**", "docs/api/guide.md", true},
+		{"docs/**", "docs", true},
+		{"/docs/**/", "docs/guide.md", true},
+		{"**/*_test.go", "pkg/glob/glob_test.go", true},
+		{"**/*_test.go", "glob_test.go", true},
+		{"pkg/**/glob.go", "pkg/glob/glob.go", true},
+		{"pkg/**/glob.go", "cmd/glob/glob.go", false},
+		{"**", "any/thing", true},
+		{"src/[a-f]*.go", "src/feature.go", true},
+		{"src/[a-f]*.go", "src/main.go", false},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestMatchAny(t *testing.T) {
+	if MatchAny(nil, "main.go") {
+		t.Error("no patterns matched main.go")
+	}
+	if !MatchAny([]string{"*.md", "*.go"}, "cmd/main.go") {
+		t.Error("*.go did not match cmd/main.go")
+	}
+}
@@ -30,12 +30,14 @@ func Default() *zap.Logger {
 		var ws zapcore.WriteSyncer
 		var wss []zapcore.WriteSyncer
 
-		home, _ := os.UserHomeDir()
-		if home == "" {
-			home = os.TempDir()
+		logDir := flag.LogDir
+		if logDir == "" {
+			home, _ := os.UserHomeDir()
+			if home == "" {
+				home = os.TempDir()
+			}
+			logDir = fmt.Sprintf("%s/.gitea-mcp", home)
 		}
-
-		logDir := fmt.Sprintf("%s/.gitea-mcp", home)
 		if err := os.MkdirAll(logDir, 0o700); err != nil {
 			// Fallback to temp directory if creation fails
 			logDir = os.TempDir()
@@ -0,0 +1,26 @@
+// Package params reads arguments shared by several tools.
+package params
+
+import (
+	gitea_sdk "code.gitea.io/sdk/gitea"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// defaultPageSize is the page size of a list tool called without page_size.
+const defaultPageSize = 50
+
+// ListOptions returns the page and page_size arguments of a list tool.
+func ListOptions(req mcp.CallToolRequest) gitea_sdk.ListOptions {
+	page, ok := req.GetArguments()["page"].(float64)
+	if !ok {
+		page = 1
+	}
+	pageSize, ok := req.GetArguments()["page_size"].(float64)
+	if !ok {
+		pageSize = defaultPageSize
+	}
+	return gitea_sdk.ListOptions{
+		Page:     int(page),
+		PageSize: int(pageSize),
+	}
+}
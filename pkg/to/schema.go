@@ -0,0 +1,120 @@
+package to
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// OutputSchema declares that a tool returns a T, which is what it passes to
+// Result. The schema is derived from the JSON encoding of T.
+func OutputSchema[T any]() mcp.ToolOption {
+	r := jsonschema.Reflector{
+		Anonymous:                 true,
+		AllowAdditionalProperties: true,
+		// The Gitea types refer to each other, so they are kept in $defs
+		// rather than inlined.
+		ExpandedStruct: true,
+	}
+	schema := r.Reflect(Output[T]{})
+	schema.Version = ""
+	data, err := json.Marshal(schema)
+	if err != nil {
+		panic(fmt.Sprintf("marshal output schema err: %v", err))
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		panic(fmt.Sprintf("unmarshal output schema err: %v", err))
+	}
+
+	// Go encodes nil pointers, slices and maps as null even without
+	// omitempty, and clients validate structured content against the schema.
+	root := reflect.TypeOf(Output[T]{})
+	nullable := map[string]map[string]bool{}
+	collectNullable(root, nullable)
+	allowNull(m, nullable[root.Name()])
+	defs, _ := m["$defs"].(map[string]any)
+	for name, def := range defs {
+		if def, ok := def.(map[string]any); ok {
+			allowNull(def, nullable[name])
+		}
+	}
+
+	data, err = json.Marshal(m)
+	if err != nil {
+		panic(fmt.Sprintf("marshal output schema err: %v", err))
+	}
+	return mcp.WithRawOutputSchema(data)
+}
+
+// collectNullable records, by type name, the JSON names of the fields that
+// may be null for every struct reachable from t.
+func collectNullable(t reflect.Type, nullable map[string]map[string]bool) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	if _, ok := nullable[t.Name()]; ok {
+		return
+	}
+	fields := map[string]bool{}
+	nullable[t.Name()] = fields
+	collectFields(t, fields, nullable)
+}
+
+func collectFields(t reflect.Type, fields map[string]bool, nullable map[string]map[string]bool) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			// Embedded structs are flattened into the outer object.
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				collectFields(ft, fields, nullable)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		switch f.Type.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+			fields[name] = true
+		}
+		collectNullable(f.Type, nullable)
+	}
+}
+
+// allowNull lets the named properties of schema be null.
+func allowNull(schema map[string]any, names map[string]bool) {
+	props, _ := schema["properties"].(map[string]any)
+	for name, prop := range props {
+		prop, ok := prop.(map[string]any)
+		if !ok || !names[name] {
+			continue
+		}
+		switch t := prop["type"].(type) {
+		case string:
+			prop["type"] = []any{t, "null"}
+		case nil:
+			if _, ok := prop["$ref"]; ok {
+				props[name] = map[string]any{"anyOf": []any{prop, map[string]any{"type": "null"}}}
+			}
+		}
+	}
+}
@@ -1,25 +1,95 @@
 package to
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 
 	"gitea.com/gitea/gitea-mcp/pkg/log"
+
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
-type textResult struct {
-	Result any
+// Output is the structured content of a tool result. MCP requires an object,
+// so the value is wrapped.
+type Output[T any] struct {
+	Result T `json:"result"`
 }
 
-func TextResult(v any) (*mcp.CallToolResult, error) {
-	result := textResult{v}
-	resultBytes, err := json.Marshal(result)
+// Result returns v as structured content. The text content is a short
+// summary for clients without structured output support: v itself if it is a
+// message, otherwise the number of results and what identifies each of them.
+func Result(v any) (*mcp.CallToolResult, error) {
+	if msg, ok := v.(string); ok {
+		log.Debugf("Result: %s", msg)
+		return mcp.NewToolResultStructured(Output[any]{v}, msg), nil
+	}
+	resultBytes, err := json.Marshal(v)
 	if err != nil {
 		return nil, fmt.Errorf("marshal result err: %v", err)
 	}
-	log.Debugf("Text Result: %s", string(resultBytes))
-	return mcp.NewToolResultText(string(resultBytes)), nil
+	log.Debugf("Result: %s", string(resultBytes))
+	return mcp.NewToolResultStructured(Output[any]{v}, summary(resultBytes)), nil
+}
+
+// summaryFields are the fields naming a result, tried in order.
+var summaryFields = []string{"login", "full_name", "name", "tag_name", "filename", "path", "sha"}
+
+// summary describes the JSON encoded result data in a few lines.
+func summary(data []byte) string {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return string(data)
+	}
+	switch v := v.(type) {
+	case []any:
+		var b strings.Builder
+		fmt.Fprintf(&b, "%d results", len(v))
+		for i, item := range v {
+			fmt.Fprintf(&b, "\n- %s", label(item, i+1))
+		}
+		return b.String()
+	case map[string]any:
+		if l := label(v, 0); l != "" {
+			return l
+		}
+		fields := slices.Sorted(maps.Keys(v))
+		return "Result with " + strings.Join(fields, ", ")
+	case nil:
+		return "No result"
+	}
+	return string(data)
+}
+
+// label identifies a result by its number, name and title. Results without
+// any of them are labeled by their position n, or not at all if n is 0.
+func label(v any, n int) string {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return fmt.Sprint(v)
+	}
+	var parts []string
+	if number, ok := m["number"].(json.Number); ok {
+		parts = append(parts, "#"+number.String())
+	}
+	for _, field := range summaryFields {
+		if name, ok := m[field].(string); ok && name != "" {
+			parts = append(parts, name)
+			break
+		}
+	}
+	if title, ok := m["title"].(string); ok && title != "" {
+		parts = append(parts, title)
+	}
+	if len(parts) == 0 && n > 0 {
+		return fmt.Sprintf("result %d", n)
+	}
+	return strings.Join(parts, " ")
 }
 
 func ErrorResult(err error) (*mcp.CallToolResult, error) {
@@ -0,0 +1,138 @@
+package to
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"gitea.com/gitea/gitea-mcp/pkg/log"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	log.SetDefault(zap.NewNop())
+	os.Exit(m.Run())
+}
+
+func TestResult(t *testing.T) {
+	tests := []struct {
+		name       string
+		v          any
+		wantText   string
+		wantStruct string
+	}{
+		{"message", "Tag deleted", "Tag deleted", `{"result":"Tag deleted"}`},
+		{"nil", nil, "No result", `{"result":null}`},
+		{"empty list", []string{}, "0 results", `{"result":[]}`},
+		{"struct", struct {
+			ID   int64  `json:"id"`
+			Name string `json:"name"`
+		}{1, "main"}, "main", `{"result":{"id":1,"name":"main"}}`},
+		{"unnamed struct", struct {
+			Ahead  int `json:"ahead"`
+			Behind int `json:"behind"`
+		}{2, 0}, "Result with ahead, behind", `{"result":{"ahead":2,"behind":0}}`},
+		{"issues", []map[string]any{
+			{"number": 12, "title": "Crash on start", "body": "..."},
+			{"number": 1000000, "title": "Typo"},
+		}, "2 results\n- #12 Crash on start\n- #1000000 Typo", `{"result":[{"body":"...","number":12,"title":"Crash on start"},{"number":1000000,"title":"Typo"}]}`},
+		{"users", []map[string]any{
+			{"login": "alice", "full_name": "Alice"},
+			{"id": 2},
+		}, "2 results\n- alice\n- result 2", `{"result":[{"full_name":"Alice","login":"alice"},{"id":2}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Result(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.IsError || len(result.Content) != 1 {
+				t.Fatalf("unexpected result %+v", result)
+			}
+			text, ok := result.Content[0].(mcp.TextContent)
+			if !ok {
+				t.Fatalf("content is %T, want text", result.Content[0])
+			}
+			if text.Text != tt.wantText {
+				t.Errorf("got text %s, want %s", text.Text, tt.wantText)
+			}
+			structured, err := json.Marshal(result.StructuredContent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(structured) != tt.wantStruct {
+				t.Errorf("got structured content %s, want %s", structured, tt.wantStruct)
+			}
+		})
+	}
+}
+
+func TestResultUnmarshalable(t *testing.T) {
+	if _, err := Result(make(chan int)); err == nil {
+		t.Error("expected an error for a value JSON cannot encode")
+	}
+}
+
+type Meta struct {
+	Note *string `json:"note"`
+}
+
+type node struct {
+	Meta
+	Name     string            `json:"name"`
+	Parent   *node             `json:"parent"`
+	Children []*node           `json:"children,omitempty"`
+	Labels   map[string]string `json:"labels"`
+}
+
+func TestOutputSchema(t *testing.T) {
+	tool := mcp.NewTool("test", OutputSchema[[]*node]())
+	var schema map[string]any
+	if err := json.Unmarshal(tool.RawOutputSchema, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if schema["type"] != "object" {
+		t.Errorf("root type = %v, want object", schema["type"])
+	}
+	if _, ok := schema["$schema"]; ok {
+		t.Error("schema declares $schema")
+	}
+
+	want := map[string]string{
+		"properties.result":              `{"items":{"$ref":"#/$defs/node"},"type":["array","null"]}`,
+		"$defs.node.properties.name":     `{"type":"string"}`,
+		"$defs.node.properties.note":     `{"type":["string","null"]}`,
+		"$defs.node.properties.parent":   `{"anyOf":[{"$ref":"#/$defs/node"},{"type":"null"}]}`,
+		"$defs.node.properties.children": `{"items":{"$ref":"#/$defs/node"},"type":["array","null"]}`,
+		"$defs.node.properties.labels":   `{"additionalProperties":{"type":"string"},"type":["object","null"]}`,
+	}
+	for path, want := range want {
+		var v any = schema
+		for _, key := range strings.Split(path, ".") {
+			v = v.(map[string]any)[key]
+		}
+		got, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %s, want %s", path, got, want)
+		}
+	}
+}
+
+func TestErrorResult(t *testing.T) {
+	want := errors.New("get file err: not found")
+	result, err := ErrorResult(want)
+	if result != nil {
+		t.Errorf("result = %+v, want nil", result)
+	}
+	if err != want {
+		t.Errorf("err = %v, want %v", err, want)
+	}
+}
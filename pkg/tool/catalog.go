@@ -0,0 +1,70 @@
+package tool
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// CatalogEntry is the contract of a tool with its clients: the name and
+// arguments agents and configs refer to, and how the server classifies it.
+type CatalogEntry struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Access      string              `json:"access"`
+	Scope       string              `json:"scope,omitempty"`
+	MinVersion  string              `json:"min_version,omitempty"`
+	InputSchema mcp.ToolInputSchema `json:"inputSchema"`
+	// OutputSchema describes the structured content of the tool's results.
+	OutputSchema json.RawMessage `json:"outputSchema,omitempty"`
+	// DeprecatedAliases maps legacy argument names still accepted to their
+	// replacements.
+	DeprecatedAliases map[string]string `json:"deprecated_aliases,omitempty"`
+}
+
+// Catalog returns every registered tool sorted by name, independent of
+// read-only mode, token scopes and configured instances.
+func Catalog() []CatalogEntry {
+	infos := Registered()
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	catalog := make([]CatalogEntry, 0, len(infos))
+	for _, info := range infos {
+		def := definitions[info.Name]
+		access := "read"
+		if info.Write {
+			access = "write"
+		}
+		catalog = append(catalog, CatalogEntry{
+			Name:              info.Name,
+			Description:       def.Description,
+			Access:            access,
+			Scope:             info.RequiredScope(),
+			MinVersion:        info.MinVersion,
+			InputSchema:       def.InputSchema,
+			OutputSchema:      def.RawOutputSchema,
+			DeprecatedAliases: info.Aliases,
+		})
+	}
+	return catalog
+}
+
+// MarkdownTable renders the catalog as the tool table of the README.
+func MarkdownTable(catalog []CatalogEntry) string {
+	var b strings.Builder
+	b.WriteString("| Tool | Access | Token scope | Gitea | Description |\n")
+	b.WriteString("| ---- | ------ | ----------- | ----- | ----------- |\n")
+	for _, e := range catalog {
+		scope, minVersion := "-", "-"
+		if e.Scope != "" {
+			scope = "`" + e.Scope + "`"
+		}
+		if e.MinVersion != "" {
+			minVersion = ">= " + e.MinVersion
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s |\n", e.Name, e.Access, scope, minVersion, strings.ReplaceAll(e.Description, "|", "\\|"))
+	}
+	return b.String()
+}
@@ -1,37 +1,286 @@
 package tool
 
 import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+
 	"gitea.com/gitea/gitea-mcp/pkg/flag"
+	"gitea.com/gitea/gitea-mcp/pkg/gitea"
+	"gitea.com/gitea/gitea-mcp/pkg/log"
+	"gitea.com/gitea/gitea-mcp/pkg/to"
+
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
+// InstanceArg is the optional argument selecting the Gitea instance a tool
+// call is executed against.
+const InstanceArg = "instance"
+
+// Info describes a registered tool.
+type Info struct {
+	Name  string
+	Write bool
+	// Scope is the token scope category the tool needs, read or write access
+	// depending on Write. Empty if the tool does not call Gitea.
+	Scope string
+	// MinVersion is the oldest Gitea version providing the endpoints the tool
+	// uses. Empty if any version does.
+	MinVersion string
+	// Aliases maps deprecated argument names to the names that replaced them.
+	Aliases map[string]string
+}
+
+// RequiredScope returns the token scope the tool needs, such as
+// "write:repository".
+func (i Info) RequiredScope() string {
+	if i.Scope == "" {
+		return ""
+	}
+	if i.Write {
+		return "write:" + i.Scope
+	}
+	return "read:" + i.Scope
+}
+
+// Option configures the registration of a tool.
+type Option func(*Info)
+
+// Scope declares the token scope category a tool needs.
+func Scope(category string) Option {
+	return func(i *Info) {
+		i.Scope = category
+	}
+}
+
+// MinVersion declares the oldest Gitea version a tool works with, such as
+// "1.22".
+func MinVersion(v string) Option {
+	return func(i *Info) {
+		i.MinVersion = v
+	}
+}
+
+// Alias keeps accepting the argument legacy, which has been renamed to
+// canonical, for configs and prompts written before the rename. Its use is
+// logged as deprecated.
+func Alias(legacy, canonical string) Option {
+	return func(i *Info) {
+		if i.Aliases == nil {
+			i.Aliases = make(map[string]string)
+		}
+		i.Aliases[legacy] = canonical
+	}
+}
+
+var (
+	registryMu  sync.RWMutex
+	registry    = map[string]Info{}
+	definitions = map[string]mcp.Tool{}
+)
+
+// Lookup returns the registration of the named tool.
+func Lookup(name string) (Info, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	info, ok := registry[name]
+	return info, ok
+}
+
+// Registered returns every registered tool sorted by name.
+func Registered() []Info {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	list := make([]Info, 0, len(registry))
+	for _, info := range registry {
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
 type Tool struct {
 	write []server.ServerTool
 	read  []server.ServerTool
+	opts  []Option
 }
 
-func New() *Tool {
+// New returns a tool set. opts apply to every tool registered in it unless
+// overridden at registration.
+func New(opts ...Option) *Tool {
 	return &Tool{
 		write: make([]server.ServerTool, 0, 100),
 		read:  make([]server.ServerTool, 0, 100),
+		opts:  opts,
 	}
 }
 
-func (t *Tool) RegisterWrite(s server.ServerTool) {
+func (t *Tool) RegisterWrite(s server.ServerTool, opts ...Option) {
 	t.write = append(t.write, s)
+	t.register(s.Tool, true, opts)
 }
 
-func (t *Tool) RegisterRead(s server.ServerTool) {
+func (t *Tool) RegisterRead(s server.ServerTool, opts ...Option) {
 	t.read = append(t.read, s)
+	t.register(s.Tool, false, opts)
+}
+
+func (t *Tool) register(def mcp.Tool, write bool, opts []Option) {
+	info := Info{Name: def.Name, Write: write}
+	for _, opt := range t.opts {
+		opt(&info)
+	}
+	for _, opt := range opts {
+		opt(&info)
+	}
+	registryMu.Lock()
+	registry[def.Name] = info
+	definitions[def.Name] = def
+	registryMu.Unlock()
 }
 
 func (t *Tool) Tools() []server.ServerTool {
 	tools := make([]server.ServerTool, 0, len(t.write)+len(t.read))
 	if flag.ReadOnly {
-		tools = append(tools, t.read...)
+		tools = append(tools, wrap(t.read)...)
 		return tools
 	}
-	tools = append(tools, t.write...)
-	tools = append(tools, t.read...)
+	tools = append(tools, wrap(t.write)...)
+	tools = append(tools, wrap(t.read)...)
 	return tools
 }
+
+// Filter hides the tools the token of the primary instance lacks the scope
+// for and those its Gitea version does not support. It is meant for
+// server.WithToolFilter. With several instances every tool is listed, since
+// the instance is only known per call.
+func Filter(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+	if len(gitea.Instances()) > 1 {
+		return tools
+	}
+	caps, err := gitea.CapabilitiesFromContext(ctx)
+	if err != nil {
+		log.Warnf("Listing tools regardless of token scopes, %v", err)
+		caps = &gitea.Capabilities{}
+	}
+	filtered := make([]mcp.Tool, 0, len(tools))
+	for _, t := range tools {
+		info, ok := Lookup(t.Name)
+		if ok && !caps.Allows(info.Scope, info.Write) {
+			continue
+		}
+		if ok && info.MinVersion != "" && gitea.RequireVersion(ctx, info.MinVersion) != nil {
+			continue
+		}
+		filtered = append(filtered, t)
+	}
+	return filtered
+}
+
+// wrap binds every tool to the Gitea instance selected by its "instance"
+// argument. The argument is only advertised when more than one instance is
+// configured.
+func wrap(tools []server.ServerTool) []server.ServerTool {
+	multi := len(gitea.Instances()) > 1
+	wrapped := make([]server.ServerTool, 0, len(tools))
+	for _, s := range tools {
+		if multi {
+			s.Tool = withInstanceArg(s.Tool)
+		}
+		info, _ := Lookup(s.Tool.Name)
+		s.Handler = withAliases(withInstance(s.Handler, info), info)
+		wrapped = append(wrapped, s)
+	}
+	return wrapped
+}
+
+func withInstanceArg(t mcp.Tool) mcp.Tool {
+	props := maps.Clone(t.InputSchema.Properties)
+	if props == nil {
+		props = make(map[string]any, 1)
+	}
+	props[InstanceArg] = map[string]any{
+		"type":        "string",
+		"description": fmt.Sprintf("gitea instance name, defaults to %s", gitea.PrimaryInstance()),
+	}
+	t.InputSchema.Properties = props
+	return t
+}
+
+func withInstance(next server.ToolHandlerFunc, info Info) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		name, _ := req.GetArguments()[InstanceArg].(string)
+		inst, err := gitea.LookupInstance(name)
+		if err != nil {
+			return to.ErrorResult(err)
+		}
+		if info.Write && inst.ReadOnly {
+			return to.ErrorResult(fmt.Errorf("gitea instance %s is read-only", inst.Name))
+		}
+		ctx = gitea.WithInstance(ctx, inst.Name)
+		if _, err := gitea.TokenFromContext(ctx); err != nil {
+			return to.ErrorResult(err)
+		}
+		if info.MinVersion != "" {
+			if err := gitea.RequireVersion(ctx, info.MinVersion); err != nil {
+				return to.ErrorResult(fmt.Errorf("%s %v", info.Name, err))
+			}
+		}
+		if info.Scope != "" {
+			// Detection failures are ignored, Gitea still rejects the call if
+			// the scope is missing.
+			if caps, err := gitea.CapabilitiesFromContext(ctx); err == nil && !caps.Allows(info.Scope, info.Write) {
+				return to.ErrorResult(fmt.Errorf("the token for instance %s lacks the %s scope required by %s", inst.Name, info.RequiredScope(), info.Name))
+			}
+		}
+		result, err := next(ctx, req)
+		if info.Write && info.Scope != "" {
+			// Write access is only assumed until a write shows whether the
+			// token has it.
+			switch {
+			case gitea.IsMissingScope(err) && strings.Contains(err.Error(), info.RequiredScope()):
+				gitea.RecordWriteAccess(ctx, info.Scope, false)
+			case err == nil && (result == nil || !result.IsError):
+				gitea.RecordWriteAccess(ctx, info.Scope, true)
+			}
+		}
+		return result, err
+	}
+}
+
+// withAliases renames deprecated arguments before the handler sees them. An
+// argument given under both names keeps the value of the canonical one.
+func withAliases(next server.ToolHandlerFunc, info Info) server.ToolHandlerFunc {
+	if len(info.Aliases) == 0 {
+		return next
+	}
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := req.GetArguments()
+		renamed := false
+		for legacy, canonical := range info.Aliases {
+			v, ok := args[legacy]
+			if !ok {
+				continue
+			}
+			log.Warnf("Tool %s: argument %s is deprecated, use %s", info.Name, legacy, canonical)
+			if !renamed {
+				args = maps.Clone(args)
+				renamed = true
+			}
+			delete(args, legacy)
+			if _, ok := args[canonical]; !ok {
+				args[canonical] = v
+			}
+		}
+		if renamed {
+			req.Params.Arguments = args
+		}
+		return next(ctx, req)
+	}
+}
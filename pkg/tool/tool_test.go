@@ -0,0 +1,112 @@
+package tool
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+
+	"gitea.com/gitea/gitea-mcp/pkg/flag"
+	"gitea.com/gitea/gitea-mcp/pkg/gitea"
+	"gitea.com/gitea/gitea-mcp/pkg/log"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.uber.org/zap"
+)
+
+func noop(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return mcp.NewToolResultText("ok"), nil
+}
+
+func names(tools []server.ServerTool) []string {
+	list := make([]string, 0, len(tools))
+	for _, t := range tools {
+		list = append(list, t.Tool.Name)
+	}
+	return list
+}
+
+func TestToolsReadOnly(t *testing.T) {
+	set := New(Scope(gitea.ScopeRepository))
+	set.RegisterWrite(server.ServerTool{Tool: mcp.NewTool("test_create"), Handler: noop})
+	set.RegisterRead(server.ServerTool{Tool: mcp.NewTool("test_get"), Handler: noop})
+	set.RegisterRead(server.ServerTool{Tool: mcp.NewTool("test_list"), Handler: noop})
+
+	readOnly := flag.ReadOnly
+	t.Cleanup(func() { flag.ReadOnly = readOnly })
+
+	tests := []struct {
+		readOnly bool
+		want     []string
+	}{
+		{false, []string{"test_create", "test_get", "test_list"}},
+		{true, []string{"test_get", "test_list"}},
+	}
+	for _, tt := range tests {
+		flag.ReadOnly = tt.readOnly
+		if got := names(set.Tools()); !slices.Equal(got, tt.want) {
+			t.Errorf("read-only %v: got %v, want %v", tt.readOnly, got, tt.want)
+		}
+	}
+}
+
+func TestRegistration(t *testing.T) {
+	set := New(Scope(gitea.ScopeIssue))
+	set.RegisterWrite(server.ServerTool{Tool: mcp.NewTool("test_edit"), Handler: noop}, MinVersion("1.22"), Alias("editID", "edit_id"))
+	set.RegisterRead(server.ServerTool{Tool: mcp.NewTool("test_search"), Handler: noop}, Scope(gitea.ScopeUser))
+
+	tests := []struct {
+		name          string
+		want          Info
+		requiredScope string
+	}{
+		{"test_edit", Info{Name: "test_edit", Write: true, Scope: gitea.ScopeIssue, MinVersion: "1.22", Aliases: map[string]string{"editID": "edit_id"}}, "write:issue"},
+		{"test_search", Info{Name: "test_search", Scope: gitea.ScopeUser}, "read:user"},
+	}
+	for _, tt := range tests {
+		info, ok := Lookup(tt.name)
+		if !ok {
+			t.Fatalf("%s is not registered", tt.name)
+		}
+		if !reflect.DeepEqual(info, tt.want) {
+			t.Errorf("Lookup(%s) = %+v, want %+v", tt.name, info, tt.want)
+		}
+		if got := info.RequiredScope(); got != tt.requiredScope {
+			t.Errorf("%s.RequiredScope() = %q, want %q", tt.name, got, tt.requiredScope)
+		}
+	}
+	if got := (Info{Name: "local"}).RequiredScope(); got != "" {
+		t.Errorf("RequiredScope without a scope = %q, want empty", got)
+	}
+}
+
+func TestAliases(t *testing.T) {
+	log.SetDefault(zap.NewNop())
+	info := Info{Name: "test_alias", Aliases: map[string]string{"pageSize": "page_size"}}
+	var got map[string]any
+	handler := withAliases(func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		got = req.GetArguments()
+		return mcp.NewToolResultText("ok"), nil
+	}, info)
+
+	tests := []struct {
+		name string
+		args map[string]any
+		want map[string]any
+	}{
+		{"canonical", map[string]any{"page_size": 5.0}, map[string]any{"page_size": 5.0}},
+		{"legacy", map[string]any{"pageSize": 5.0}, map[string]any{"page_size": 5.0}},
+		{"both", map[string]any{"pageSize": 5.0, "page_size": 10.0}, map[string]any{"page_size": 10.0}},
+	}
+	for _, tt := range tests {
+		var req mcp.CallToolRequest
+		req.Params.Arguments = tt.args
+		if _, err := handler(context.Background(), req); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: handler got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
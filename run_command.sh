@@ -1 +1 @@
-docker run -d --name gitea-mcp-server -e GITEA_ACCESS_TOKEN=bda0a8923c1bd07f48ddb9fc998bd2cea1af7a9b -e GITEA_HOST=http://157.66.191.31:3000 -p 4000:8080 gitea-mcp-server -t sse
+docker run -d --name gitea-mcp-server -v "$PWD/gitea_token:/run/secrets/gitea_access_token:ro" -e GITEA_HOST=http://157.66.191.31:3000 -p 4000:8080 gitea-mcp-server -t sse
\ No newline at end of file